@@ -0,0 +1,189 @@
+// Package auth authenticates CRM API clients with API keys or JWT bearer tokens
+// and grants them one of three roles.
+package auth
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Role is the access level of a CRM client. Each role can do everything the lower ones can.
+type Role string
+
+const (
+	// Viewer reads conversations, memories, escalations and events
+	Viewer Role = "viewer"
+	// Agent also takes over conversations, sends messages and works the escalation queue
+	Agent Role = "agent"
+	// Admin also deletes data and reads the audit log
+	Admin Role = "admin"
+)
+
+var roleRanks = map[Role]int{Viewer: 1, Agent: 2, Admin: 3}
+
+// ParseRole returns the role with the given name.
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants the access of the required role.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Principal is an authenticated CRM client.
+type Principal struct {
+	// Name identifies the client in the audit log, such as the API key name or the JWT subject
+	Name string
+	Role Role
+	// Tenant restricts the client to one tenant's data; empty grants every tenant
+	Tenant string
+}
+
+// CanAccess reports whether the client may access the tenant's data. The default
+// tenant of a multi-tenant bot has an empty ID, so only unrestricted clients reach it.
+func (p Principal) CanAccess(tenant string) bool {
+	return p.Tenant == "" || p.Tenant == tenant
+}
+
+// APIKey is a static credential with a fixed role.
+type APIKey struct {
+	Name   string
+	Key    string
+	Role   Role
+	Tenant string // Restricts the key to one tenant when set
+}
+
+// ParseAPIKey parses an API key in the "name:role:key" format. A key restricted to a
+// tenant appends the tenant ID to the role, as in "name:role@tenant:key".
+func ParseAPIKey(value string) (APIKey, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return APIKey{}, errors.New(`API key must be in the "name:role:key" format`)
+	}
+	roleName, tenant, restricted := strings.Cut(parts[1], "@")
+	if restricted && tenant == "" {
+		return APIKey{}, errors.New(`API key tenant must follow the role, as in "name:role@tenant:key"`)
+	}
+	role, err := ParseRole(roleName)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return APIKey{Name: parts[0], Role: role, Key: parts[2], Tenant: tenant}, nil
+}
+
+// UnmarshalText parses an API key written as in ParseAPIKey, such as in the config file.
+func (k *APIKey) UnmarshalText(text []byte) error {
+	key, err := ParseAPIKey(string(text))
+	if err != nil {
+		return err
+	}
+	*k = key
+	return nil
+}
+
+var (
+	// ErrMissingCredentials is returned when the request has neither an API key nor a token.
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials is returned for unknown API keys and invalid or expired tokens.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator checks API keys and JWT bearer tokens.
+type Authenticator struct {
+	apiKeys   map[[sha256.Size]byte]APIKey
+	jwtSecret []byte
+}
+
+// NewAuthenticator accepts the given API keys and, when jwtSecret is not empty,
+// HS256 tokens signed with it.
+func NewAuthenticator(apiKeys []APIKey, jwtSecret string) *Authenticator {
+	a := &Authenticator{
+		apiKeys:   make(map[[sha256.Size]byte]APIKey, len(apiKeys)),
+		jwtSecret: []byte(jwtSecret),
+	}
+	for _, key := range apiKeys {
+		a.apiKeys[sha256.Sum256([]byte(key.Key))] = key
+	}
+	return a
+}
+
+// Enabled reports whether any credential can be accepted.
+func (a *Authenticator) Enabled() bool {
+	return len(a.apiKeys) > 0 || len(a.jwtSecret) > 0
+}
+
+// AuthenticateAPIKey returns the client owning the API key.
+func (a *Authenticator) AuthenticateAPIKey(key string) (Principal, error) {
+	if key == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+	// Keys are looked up by hash so the lookup does not leak their content through timing
+	apiKey, ok := a.apiKeys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return Principal{Name: apiKey.Name, Role: apiKey.Role, Tenant: apiKey.Tenant}, nil
+}
+
+// Claims are the JWT claims of a CRM client. The subject names the client, the role
+// claim sets its access level and the optional tenant claim restricts it to one tenant.
+type Claims struct {
+	Role   string `json:"role"`
+	Tenant string `json:"tenant,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// AuthenticateToken validates a JWT and returns the client it was issued to.
+// Tokens must be signed with HS256, expire and carry a subject and a role.
+func (a *Authenticator) AuthenticateToken(token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+	if len(a.jwtSecret) == 0 {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	role, err := ParseRole(claims.Role)
+	if err != nil || claims.Subject == "" {
+		return Principal{}, ErrInvalidCredentials
+	}
+	return Principal{Name: claims.Subject, Role: role, Tenant: claims.Tenant}, nil
+}
+
+// Authenticate accepts either credential. An API key takes precedence over a bearer token.
+func (a *Authenticator) Authenticate(apiKey, bearerToken string) (Principal, error) {
+	if apiKey != "" {
+		return a.AuthenticateAPIKey(apiKey)
+	}
+	return a.AuthenticateToken(bearerToken)
+}
+
+// NewToken issues a token for the client, for tests and internal tooling.
+func (a *Authenticator) NewToken(principal Principal, claims jwt.RegisteredClaims) (string, error) {
+	if len(a.jwtSecret) == 0 {
+		return "", errors.New("no JWT secret configured")
+	}
+	claims.Subject = principal.Name
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Role:             string(principal.Role),
+		Tenant:           principal.Tenant,
+		RegisteredClaims: claims,
+	}).SignedString(a.jwtSecret)
+}
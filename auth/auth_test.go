@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	key, err := ParseAPIKey("dashboard:viewer:s3cret:with:colons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator([]APIKey{key}, "")
+
+	principal, err := a.Authenticate("s3cret:with:colons", "")
+	if err != nil || principal.Name != "dashboard" || principal.Role != Viewer {
+		t.Fatalf("Authenticate = %+v, %v", principal, err)
+	}
+	if _, err := a.Authenticate("wrong", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong key error = %v", err)
+	}
+	if _, err := a.Authenticate("", ""); !errors.Is(err, ErrMissingCredentials) {
+		t.Fatalf("missing key error = %v", err)
+	}
+	if _, err := ParseAPIKey("dashboard:owner:s3cret"); err == nil {
+		t.Fatal("unknown role accepted")
+	}
+}
+
+func TestAPIKeyTenant(t *testing.T) {
+	key, err := ParseAPIKey("acme-support:agent@acme:s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator([]APIKey{key}, "")
+
+	principal, err := a.Authenticate("s3cret", "")
+	if err != nil || principal.Role != Agent || principal.Tenant != "acme" {
+		t.Fatalf("Authenticate = %+v, %v", principal, err)
+	}
+	if !principal.CanAccess("acme") || principal.CanAccess("globex") || principal.CanAccess("") {
+		t.Fatalf("tenant access of %+v", principal)
+	}
+	if !(Principal{Role: Admin}).CanAccess("globex") {
+		t.Fatal("unrestricted client denied")
+	}
+	if _, err := ParseAPIKey("acme-support:agent@:s3cret"); err == nil {
+		t.Fatal("empty tenant accepted")
+	}
+}
+
+func TestAuthenticateToken(t *testing.T) {
+	a := NewAuthenticator(nil, "jwt-secret")
+	expiresAt := jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+	token, err := a.NewToken(Principal{Name: "ana", Role: Agent}, jwt.RegisteredClaims{ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal, err := a.Authenticate("", token)
+	if err != nil || principal.Name != "ana" || principal.Role != Agent {
+		t.Fatalf("Authenticate = %+v, %v", principal, err)
+	}
+
+	expired, _ := a.NewToken(Principal{Name: "ana", Role: Agent}, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	})
+	if _, err := a.Authenticate("", expired); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expired token error = %v", err)
+	}
+
+	noExpiry, _ := a.NewToken(Principal{Name: "ana", Role: Agent}, jwt.RegisteredClaims{})
+	if _, err := a.Authenticate("", noExpiry); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("token without expiry error = %v", err)
+	}
+
+	other := NewAuthenticator(nil, "other-secret")
+	if _, err := other.Authenticate("", token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("foreign token error = %v", err)
+	}
+}
+
+func TestRoleAllows(t *testing.T) {
+	if !Admin.Allows(Agent) || !Agent.Allows(Viewer) || Viewer.Allows(Agent) || Role("").Allows(Viewer) {
+		t.Fatal("unexpected role ordering")
+	}
+}
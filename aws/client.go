@@ -2,14 +2,21 @@ package aws
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/NextMind-AI/chatbot-go/tracing"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Client struct {
@@ -20,13 +27,32 @@ type Client struct {
 	s3Client *s3.S3
 }
 
+// NewClient creates a client for the bucket with the default credential chain and exits
+// when the session cannot be created.
 func NewClient(region, bucket string) *Client {
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(region),
-	})
+	client, err := Connect(region, bucket, "", "")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create AWS session")
 	}
+	return client
+}
+
+// Connect creates a client for the bucket. Empty keys use the default credential chain,
+// such as the AWS_* environment variables or an instance role.
+func Connect(region, bucket, accessKeyID, secretAccessKey string) (*Client, error) {
+	// S3 requests are recorded as spans of the trace in the context they are sent with
+	httpClient := tracing.HTTPClient("s3", http.Client{})
+	awsConfig := &aws.Config{
+		Region:     aws.String(region),
+		HTTPClient: &httpClient,
+	}
+	if accessKeyID != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(accessKeyID, secretAccessKey, "")
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	log.Info().
 		Str("bucket", bucket).
@@ -39,11 +65,19 @@ func NewClient(region, bucket string) *Client {
 		region:   region,
 		uploader: s3manager.NewUploader(sess),
 		s3Client: s3.New(sess),
-	}
+	}, nil
 }
 
-func (c *Client) UploadAudio(audioData []byte, voiceID string) (string, error) {
-	key := fmt.Sprintf("audio/%s_%d.mp3", voiceID, time.Now().Unix())
+func (c *Client) UploadAudio(ctx context.Context, audioData []byte, voiceID string) (publicURL string, err error) {
+	// Nanoseconds keep keys unique across users, so erasing one user's audio never deletes another's
+	key := fmt.Sprintf("audio/%s_%d.mp3", voiceID, time.Now().UnixNano())
+
+	ctx, span := tracing.Start(ctx, "s3.UploadAudio",
+		attribute.String("s3.bucket", c.bucket),
+		attribute.String("s3.key", key),
+		attribute.Int("s3.size", len(audioData)),
+	)
+	defer func() { tracing.End(span, err) }()
 
 	log.Info().
 		Str("bucket", c.bucket).
@@ -59,7 +93,7 @@ func (c *Client) UploadAudio(audioData []byte, voiceID string) (string, error) {
 		ContentType: aws.String("audio/mpeg"),
 	}
 
-	result, err := c.uploader.Upload(uploadInput)
+	result, err := c.uploader.UploadWithContext(ctx, uploadInput)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -70,7 +104,7 @@ func (c *Client) UploadAudio(audioData []byte, voiceID string) (string, error) {
 		return "", fmt.Errorf("failed to upload audio to S3: %w", err)
 	}
 
-	_, aclErr := c.s3Client.PutObjectAcl(&s3.PutObjectAclInput{
+	_, aclErr := c.s3Client.PutObjectAclWithContext(ctx, &s3.PutObjectAclInput{
 		Bucket: aws.String(c.bucket),
 		Key:    aws.String(key),
 		ACL:    aws.String("public-read"),
@@ -83,7 +117,7 @@ func (c *Client) UploadAudio(audioData []byte, voiceID string) (string, error) {
 			Msg("Failed to set public-read ACL on uploaded object, file may not be publicly accessible")
 	}
 
-	publicURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.bucket, c.region, key)
+	publicURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", c.bucket, c.region, key)
 
 	log.Info().
 		Str("s3_url", publicURL).
@@ -95,3 +129,27 @@ func (c *Client) UploadAudio(audioData []byte, voiceID string) (string, error) {
 
 	return publicURL, nil
 }
+
+// DeleteAudio deletes an audio object uploaded by UploadAudio, given its public URL.
+func (c *Client) DeleteAudio(ctx context.Context, audioURL string) error {
+	prefix := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/", c.bucket, c.region)
+	key, ok := strings.CutPrefix(audioURL, prefix)
+	if !ok || key == "" {
+		return fmt.Errorf("audio URL %q is not in bucket %s", audioURL, c.bucket)
+	}
+
+	_, err := c.s3Client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(c.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete audio from S3: %w", err)
+	}
+
+	log.Info().
+		Str("bucket", c.bucket).
+		Str("key", key).
+		Msg("Audio deleted from S3")
+
+	return nil
+}
@@ -2,22 +2,35 @@ package chatbot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
 	"reflect"
 	"strings"
+	"time"
 
+	"github.com/NextMind-AI/chatbot-go/auth"
 	"github.com/NextMind-AI/chatbot-go/aws"
 	"github.com/NextMind-AI/chatbot-go/config"
 	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/events"
 	"github.com/NextMind-AI/chatbot-go/execution"
+	"github.com/NextMind-AI/chatbot-go/knowledge"
+	"github.com/NextMind-AI/chatbot-go/llm"
 	"github.com/NextMind-AI/chatbot-go/openai"
 	"github.com/NextMind-AI/chatbot-go/processor"
+	"github.com/NextMind-AI/chatbot-go/prompt"
 	"github.com/NextMind-AI/chatbot-go/redis"
 	"github.com/NextMind-AI/chatbot-go/server"
+	"github.com/NextMind-AI/chatbot-go/store"
+	"github.com/NextMind-AI/chatbot-go/store/sqlstore"
+	"github.com/NextMind-AI/chatbot-go/tenant"
+	"github.com/NextMind-AI/chatbot-go/tracing"
+	"github.com/NextMind-AI/chatbot-go/usage"
 	"github.com/NextMind-AI/chatbot-go/vonage"
 
-	openaiapi "github.com/openai/openai-go"
+	"github.com/rs/zerolog/log"
 )
 
 // Tool represents a custom tool that can be called by the AI (using the openai package type)
@@ -29,71 +42,750 @@ type ToolHandler = openai.ToolHandler
 // PromptGenerator is a function that generates the system prompt based on user context
 type PromptGenerator = openai.PromptGenerator
 
+// ContextPromptGenerator generates the system prompt from the user's context, including their memories
+type ContextPromptGenerator = openai.ContextPromptGenerator
+
+// PromptContext is the user context passed to a ContextPromptGenerator
+type PromptContext = openai.PromptContext
+
+// LLMProvider is a chat completion backend such as OpenAI or Anthropic (using the llm package type)
+type LLMProvider = llm.Provider
+
+// RetryPolicy controls how failed LLM requests are retried (using the llm package type)
+type RetryPolicy = llm.RetryPolicy
+
+// ModelFallback is a provider and model tried when the previous ones keep failing (using the openai package type)
+type ModelFallback = openai.ModelTarget
+
+// ModelRouter picks the model of each turn from rules (using the openai package type)
+type ModelRouter = openai.ModelRouter
+
+// RouteRule selects a model for the turns matching its conditions (using the openai package type)
+type RouteRule = openai.RouteRule
+
+// IntentClassifier classifies the user's messages for intent-based routing (using the openai package type)
+type IntentClassifier = openai.IntentClassifier
+
+// Experiment splits users between weighted prompt and model variants (using the openai package type)
+type Experiment = openai.Experiment
+
+// Variant is a prompt and model compared in an experiment (using the openai package type)
+type Variant = openai.Variant
+
+// HistoryPolicy controls how much of the conversation is sent to the model (using the openai package type)
+type HistoryPolicy = openai.HistoryPolicy
+
+// ConversationStore persists the chat history (using the store package type)
+type ConversationStore = store.ConversationStore
+
+// TenantDefinition describes a tenant served by the bot (using the tenant package type)
+type TenantDefinition = tenant.Definition
+
+// UserDataExport is everything the bot holds about a user (using the processor package type)
+type UserDataExport = processor.UserDataExport
+
+// ErasureReport describes what an erasure deleted (using the processor package type)
+type ErasureReport = processor.ErasureReport
+
+// MemoryConfig controls the long-term memory kept about each user.
+// Memories are saved by the model through the remember tool and extracted from each
+// conversation once it goes idle. They are stored without TTL and added to the system prompt.
+type MemoryConfig struct {
+	Enabled     bool
+	IdleTimeout time.Duration // Idle time that ends a conversation and triggers extraction (default 30 minutes)
+}
+
+// AttributesConfig gives the model the get_user_attributes and set_user_attribute tools.
+// Attributes are custom data about each user, also edited by agents in the CRM.
+type AttributesConfig struct {
+	Enabled  bool
+	ReadOnly []string // Attributes the model can read but not set, such as a plan set by agents
+}
+
+// TakeoverConfig controls how human agents take over conversations through the CRM API.
+// While a conversation is paused the bot stores the user's messages but does not answer.
+type TakeoverConfig struct {
+	IdleTimeout time.Duration // Time without agent activity before the bot resumes (default 30 minutes)
+}
+
+// EscalationConfig enables the escalate_to_human tool, which lets the model hand a conversation
+// over to a human agent: the bot is paused, the conversation is queued at /crm/escalations
+// and the user receives the acknowledgment message.
+type EscalationConfig struct {
+	Enabled        bool
+	Acknowledgment string // Message sent to the user on escalation (default DefaultEscalationAcknowledgment)
+}
+
+// DefaultEscalationAcknowledgment is sent to users escalated to a human agent when no message is configured
+const DefaultEscalationAcknowledgment = "Vou chamar um dos nossos atendentes para continuar o seu atendimento. Em instantes alguém fala com você por aqui."
+
+// UsagePrice is what a model or voice costs, in US dollars (using the usage package type)
+type UsagePrice = usage.Price
+
+// UsageConfig prices the tokens and speech characters each user consumes and limits what
+// a user may spend per day. Usage is always accounted; models without a price cost nothing.
+type UsageConfig struct {
+	Prices                map[string]UsagePrice // Per model name as configured, and per ElevenLabs model for speech
+	DailyBudgetUSD        float64               // Daily spend per user after which the model is no longer called; zero is unlimited
+	BudgetExceededMessage string                // Sent instead of a response once the budget is reached (default DefaultBudgetExceededMessage)
+	Timezone              string                // IANA time zone days and budgets are counted in (default America/Sao_Paulo)
+}
+
+// DefaultBudgetExceededMessage is sent to users over their daily budget when no message is configured
+const DefaultBudgetExceededMessage = "Você atingiu o limite de uso diário do atendimento automático. Volte a falar comigo amanhã, ou aguarde que um dos nossos atendentes pode te ajudar."
+
+// KnowledgeConfig connects a knowledge base to the bot. By default the model searches it
+// through the search_knowledge tool; with AutoInject the top chunks for the user's latest
+// messages are added to the prompt before every response instead.
+type KnowledgeConfig struct {
+	Base          *knowledge.Base
+	TopK          int     // Chunks returned per search (default 3)
+	AutoInject    bool    // Inject chunks before generation instead of registering the tool
+	MinSimilarity float64 // Minimum cosine similarity of injected chunks
+}
+
+// PromptTemplateConfig generates the system prompt from a Go template kept in a file or in
+// Redis instead of a PromptGenerator, so the prompt can be edited without recompiling. The
+// template receives .Name, .Phone, .Time, .Locale and .Attributes, and the user's memories
+// are appended to it. The source is checked for changes every ReloadInterval and a new
+// template replaces the previous one once it parses; an invalid one is logged and ignored.
+type PromptTemplateConfig struct {
+	File           string        // Template file
+	Redis          bool          // Read the template saved through PUT /crm/prompt instead of a file
+	Locale         string        // .Locale of users without a locale attribute (default pt-BR)
+	Timezone       string        // IANA time zone of .Time (default America/Sao_Paulo)
+	ReloadInterval time.Duration // How often the source is checked for changes (default 10 seconds)
+}
+
 // Config holds the configuration for the chatbot
 type Config struct {
-	PromptGenerator PromptGenerator
-	Tools           []Tool
-	Model           string // OpenAI model to use
+	PromptGenerator        PromptGenerator
+	ContextPromptGenerator ContextPromptGenerator // Replaces PromptGenerator when set; receives the user's memories
+	PromptTemplate         PromptTemplateConfig   // Replaces both generators when File or Redis is set
+	Tools                  []Tool
+	Model                  string                      // Model to use with the provider
+	Provider               LLMProvider                 // Overrides the provider selected by LLM_PROVIDER
+	Router                 ModelRouter                 // Picks the model of each turn; without rules every turn uses Model
+	Experiment             Experiment                  // Prompt and model variants compared per user, in every tenant; disabled without variants
+	Fallbacks              []ModelFallback             // Tried in order when the model fails; overrides LLM_FALLBACK_MODELS
+	RetryPolicy            RetryPolicy                 // Retries per model; zero fields use the defaults
+	History                HistoryPolicy               // Conversation window; overrides the HISTORY_* variables when Mode is set
+	ConversationStore      ConversationStore           // Chat history backend; overrides HISTORY_STORE
+	Memory                 MemoryConfig                // Long-term user memory, disabled by default
+	Attributes             AttributesConfig            // Attribute tools for the model, disabled by default
+	Takeover               TakeoverConfig              // Human agent takeover
+	Escalation             EscalationConfig            // Automatic escalation to a human agent, disabled by default
+	Knowledge              KnowledgeConfig             // Retrieval-augmented knowledge base, disabled when Base is nil
+	Usage                  UsageConfig                 // Token and speech prices and the daily budget per user
+	ToolMiddleware         []ToolMiddleware            // Applied to every tool, first one outermost
+	PerToolMiddleware      map[string][]ToolMiddleware // Applied to the named tool, inside the global ones
+	AsyncToolWorkers       int                         // Background workers for async tools (default 2)
+	Tenants                []TenantDefinition          // Served next to the tenants of TENANTS_FILE and the admin API
 }
 
 // Chatbot represents the main chatbot instance
 type Chatbot struct {
-	config           Config
-	messageProcessor *processor.MessageProcessor
-	server           *server.Server
+	config            Config
+	appConfig         *config.Config
+	httpClient        http.Client
+	provider          LLMProvider
+	redisClient       redis.Client
+	conversationStore ConversationStore
+	awsClient         *aws.Client
+	elevenLabsClient  elevenlabs.Client
+	messageProcessor  *processor.MessageProcessor
+	// promptTemplate is the bot's prompt template, nil without PromptTemplate
+	promptTemplate *prompt.Template
+	promptLocation *time.Location
+	tenants        *tenant.Registry
+	// tenantDefinitions are loaded into the registry on Start
+	tenantDefinitions []TenantDefinition
+	stopWatching      func()
+	server            *server.Server
+	shutdownTracing   func(context.Context) error
 }
 
-// New creates a new chatbot instance with the given configuration
-func New(cfg Config) *Chatbot {
-	appConfig := config.Load()
+// New creates a new chatbot instance with the given configuration and exits when it
+// cannot be created; use Build to handle the error instead
+func New(cfg Config, opts ...Option) *Chatbot {
+	c, err := Build(cfg, opts...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Chatbot setup failed")
+	}
+	return c
+}
+
+// Build creates a new chatbot instance with the given configuration. The settings are read
+// from the config file and the environment unless given with WithSettings; audio is disabled
+// when the ElevenLabs or S3 settings are missing.
+func Build(cfg Config, opts ...Option) (*Chatbot, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	appConfig, err := loadSettings(o)
+	if err != nil {
+		return nil, err
+	}
+
 	httpClient := http.Client{}
+	if o.httpClient != nil {
+		httpClient = *o.httpClient
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    appConfig.OTLPEndpoint,
+		ServiceName: appConfig.OTelServiceName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opentelemetry exporter setup: %w", err)
+	}
+	if appConfig.OTLPEndpoint != "" {
+		log.Info().Str("endpoint", appConfig.OTLPEndpoint).Msg("Exporting traces over OTLP")
+	}
 
-	awsClient := aws.NewClient(appConfig.S3Region, appConfig.S3Bucket)
+	awsClient := o.awsClient
+	if awsClient == nil && appConfig.S3Bucket != "" {
+		awsClient, err = aws.Connect(appConfig.S3Region, appConfig.S3Bucket, appConfig.AWSAccessKeyID, appConfig.AWSSecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("aws session setup: %w", err)
+		}
+	}
+
+	var redisClient redis.Client
+	if o.redisClient != nil {
+		redisClient = *o.redisClient
+	} else {
+		redisClient, err = redis.Connect(appConfig.RedisAddr, appConfig.RedisPassword, appConfig.RedisDB)
+		if err != nil {
+			return nil, fmt.Errorf("redis connection to %s: %w", appConfig.RedisAddr, err)
+		}
+	}
+
+	conversationStore := cfg.ConversationStore
+	if o.conversationStore != nil {
+		conversationStore = o.conversationStore
+	}
+	if conversationStore == nil {
+		conversationStore, err = newConversationStore(appConfig, redisClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	provider := cfg.Provider
+	if o.provider != nil {
+		provider = o.provider
+	}
+	if provider == nil {
+		provider, err = newLLMProvider(appConfig, httpClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var elevenLabsClient elevenlabs.Client
+	if o.elevenLabsClient != nil {
+		elevenLabsClient = *o.elevenLabsClient
+	} else {
+		// A nil *aws.Client must not become a non-nil AWSClient
+		var audioStorage elevenlabs.AWSClient
+		if awsClient != nil {
+			audioStorage = awsClient
+		}
+		elevenLabsClient = elevenlabs.NewClient(appConfig.ElevenLabsAPIKey, httpClient, audioStorage)
+	}
+	if !elevenLabsClient.CanTranscribe() {
+		log.Warn().Msg("ELEVENLABS_API_KEY is not set, audio messages are not supported")
+	} else if !elevenLabsClient.CanSpeak() {
+		log.Warn().Msg("AWS_S3_BUCKET is not set, audio replies are sent as text")
+	}
+
+	c := &Chatbot{
+		config:            cfg,
+		appConfig:         appConfig,
+		httpClient:        httpClient,
+		provider:          provider,
+		redisClient:       redisClient,
+		conversationStore: conversationStore,
+		awsClient:         awsClient,
+		elevenLabsClient:  elevenLabsClient,
+		shutdownTracing:   shutdownTracing,
+	}
+
+	if err := c.loadPromptTemplate(); err != nil {
+		return nil, err
+	}
+
+	// The default tenant answers the numbers no tenant owns, with the bot's own configuration
+	c.messageProcessor, err = c.newMessageProcessor(tenantSettings{
+		id:                     appConfig.PhoneNumber,
+		vonageJWT:              appConfig.VonageJWT,
+		senderNumber:           appConfig.PhoneNumber,
+		redisClient:            redisClient,
+		conversationStore:      conversationStore,
+		promptGenerator:        cfg.PromptGenerator,
+		contextPromptGenerator: cfg.ContextPromptGenerator,
+		promptTemplate:         c.promptTemplate,
+		tools:                  cfg.Tools,
+		model:                  cfg.Model,
+		voiceID:                appConfig.ElevenLabsVoiceID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.tenantDefinitions = cfg.Tenants
+	if appConfig.TenantsFile != "" {
+		definitions, err := tenant.LoadFile(appConfig.TenantsFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenants file: %w", err)
+		}
+		c.tenantDefinitions = append(c.tenantDefinitions, definitions...)
+	}
+	c.tenants = tenant.NewRegistry(&c.redisClient, c.buildTenant)
+
+	c.server = server.New(c.messageProcessor, server.Options{
+		Authenticator: auth.NewAuthenticator(appConfig.CRMAPIKeys, appConfig.CRMJWTSecret),
+		CORSOrigins:   appConfig.CRMCORSOrigins,
+		Tenants:       c.tenants,
+	})
+
+	return c, nil
+}
+
+// loadSettings returns the settings given with WithSettings, or reads them from the config file and the environment
+func loadSettings(o options) (*Settings, error) {
+	appConfig := o.settings
+	if appConfig == nil {
+		path := o.configFile
+		if path == "" {
+			path = os.Getenv("CONFIG_FILE")
+		}
+		loaded, err := config.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		appConfig = loaded
+	}
+
+	for _, override := range o.overrides {
+		override(appConfig)
+	}
+	if err := appConfig.Validate(); err != nil {
+		return nil, err
+	}
+	return appConfig, nil
+}
+
+// tenantSettings is what differs between the tenants served by the bot
+type tenantSettings struct {
+	id                     string // Usage is accounted to it
+	vonageJWT              string
+	senderNumber           string
+	redisClient            redis.Client
+	conversationStore      ConversationStore
+	promptGenerator        PromptGenerator
+	contextPromptGenerator ContextPromptGenerator
+	promptTemplate         *prompt.Template // Replaces both generators when set
+	tools                  []Tool
+	model                  string
+	voiceID                string
+}
+
+// newMessageProcessor creates the clients and the message processor serving a tenant
+func (c *Chatbot) newMessageProcessor(settings tenantSettings) (*processor.MessageProcessor, error) {
+	cfg := c.config
+	appConfig := c.appConfig
+	redisClient := settings.redisClient
 
 	vonageClient := vonage.NewClient(
-		appConfig.VonageJWT,
+		settings.vonageJWT,
 		appConfig.GeospecificMessagesAPIURL,
 		appConfig.MessagesAPIURL,
-		appConfig.PhoneNumber,
-		httpClient,
+		settings.senderNumber,
+		c.httpClient,
 	)
 
+	// Every stored message is published to the CRM event stream
+	eventBus := events.NewBus(redisClient)
+	conversationStore := events.NewStore(settings.conversationStore, eventBus)
+
+	tools := settings.tools
+	if cfg.Memory.Enabled {
+		tools = append(openai.MemoryTools(&redisClient), tools...)
+	}
+	if cfg.Attributes.Enabled {
+		tools = append(openai.AttributeTools(&redisClient, cfg.Attributes.ReadOnly), tools...)
+	}
+	knowledgeTopK := cfg.Knowledge.TopK
+	if knowledgeTopK <= 0 {
+		knowledgeTopK = 3
+	}
+	if cfg.Knowledge.Base != nil && !cfg.Knowledge.AutoInject {
+		tools = append(tools, knowledge.SearchTool(cfg.Knowledge.Base, knowledgeTopK))
+	}
+	escalator := &processorEscalator{}
+	if cfg.Escalation.Enabled {
+		tools = append(tools, openai.EscalationTool(escalator))
+	}
+
+	// Panic recovery always wraps the user middlewares so a failing tool never kills the processing goroutine,
+	// and usage, metrics and spans are recorded outside it so recovered panics count as failed calls
+	globalMiddleware := append([]ToolMiddleware{
+		openai.UsageToolMiddleware(&redisClient),
+		openai.MetricsToolMiddleware(),
+		openai.TracingToolMiddleware(),
+		openai.RecoveryToolMiddleware(),
+	}, cfg.ToolMiddleware...)
+	tools = openai.ApplyToolMiddleware(tools, globalMiddleware, cfg.PerToolMiddleware)
+
 	openAIClient := openai.NewClient(
-		appConfig.OpenAIKey,
-		httpClient,
-		cfg.PromptGenerator,
-		cfg.Tools,
-		cfg.Model,
+		c.provider,
+		settings.promptGenerator,
+		tools,
+		settings.model,
 	)
 
-	redisClient := redis.NewClient(
-		appConfig.RedisAddr,
-		appConfig.RedisPassword,
-		appConfig.RedisDB,
-	)
+	usageMeter, err := newUsageMeter(cfg.Usage, settings.id, &redisClient)
+	if err != nil {
+		return nil, err
+	}
+	openAIClient.SetUsageRecorder(usageMeter)
 
-	elevenLabsClient := elevenlabs.NewClient(
-		appConfig.ElevenLabsAPIKey,
-		httpClient,
-		awsClient,
-	)
+	retryPolicy := cfg.RetryPolicy
+	if retryPolicy.MaxAttempts == 0 {
+		retryPolicy.MaxAttempts = appConfig.LLMMaxAttempts
+	}
+	fallbacks := cfg.Fallbacks
+	if fallbacks == nil {
+		for _, model := range appConfig.LLMFallbackModels {
+			fallbacks = append(fallbacks, ModelFallback{Model: model})
+		}
+	}
+	openAIClient.SetRetryPolicy(retryPolicy, fallbacks)
+
+	history := cfg.History
+	if history.Mode == "" {
+		history.Mode = openai.HistoryMode(appConfig.HistoryMode)
+		history.MaxTokens = appConfig.HistoryMaxTokens
+		history.MaxTurns = appConfig.HistoryMaxTurns
+	}
+	openAIClient.SetHistoryPolicy(history)
+	openAIClient.SetConversationStore(conversationStore)
+	// Summaries live in the conversation store, so deleting a conversation deletes its summary
+	if summaries, ok := settings.conversationStore.(store.HistorySummaryStore); ok {
+		openAIClient.SetHistorySummaryStore(summaries)
+	} else if history.Mode == openai.HistoryRollingSummary {
+		log.Warn().Msg("The conversation store does not keep history summaries, sending the full history")
+	}
+
+	if settings.contextPromptGenerator != nil {
+		openAIClient.SetContextPromptGenerator(settings.contextPromptGenerator)
+	}
+	if settings.promptTemplate != nil {
+		openAIClient.SetContextPromptGenerator(c.templatePromptGenerator(settings.promptTemplate, &redisClient))
+	}
+	openAIClient.SetToolStates(&redisClient)
+	if len(cfg.Router.Rules) > 0 {
+		openAIClient.SetModelRouter(cfg.Router, &redisClient)
+	}
+	if len(cfg.Experiment.Variants) > 0 {
+		if err := openAIClient.SetExperiment(cfg.Experiment); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Memory.Enabled {
+		openAIClient.EnableMemory(&redisClient)
+	}
+	if cfg.Knowledge.Base != nil && cfg.Knowledge.AutoInject {
+		openAIClient.SetKnowledgeRetriever(knowledge.Retriever(cfg.Knowledge.Base, knowledgeTopK, cfg.Knowledge.MinSimilarity))
+	}
+
+	elevenLabsClient := c.elevenLabsClient
+	elevenLabsClient.VoiceID = settings.voiceID
 
 	executionManager := execution.NewManager()
 
 	messageProcessor := processor.NewMessageProcessor(
 		vonageClient,
 		redisClient,
+		conversationStore,
 		openAIClient,
 		elevenLabsClient,
 		executionManager,
 	)
 
-	srv := server.New(messageProcessor)
+	messageProcessor.SetEventBus(eventBus)
+	if c.awsClient != nil {
+		messageProcessor.SetAudioDeleter(c.awsClient)
+	}
+	messageProcessor.SetTakeoverIdleTimeout(cfg.Takeover.IdleTimeout)
+	budgetExceededMessage := cfg.Usage.BudgetExceededMessage
+	if budgetExceededMessage == "" {
+		budgetExceededMessage = DefaultBudgetExceededMessage
+	}
+	messageProcessor.SetUsageMeter(usageMeter, budgetExceededMessage)
+	escalator.messageProcessor = messageProcessor
+	if cfg.Escalation.Enabled {
+		acknowledgment := cfg.Escalation.Acknowledgment
+		if acknowledgment == "" {
+			acknowledgment = DefaultEscalationAcknowledgment
+		}
+		messageProcessor.SetEscalationAcknowledgment(acknowledgment)
+	}
 
-	return &Chatbot{
-		config:           cfg,
-		messageProcessor: messageProcessor,
-		server:           srv,
+	return messageProcessor, nil
+}
+
+// buildTenant creates the message processor of a tenant defined in the tenants file or
+// through the admin API, and starts its background workers
+func (c *Chatbot) buildTenant(definition TenantDefinition) (*processor.MessageProcessor, error) {
+	settings := tenantSettings{
+		id:                     definition.ID,
+		vonageJWT:              definition.VonageJWT,
+		senderNumber:           definition.SenderNumber,
+		redisClient:            c.redisClient.WithPrefix(definition.RedisPrefix),
+		promptGenerator:        c.config.PromptGenerator,
+		contextPromptGenerator: c.config.ContextPromptGenerator,
+		tools:                  c.config.Tools,
+		model:                  definition.Model,
+		voiceID:                definition.VoiceID,
+	}
+	if settings.vonageJWT == "" {
+		settings.vonageJWT = c.appConfig.VonageJWT
+	}
+	switch {
+	case definition.Prompt != "":
+		settings.promptGenerator = SimplePromptGenerator(definition.Prompt)
+		settings.contextPromptGenerator = nil
+	case c.config.PromptTemplate.Redis:
+		// A tenant without its own template in Redis uses the bot's
+		promptTemplate, err := prompt.New(prompt.Redis(&settings.redisClient, &c.redisClient), c.config.PromptTemplate.ReloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("prompt template: %w", err)
+		}
+		settings.promptTemplate = promptTemplate
+	default:
+		settings.promptTemplate = c.promptTemplate
+	}
+	if definition.Tools != nil {
+		tools, err := selectTools(c.config.Tools, definition.Tools)
+		if err != nil {
+			return nil, err
+		}
+		settings.tools = tools
+	}
+	if settings.model == "" {
+		settings.model = c.config.Model
+	}
+	if settings.voiceID == "" {
+		settings.voiceID = c.appConfig.ElevenLabsVoiceID
+	}
+
+	conversationStore, err := c.tenantConversationStore(definition, settings.redisClient)
+	if err != nil {
+		return nil, err
+	}
+	settings.conversationStore = conversationStore
+
+	messageProcessor, err := c.newMessageProcessor(settings)
+	if err != nil {
+		return nil, err
+	}
+	c.startWorkers(messageProcessor, settings.tools)
+	return messageProcessor, nil
+}
+
+// loadPromptTemplate loads the bot's prompt template, when one is configured
+func (c *Chatbot) loadPromptTemplate() error {
+	cfg := &c.config.PromptTemplate
+	if cfg.File == "" && !cfg.Redis {
+		return nil
+	}
+	if cfg.File != "" && cfg.Redis {
+		return errors.New("the prompt template must be read from either a file or Redis")
+	}
+	if cfg.Locale == "" {
+		cfg.Locale = "pt-BR"
+	}
+	if cfg.Timezone == "" {
+		cfg.Timezone = "America/Sao_Paulo"
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = 10 * time.Second
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid prompt time zone: %w", err)
+	}
+	c.promptLocation = location
+
+	loader := prompt.File(cfg.File)
+	if cfg.Redis {
+		loader = prompt.Redis(&c.redisClient)
+	}
+	promptTemplate, err := prompt.New(loader, cfg.ReloadInterval)
+	if err != nil {
+		return fmt.Errorf("prompt template: %w", err)
+	}
+	c.promptTemplate = promptTemplate
+	return nil
+}
+
+// templatePromptGenerator renders the system prompt from the template with the user's
+// attributes, whose locale attribute overrides the bot's locale
+func (c *Chatbot) templatePromptGenerator(promptTemplate *prompt.Template, attributes *redis.Client) ContextPromptGenerator {
+	return func(p PromptContext) string {
+		userAttributes, err := attributes.GetConversationAttributes(p.UserPhone)
+		if err != nil {
+			log.Error().Err(err).Str("user_id", p.UserPhone).Msg("Error loading attributes for the prompt")
+		}
+		locale := userAttributes["locale"]
+		if locale == "" {
+			locale = c.config.PromptTemplate.Locale
+		}
+
+		systemPrompt, err := promptTemplate.Render(prompt.Data{
+			Name:       p.UserName,
+			Phone:      p.UserPhone,
+			Time:       time.Now().In(c.promptLocation),
+			Locale:     locale,
+			Attributes: userAttributes,
+		})
+		if err != nil {
+			log.Error().Err(err).Str("user_id", p.UserPhone).Msg("Error rendering prompt template")
+		}
+		return systemPrompt + p.MemoriesSection()
+	}
+}
+
+// selectTools returns the bot's tools with the given names
+func selectTools(tools []Tool, names []string) ([]Tool, error) {
+	byName := make(map[string]Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Definition.Name] = tool
+	}
+	selected := make([]Tool, 0, len(names))
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown tool %q", tenant.ErrInvalidDefinition, name)
+		}
+		selected = append(selected, tool)
+	}
+	return selected, nil
+}
+
+// tenantConversationStore returns the chat history of a tenant, kept apart from the other tenants'
+func (c *Chatbot) tenantConversationStore(definition TenantDefinition, redisClient redis.Client) (ConversationStore, error) {
+	switch conversationStore := c.conversationStore.(type) {
+	case *sqlstore.Store:
+		return conversationStore.ForTenant(definition.ID), nil
+	case *redis.HistoryStore:
+		historyStore := redis.NewHistoryStore(redisClient, c.appConfig.HistoryRetention)
+		if err := historyStore.EnsureSearchIndex(context.Background()); err != nil {
+			log.Error().Err(err).Str("tenant", definition.ID).Msg("Error creating conversation search index")
+		}
+		return historyStore, nil
+	default:
+		return nil, errors.New("the configured ConversationStore cannot be shared by tenants")
+	}
+}
+
+// startWorkers starts the background workers a tenant's configuration needs
+func (c *Chatbot) startWorkers(messageProcessor *processor.MessageProcessor, tools []Tool) {
+	if hasAsyncTools(tools) {
+		workers := c.config.AsyncToolWorkers
+		if workers <= 0 {
+			workers = 2
+		}
+		messageProcessor.StartToolJobWorkers(workers)
+	}
+	if c.config.Memory.Enabled {
+		idleTimeout := c.config.Memory.IdleTimeout
+		if idleTimeout <= 0 {
+			idleTimeout = 30 * time.Minute
+		}
+		messageProcessor.StartMemoryExtraction(idleTimeout)
+	}
+}
+
+// processorEscalator forwards escalate_to_human calls to the message processor,
+// which is created after the tools it runs
+type processorEscalator struct {
+	messageProcessor *processor.MessageProcessor
+}
+
+func (e *processorEscalator) EscalateToHuman(userID, reason, priority string) error {
+	return e.messageProcessor.EscalateToHuman(userID, reason, priority)
+}
+
+// newUsageMeter creates the meter that accounts usage to the tenant
+func newUsageMeter(cfg UsageConfig, tenant string, redisClient *redis.Client) (*usage.Meter, error) {
+	timezone := cfg.Timezone
+	if timezone == "" {
+		timezone = "America/Sao_Paulo"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid usage time zone: %w", err)
+	}
+	if cfg.DailyBudgetUSD > 0 {
+		log.Info().Float64("daily_budget_usd", cfg.DailyBudgetUSD).Str("tenant", tenant).Msg("Daily budget per user enabled")
+	}
+	return usage.NewMeter(redisClient, tenant, cfg.Prices, cfg.DailyBudgetUSD, location), nil
+}
+
+// newLLMProvider creates the provider selected by the LLM_PROVIDER setting
+func newLLMProvider(appConfig *config.Config, httpClient http.Client) (LLMProvider, error) {
+	switch appConfig.LLMProvider {
+	case "anthropic":
+		if appConfig.AnthropicAPIKey == "" {
+			return nil, errors.New("ANTHROPIC_API_KEY is required with LLM_PROVIDER anthropic")
+		}
+		return llm.NewAnthropicProvider(appConfig.AnthropicAPIKey, appConfig.LLMBaseURL, httpClient), nil
+	case "openai-compatible":
+		if appConfig.LLMBaseURL == "" {
+			return nil, errors.New("LLM_BASE_URL is required with LLM_PROVIDER openai-compatible")
+		}
+		return llm.NewOpenAICompatibleProvider(appConfig.LLMBaseURL, appConfig.OpenAIKey, httpClient), nil
+	default:
+		if appConfig.OpenAIKey == "" {
+			return nil, errors.New("OPENAI_API_KEY is required with LLM_PROVIDER openai")
+		}
+		return llm.NewOpenAIProvider(appConfig.OpenAIKey, httpClient), nil
+	}
+}
+
+// newConversationStore creates the chat history store selected by the HISTORY_STORE setting
+func newConversationStore(appConfig *config.Config, redisClient redis.Client) (ConversationStore, error) {
+	switch appConfig.HistoryStore {
+	case "postgres":
+		conversationStore, err := sqlstore.OpenPostgres(context.Background(), appConfig.DatabaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("postgresql connection: %w", err)
+		}
+		log.Info().Msg("Storing chat history in PostgreSQL")
+		return conversationStore, nil
+	case "sqlite":
+		conversationStore, err := sqlstore.OpenSQLite(context.Background(), appConfig.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite open %s: %w", appConfig.SQLitePath, err)
+		}
+		log.Info().Str("path", appConfig.SQLitePath).Msg("Storing chat history in SQLite")
+		return conversationStore, nil
+	default:
+		historyStore := redis.NewHistoryStore(redisClient, appConfig.HistoryRetention)
+		if err := historyStore.RebuildIndex(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Error rebuilding conversation index")
+		}
+		if err := historyStore.EnsureSearchIndex(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Error creating conversation search index")
+		}
+		return historyStore, nil
 	}
 }
 
@@ -102,9 +794,105 @@ func (c *Chatbot) Start(port string) {
 	if port == "" {
 		port = "8080"
 	}
+	c.startWorkers(c.messageProcessor, c.config.Tools)
+
+	if err := c.tenants.Load(c.tenantDefinitions); err != nil {
+		log.Fatal().Err(err).Msg("Loading tenants failed")
+	}
+	stopWatching, err := c.tenants.Watch()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Subscribing to tenant updates failed")
+	}
+	c.stopWatching = stopWatching
+
 	c.server.Start(port)
 }
 
+// Shutdown stops the server and flushes the traces not exported yet.
+func (c *Chatbot) Shutdown(ctx context.Context) error {
+	if c.stopWatching != nil {
+		c.stopWatching()
+	}
+	return errors.Join(c.server.Shutdown(ctx), c.shutdownTracing(ctx))
+}
+
+// Tenants returns the tenants served next to the default one, for managing them from Go
+// code instead of the admin API. Tenants are only served once Start is called.
+func (c *Chatbot) Tenants() *tenant.Registry {
+	return c.tenants
+}
+
+// ExportUserData returns everything the bot holds about a user of the tenant, for LGPD/GDPR
+// data access requests. An empty tenantID is the default tenant. The export is recorded in
+// the tenant's audit log under requestedBy. Use WriteCSV on the result for a CSV version.
+func (c *Chatbot) ExportUserData(tenantID, userID, requestedBy string) (UserDataExport, error) {
+	messageProcessor, err := c.tenantProcessor(tenantID)
+	if err != nil {
+		return UserDataExport{}, err
+	}
+	export, err := messageProcessor.ExportUserData(userID)
+	if err != nil {
+		return UserDataExport{}, err
+	}
+	recordAudit(messageProcessor, requestedBy, "export_data", userID, "")
+	return export, nil
+}
+
+// EraseUserData deletes everything the bot holds about a user of the tenant, including the
+// generated audio in S3, for LGPD/GDPR erasure requests. An empty tenantID is the default
+// tenant. The erasure is recorded in the tenant's audit log under requestedBy.
+func (c *Chatbot) EraseUserData(tenantID, userID, requestedBy string) (ErasureReport, error) {
+	messageProcessor, err := c.tenantProcessor(tenantID)
+	if err != nil {
+		return ErasureReport{}, err
+	}
+	report, err := messageProcessor.EraseUserData(userID)
+	if err != nil {
+		recordAudit(messageProcessor, requestedBy, "erase_data_failed", userID, err.Error())
+		return ErasureReport{}, err
+	}
+	recordAudit(messageProcessor, requestedBy, "erase_data", userID, fmt.Sprintf("messages=%d audio_deleted=%d audio_failed=%d",
+		report.MessagesDeleted, report.AudioDeleted, len(report.AudioFailed)))
+	return report, nil
+}
+
+// tenantProcessor returns the message processor of the tenant, or of the default tenant
+// when tenantID is empty. Tenants are only found once Start has loaded them.
+func (c *Chatbot) tenantProcessor(tenantID string) (*processor.MessageProcessor, error) {
+	if tenantID == "" {
+		return c.messageProcessor, nil
+	}
+	t, ok := c.tenants.Get(tenantID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", tenant.ErrNotFound, tenantID)
+	}
+	return t.Processor, nil
+}
+
+func recordAudit(messageProcessor *processor.MessageProcessor, actor, action, userID, detail string) {
+	entry := redis.AuditEntry{
+		Actor:     actor,
+		Role:      "library",
+		Action:    action,
+		UserID:    userID,
+		Detail:    detail,
+		Timestamp: time.Now(),
+	}
+	if err := messageProcessor.GetRedisClient().RecordAudit(entry); err != nil {
+		log.Error().Err(err).Str("action", action).Str("user_id", userID).Msg("Error recording audit entry")
+	}
+}
+
+// hasAsyncTools reports whether any of the tools runs asynchronously
+func hasAsyncTools(tools []Tool) bool {
+	for _, tool := range tools {
+		if tool.Async {
+			return true
+		}
+	}
+	return false
+}
+
 // ToolFunc represents a tool function with parameter metadata
 type ToolFunc struct {
 	Fn             any
@@ -155,17 +943,27 @@ func CreateTool(name, description string, fn any) (Tool, error) {
 	handler := createHandler(fnValue, fnType, toolFunc.ParameterNames)
 
 	return Tool{
-		Definition: openaiapi.ChatCompletionToolParam{
-			Function: openaiapi.FunctionDefinitionParam{
-				Name:        name,
-				Description: openaiapi.String(description),
-				Parameters:  parameters,
-			},
+		Definition: llm.ToolDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  parameters,
 		},
 		Handler: handler,
 	}, nil
 }
 
+// CreateAsyncTool creates a tool that runs in a background worker.
+// The model immediately receives a job ID and tells the user the task is in progress;
+// once the function returns, a new turn is started with the result.
+func CreateAsyncTool(name, description string, fn any) (Tool, error) {
+	tool, err := CreateTool(name, description, fn)
+	if err != nil {
+		return Tool{}, err
+	}
+	tool.Async = true
+	return tool, nil
+}
+
 // WithParams wraps a function with parameter metadata
 func WithParams(fn any, names []string, descriptions []string) ToolFunc {
 	return ToolFunc{
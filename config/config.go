@@ -1,88 +1,314 @@
+// Package config holds the bot's settings, read from an optional YAML file and the
+// environment. Settings are validated once loaded and problems are returned as errors,
+// so a misconfigured bot can be reported by the caller instead of exiting.
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/auth"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
 )
 
+// Config holds the settings of the bot. In the config file each setting's key is the
+// lowercase name of its environment variable, such as vonage_jwt for VONAGE_JWT.
 type Config struct {
-	VonageJWT                 string
-	OpenAIKey                 string
-	ElevenLabsAPIKey          string
-	ElevenLabsVoiceID         string
-	ElevenLabsModelID         string
-	Port                      string
-	GeospecificMessagesAPIURL string
-	MessagesAPIURL            string
-	RedisAddr                 string
-	RedisPassword             string
-	RedisDB                   int
-	PhoneNumber               string
-	S3Bucket                  string
-	S3Region                  string
-	AWSAccessKeyID            string
-	AWSSecretAccessKey        string
+	VonageJWT                 string        `yaml:"vonage_jwt"`
+	LLMProvider               string        `yaml:"llm_provider"`
+	LLMBaseURL                string        `yaml:"llm_base_url"`
+	OpenAIKey                 string        `yaml:"openai_api_key"`
+	AnthropicAPIKey           string        `yaml:"anthropic_api_key"`
+	LLMFallbackModels         []string      `yaml:"llm_fallback_models"`
+	LLMMaxAttempts            int           `yaml:"llm_max_attempts"`
+	HistoryMode               string        `yaml:"history_mode"`
+	HistoryMaxTokens          int           `yaml:"history_max_tokens"`
+	HistoryMaxTurns           int           `yaml:"history_max_turns"`
+	HistoryStore              string        `yaml:"history_store"`
+	HistoryRetention          time.Duration `yaml:"history_retention"`
+	DatabaseURL               string        `yaml:"database_url"`
+	SQLitePath                string        `yaml:"sqlite_path"`
+	ElevenLabsAPIKey          string        `yaml:"elevenlabs_api_key"` // Empty disables audio messages
+	ElevenLabsVoiceID         string        `yaml:"elevenlabs_voice_id"`
+	ElevenLabsModelID         string        `yaml:"elevenlabs_model_id"`
+	Port                      string        `yaml:"port"`
+	GeospecificMessagesAPIURL string        `yaml:"geospecific_messages_api_url"`
+	MessagesAPIURL            string        `yaml:"messages_api_url"`
+	RedisAddr                 string        `yaml:"redis_addr"`
+	RedisPassword             string        `yaml:"redis_password"`
+	RedisDB                   int           `yaml:"redis_db"`
+	PhoneNumber               string        `yaml:"phone_number"`
+	S3Bucket                  string        `yaml:"aws_s3_bucket"` // Empty disables audio replies, which are served from S3
+	S3Region                  string        `yaml:"aws_region"`
+	// AWSAccessKeyID and AWSSecretAccessKey are optional; without them the AWS SDK's
+	// default credential chain is used, such as an instance role
+	AWSAccessKeyID     string `yaml:"aws_access_key_id"`
+	AWSSecretAccessKey string `yaml:"aws_secret_access_key"`
+	// CRMAPIKeys are the API keys accepted by the CRM API, each with a name and a role
+	CRMAPIKeys []auth.APIKey `yaml:"crm_api_keys"`
+	// CRMJWTSecret verifies HS256 bearer tokens for the CRM API; empty disables tokens
+	CRMJWTSecret string `yaml:"crm_jwt_secret"`
+	// CRMCORSOrigins are the browser origins allowed to call the CRM API; empty allows none
+	CRMCORSOrigins []string `yaml:"crm_cors_origins"`
+	// OTLPEndpoint is the OpenTelemetry collector that receives traces; empty disables tracing
+	OTLPEndpoint    string `yaml:"otel_exporter_otlp_endpoint"`
+	OTelServiceName string `yaml:"otel_service_name"`
+	// TenantsFile is a JSON file with the tenants served next to the default one; empty serves none from a file
+	TenantsFile string `yaml:"tenants_file"`
+}
+
+// Default returns the settings used for whatever the config file and the environment leave out.
+func Default() *Config {
+	return &Config{
+		LLMProvider:               "openai",
+		LLMMaxAttempts:            3,
+		HistoryMode:               "full",
+		HistoryMaxTokens:          4000,
+		HistoryMaxTurns:           10,
+		HistoryStore:              "redis",
+		HistoryRetention:          24 * time.Hour,
+		SQLitePath:                "chatbot.db",
+		ElevenLabsVoiceID:         "JNI7HKGyqNaHqfihNoCi",
+		ElevenLabsModelID:         "eleven_multilingual_v2",
+		Port:                      "8080",
+		GeospecificMessagesAPIURL: "https://api-us.nexmo.com/v1/messages",
+		MessagesAPIURL:            "https://api.nexmo.com/v1/messages",
+		RedisAddr:                 "localhost:6379",
+		S3Region:                  "us-east-2",
+		OTelServiceName:           "chatbot",
+	}
 }
 
+// Load reads the settings from the file named by CONFIG_FILE, when set, and the
+// environment, and exits when they are invalid.
 func Load() *Config {
 	godotenv.Load()
 
-	cfg := &Config{
-		VonageJWT:                 mustGetEnv("VONAGE_JWT"),
-		OpenAIKey:                 mustGetEnv("OPENAI_API_KEY"),
-		ElevenLabsAPIKey:          mustGetEnv("ELEVENLABS_API_KEY"),
-		ElevenLabsVoiceID:         mustGetEnvWithDefault("ELEVENLABS_VOICE_ID", "JNI7HKGyqNaHqfihNoCi"),
-		ElevenLabsModelID:         mustGetEnvWithDefault("ELEVENLABS_MODEL_ID", "eleven_multilingual_v2"),
-		Port:                      getEnv("PORT", "8080"),
-		GeospecificMessagesAPIURL: getEnv("GEOSPECIFIC_MESSAGES_API_URL", "https://api-us.nexmo.com/v1/messages"),
-		MessagesAPIURL:            getEnv("MESSAGES_API_URL", "https://api.nexmo.com/v1/messages"),
-		RedisAddr:                 getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:             getEnv("REDIS_PASSWORD", ""),
-		RedisDB:                   getEnvInt("REDIS_DB", 0),
-		PhoneNumber:               mustGetEnv("PHONE_NUMBER"),
-		S3Bucket:                  mustGetEnv("AWS_S3_BUCKET"),
-		S3Region:                  getEnv("AWS_REGION", "us-east-2"),
-		AWSAccessKeyID:            mustGetEnv("AWS_ACCESS_KEY_ID"),
-		AWSSecretAccessKey:        mustGetEnv("AWS_SECRET_ACCESS_KEY"),
+	cfg, err := LoadFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid configuration")
 	}
-
 	return cfg
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// LoadFile reads the settings from a YAML file, then from the environment and the .env
+// file, which override the file. An empty path reads the environment only.
+// Unknown keys in the file are rejected so typos don't silently fall back to the defaults.
+func LoadFile(path string) (*Config, error) {
+	godotenv.Load()
+
+	cfg := Default()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.readEnv(); err != nil {
+		return nil, err
 	}
-	return defaultValue
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+func (c *Config) readFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Config) readEnv() error {
+	env := &envReader{}
+	env.str(&c.VonageJWT, "VONAGE_JWT")
+	env.str(&c.LLMProvider, "LLM_PROVIDER")
+	env.str(&c.LLMBaseURL, "LLM_BASE_URL")
+	env.str(&c.OpenAIKey, "OPENAI_API_KEY")
+	env.str(&c.AnthropicAPIKey, "ANTHROPIC_API_KEY")
+	env.list(&c.LLMFallbackModels, "LLM_FALLBACK_MODELS")
+	env.integer(&c.LLMMaxAttempts, "LLM_MAX_ATTEMPTS")
+	env.str(&c.HistoryMode, "HISTORY_MODE")
+	env.integer(&c.HistoryMaxTokens, "HISTORY_MAX_TOKENS")
+	env.integer(&c.HistoryMaxTurns, "HISTORY_MAX_TURNS")
+	env.str(&c.HistoryStore, "HISTORY_STORE")
+	env.duration(&c.HistoryRetention, "HISTORY_RETENTION")
+	env.str(&c.DatabaseURL, "DATABASE_URL")
+	env.str(&c.SQLitePath, "SQLITE_PATH")
+	env.str(&c.ElevenLabsAPIKey, "ELEVENLABS_API_KEY")
+	env.str(&c.ElevenLabsVoiceID, "ELEVENLABS_VOICE_ID")
+	env.str(&c.ElevenLabsModelID, "ELEVENLABS_MODEL_ID")
+	env.str(&c.Port, "PORT")
+	env.str(&c.GeospecificMessagesAPIURL, "GEOSPECIFIC_MESSAGES_API_URL")
+	env.str(&c.MessagesAPIURL, "MESSAGES_API_URL")
+	env.str(&c.RedisAddr, "REDIS_ADDR")
+	env.str(&c.RedisPassword, "REDIS_PASSWORD")
+	env.integer(&c.RedisDB, "REDIS_DB")
+	env.str(&c.PhoneNumber, "PHONE_NUMBER")
+	env.str(&c.S3Bucket, "AWS_S3_BUCKET")
+	env.str(&c.S3Region, "AWS_REGION")
+	env.str(&c.AWSAccessKeyID, "AWS_ACCESS_KEY_ID")
+	env.str(&c.AWSSecretAccessKey, "AWS_SECRET_ACCESS_KEY")
+	env.apiKeys(&c.CRMAPIKeys, "CRM_API_KEYS")
+	env.str(&c.CRMJWTSecret, "CRM_JWT_SECRET")
+	env.list(&c.CRMCORSOrigins, "CRM_CORS_ORIGINS")
+	env.str(&c.OTLPEndpoint, "OTEL_EXPORTER_OTLP_ENDPOINT")
+	env.str(&c.OTelServiceName, "OTEL_SERVICE_NAME")
+	env.str(&c.TenantsFile, "TENANTS_FILE")
+	return errors.Join(env.errs...)
+}
+
+// Validate checks the settings and returns every problem found. The credentials of the
+// LLM provider are checked when the provider is created, since a provider passed in code needs none.
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(value, key string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", setting(key)))
+		}
+	}
+
+	require(c.VonageJWT, "VONAGE_JWT")
+	require(c.PhoneNumber, "PHONE_NUMBER")
+	require(c.RedisAddr, "REDIS_ADDR")
+
+	switch c.LLMProvider {
+	case "openai", "anthropic", "openai-compatible":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported %s %q", setting("LLM_PROVIDER"), c.LLMProvider))
+	}
+
+	switch c.HistoryMode {
+	case "full", "tokens", "turns", "summary":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported %s %q", setting("HISTORY_MODE"), c.HistoryMode))
+	}
+
+	switch c.HistoryStore {
+	case "redis", "sqlite":
+	case "postgres":
+		require(c.DatabaseURL, "DATABASE_URL")
+	default:
+		errs = append(errs, fmt.Errorf("unsupported %s %q", setting("HISTORY_STORE"), c.HistoryStore))
+	}
+
+	positive := func(value int, key string) {
+		if value < 1 {
+			errs = append(errs, fmt.Errorf("%s must be positive", setting(key)))
 		}
 	}
-	return defaultValue
+	positive(c.LLMMaxAttempts, "LLM_MAX_ATTEMPTS")
+	positive(c.HistoryMaxTokens, "HISTORY_MAX_TOKENS")
+	positive(c.HistoryMaxTurns, "HISTORY_MAX_TURNS")
+	if c.RedisDB < 0 {
+		errs = append(errs, fmt.Errorf("%s must not be negative", setting("REDIS_DB")))
+	}
+	if c.HistoryRetention < 0 {
+		errs = append(errs, fmt.Errorf("%s must not be negative", setting("HISTORY_RETENTION")))
+	}
+
+	for _, origin := range c.CRMCORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("invalid %s origin %q, expected scheme://host[:port]", setting("CRM_CORS_ORIGINS"), origin))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// setting names a setting by both its environment variable and its config file key.
+func setting(key string) string {
+	return key + " (" + strings.ToLower(key) + ")"
+}
+
+// envReader overrides settings with the environment variables that are set,
+// collecting the values that cannot be parsed.
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) str(target *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*target = value
+	}
 }
 
-func mustGetEnv(key string) string {
+func (r *envReader) integer(target *int, key string) {
 	value := os.Getenv(key)
 	if value == "" {
-		log.Fatal().Msgf("%s environment variable is required", key)
+		return
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", key, err))
+		return
 	}
-	return value
+	*target = intValue
 }
 
-func mustGetEnvWithDefault(key, defaultValue string) string {
+// duration parses values such as "24h" or "30m"; "0" disables the duration.
+func (r *envReader) duration(target *time.Duration, key string) {
 	value := os.Getenv(key)
 	if value == "" {
-		if defaultValue == "" {
-			log.Fatal().Msgf("%s environment variable is required", key)
+		return
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", key, err))
+		return
+	}
+	*target = duration
+}
+
+func (r *envReader) list(target *[]string, key string) {
+	if values := getEnvList(key); values != nil {
+		*target = values
+	}
+}
+
+func (r *envReader) apiKeys(target *[]auth.APIKey, key string) {
+	values := getEnvList(key)
+	if values == nil {
+		return
+	}
+	apiKeys := make([]auth.APIKey, 0, len(values))
+	for _, value := range values {
+		apiKey, err := auth.ParseAPIKey(value)
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("invalid %s: %w", key, err))
+			return
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	*target = apiKeys
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
 		}
-		return defaultValue
 	}
-	return value
+	return values
 }
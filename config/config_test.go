@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/auth"
+)
+
+// clearEnv keeps the environment of the machine running the tests out of the settings
+func clearEnv(t *testing.T) {
+	for _, key := range []string{"VONAGE_JWT", "PHONE_NUMBER", "HISTORY_MODE", "HISTORY_RETENTION", "CRM_API_KEYS", "LLM_MAX_ATTEMPTS"} {
+		t.Setenv(key, "")
+	}
+}
+
+func writeFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "chatbot.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, `
+vonage_jwt: jwt
+phone_number: "5511999990000"
+history_mode: summary
+history_retention: 2h
+llm_fallback_models: [gpt-4.1, gpt-4o-mini]
+crm_api_keys:
+  - dashboard:viewer:s3cret
+`)
+	t.Setenv("PHONE_NUMBER", "5511888880000")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.VonageJWT != "jwt" || cfg.HistoryMode != "summary" || cfg.HistoryRetention != 2*time.Hour {
+		t.Fatalf("file settings not read: %+v", cfg)
+	}
+	if cfg.PhoneNumber != "5511888880000" {
+		t.Fatalf("PhoneNumber = %q, want the environment to override the file", cfg.PhoneNumber)
+	}
+	if len(cfg.LLMFallbackModels) != 2 || cfg.Port != "8080" || cfg.LLMMaxAttempts != 3 {
+		t.Fatalf("defaults not kept: %+v", cfg)
+	}
+	if len(cfg.CRMAPIKeys) != 1 || cfg.CRMAPIKeys[0].Name != "dashboard" || cfg.CRMAPIKeys[0].Role != auth.Viewer {
+		t.Fatalf("CRMAPIKeys = %+v", cfg.CRMAPIKeys)
+	}
+	if cfg.ElevenLabsAPIKey != "" || cfg.S3Bucket != "" {
+		t.Fatal("audio settings should be optional")
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	clearEnv(t)
+
+	if _, err := LoadFile(writeFile(t, "vonage_jwt: jwt\nphone_numbr: \"5511\"\n")); err == nil {
+		t.Fatal("unknown key accepted")
+	}
+	if _, err := LoadFile(writeFile(t, "crm_api_keys: [dashboard:owner:s3cret]\n")); err == nil {
+		t.Fatal("invalid API key accepted")
+	}
+
+	_, err := LoadFile(writeFile(t, "history_mode: everything\nllm_max_attempts: 0\n"))
+	if err == nil {
+		t.Fatal("invalid settings accepted")
+	}
+	// Every problem is reported at once
+	for _, want := range []string{"VONAGE_JWT", "PHONE_NUMBER", "history_mode", "llm_max_attempts"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	t.Setenv("LLM_MAX_ATTEMPTS", "three")
+	if _, err := LoadFile(""); err == nil || !strings.Contains(err.Error(), "LLM_MAX_ATTEMPTS") {
+		t.Fatalf("unparsable environment variable error = %v", err)
+	}
+}
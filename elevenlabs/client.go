@@ -15,14 +15,17 @@
 //	client := elevenlabs.NewClient(apiKey, http.Client{}, awsClient)
 //
 //	// Transcribe audio
-//	text, err := client.TranscribeAudio("https://example.com/audio.mp3")
+//	text, err := client.TranscribeAudio(ctx, "https://example.com/audio.mp3")
 //
 //	// Convert text to speech
-//	audioURL, err := client.ConvertTextToSpeech(voiceID, "Hello world", modelID)
+//	audioURL, err := client.ConvertTextToSpeech(ctx, voiceID, "Hello world", modelID)
 package elevenlabs
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/NextMind-AI/chatbot-go/tracing"
 )
 
 const (
@@ -33,14 +36,16 @@ const (
 )
 
 type AWSClient interface {
-	UploadAudio(audioData []byte, voiceID string) (string, error)
+	UploadAudio(ctx context.Context, audioData []byte, voiceID string) (string, error)
 }
 
 type Client struct {
 	APIKey       string
 	LanguageCode string
-	HTTPClient   *http.Client
-	AWSClient    AWSClient
+	// VoiceID is the voice ConvertTextToSpeechDefault speaks with; empty uses the VoiceID constant
+	VoiceID    string
+	HTTPClient *http.Client
+	AWSClient  AWSClient
 }
 
 // NewClient creates a new ElevenLabs client with AWS integration for audio storage.
@@ -53,6 +58,9 @@ type Client struct {
 //
 // Returns a configured Client ready for use with ElevenLabs APIs.
 func NewClient(apiKey string, httpClient http.Client, awsClient AWSClient) Client {
+	// Requests are recorded as spans of the trace in the context they are sent with
+	httpClient = tracing.HTTPClient("elevenlabs", httpClient)
+
 	return Client{
 		APIKey:       apiKey,
 		LanguageCode: "pt",
@@ -60,3 +68,14 @@ func NewClient(apiKey string, httpClient http.Client, awsClient AWSClient) Clien
 		AWSClient:    awsClient,
 	}
 }
+
+// CanTranscribe reports whether the client can transcribe audio, which needs an API key.
+func (c *Client) CanTranscribe() bool {
+	return c.APIKey != ""
+}
+
+// CanSpeak reports whether the client can convert text to speech, which also needs the
+// AWS client the generated audio is served from.
+func (c *Client) CanSpeak() bool {
+	return c.APIKey != "" && c.AWSClient != nil
+}
@@ -0,0 +1,12 @@
+package elevenlabs
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/metrics"
+)
+
+// observeRequest records the duration and outcome of an ElevenLabs API call.
+func observeRequest(operation string, start time.Time, err error) {
+	metrics.ElevenLabsRequestDuration.WithLabelValues(operation, metrics.Outcome(err)).Observe(metrics.Since(start))
+}
@@ -2,11 +2,13 @@ package elevenlabs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
@@ -24,10 +26,14 @@ const SpeechToTextPath = "/speech-to-text"
 //   - error: Any error that occurred during download or transcription
 //
 // Supported audio formats: MP3, WAV, OGG, AAC, FLAC, M4A, WebM
-func (c *Client) TranscribeAudio(url string) (string, error) {
+func (c *Client) TranscribeAudio(ctx context.Context, url string) (string, error) {
 	log.Info().Str("url", url).Msg("Downloading and transcribing audio from URL")
 
-	resp, err := c.HTTPClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create download request: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to download audio: %w", err)
 	}
@@ -37,7 +43,7 @@ func (c *Client) TranscribeAudio(url string) (string, error) {
 		return "", fmt.Errorf("failed to download audio: HTTP %d", resp.StatusCode)
 	}
 
-	return c.transcribeAudioFile(resp.Body, "audio.mp3")
+	return c.transcribeAudioFile(ctx, resp.Body, "audio.mp3")
 }
 
 // TranscribeAudioFile transcribes audio data from an io.Reader to text using ElevenLabs API.
@@ -54,11 +60,11 @@ func (c *Client) TranscribeAudio(url string) (string, error) {
 //
 // The transcription uses the configured language code and ElevenLabs' default speech-to-text model.
 // Supported audio formats: MP3, WAV, OGG, AAC, FLAC, M4A, WebM
-func (c *Client) TranscribeAudioFile(file io.Reader, fileName string) (string, error) {
-	return c.transcribeAudioFile(file, fileName)
+func (c *Client) TranscribeAudioFile(ctx context.Context, file io.Reader, fileName string) (string, error) {
+	return c.transcribeAudioFile(ctx, file, fileName)
 }
 
-func (c *Client) transcribeAudioFile(file io.Reader, fileName string) (string, error) {
+func (c *Client) transcribeAudioFile(ctx context.Context, file io.Reader, fileName string) (string, error) {
 	log.Info().Str("file_name", fileName).Msg("Transcribing audio file")
 
 	var body bytes.Buffer
@@ -88,7 +94,7 @@ func (c *Client) transcribeAudioFile(file io.Reader, fileName string) (string, e
 	}
 
 	url := BaseURL + SpeechToTextPath
-	req, err := http.NewRequest("POST", url, &body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, &body)
 	if err != nil {
 		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -96,14 +102,17 @@ func (c *Client) transcribeAudioFile(file io.Reader, fileName string) (string, e
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("xi-api-key", c.APIKey)
 
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
+		observeRequest("stt", start, err)
 		return "", fmt.Errorf("failed to make HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		observeRequest("stt", start, err)
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
@@ -113,8 +122,10 @@ func (c *Client) transcribeAudioFile(file io.Reader, fileName string) (string, e
 		if err := json.Unmarshal(respBody, &apiErr); err != nil {
 			apiErr.Message = string(respBody)
 		}
+		observeRequest("stt", start, apiErr)
 		return "", apiErr
 	}
+	observeRequest("stt", start, nil)
 
 	var result SpeechToTextResponse
 	if err := json.Unmarshal(respBody, &result); err != nil {
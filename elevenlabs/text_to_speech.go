@@ -2,18 +2,20 @@ package elevenlabs
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 const TextToSpeechPath = "/text-to-speech"
 
-// ConvertTextToSpeechDefault converts text to speech using the default hardcoded voice and model,
-// then uploads the audio to S3. This is a convenience method that uses predefined constants.
+// ConvertTextToSpeechDefault converts text to speech using the client's voice, or the default
+// hardcoded voice when none is set, and the default model, then uploads the audio to S3.
 //
 // Parameters:
 //   - text: The text content to convert to speech
@@ -21,8 +23,12 @@ const TextToSpeechPath = "/text-to-speech"
 // Returns:
 //   - string: Public S3 URL where the generated audio file can be accessed
 //   - error: Any error that occurred during text-to-speech conversion or S3 upload
-func (c *Client) ConvertTextToSpeechDefault(text string) (string, error) {
-	return c.ConvertTextToSpeech(VoiceID, text, ModelID)
+func (c *Client) ConvertTextToSpeechDefault(ctx context.Context, text string) (string, error) {
+	voiceID := c.VoiceID
+	if voiceID == "" {
+		voiceID = VoiceID
+	}
+	return c.ConvertTextToSpeech(ctx, voiceID, text, ModelID)
 }
 
 // ConvertTextToSpeech converts text to speech using ElevenLabs API and uploads the audio to S3.
@@ -40,7 +46,7 @@ func (c *Client) ConvertTextToSpeechDefault(text string) (string, error) {
 //
 // The audio file is stored in S3 with the path format: "audio/{voiceID}_{timestamp}.mp3"
 // and is publicly accessible via the returned URL.
-func (c *Client) ConvertTextToSpeech(voiceID string, text string, modelID string) (string, error) {
+func (c *Client) ConvertTextToSpeech(ctx context.Context, voiceID string, text string, modelID string) (string, error) {
 	log.Info().
 		Str("voice_id", voiceID).
 		Str("text", text).
@@ -59,7 +65,7 @@ func (c *Client) ConvertTextToSpeech(voiceID string, text string, modelID string
 
 	apiURL := fmt.Sprintf("%s%s/%s", BaseURL, TextToSpeechPath, voiceID)
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
@@ -67,14 +73,17 @@ func (c *Client) ConvertTextToSpeech(voiceID string, text string, modelID string
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("xi-api-key", c.APIKey)
 
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
+		observeRequest("tts", start, err)
 		return "", fmt.Errorf("failed to make HTTP request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
+		observeRequest("tts", start, err)
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
@@ -84,13 +93,15 @@ func (c *Client) ConvertTextToSpeech(voiceID string, text string, modelID string
 		if err := json.Unmarshal(respBody, &apiErr); err != nil {
 			apiErr.Message = string(respBody)
 		}
+		observeRequest("tts", start, apiErr)
 		return "", apiErr
 	}
+	observeRequest("tts", start, nil)
 
 	log.Info().
 		Str("voice_id", voiceID).
 		Int("audio_size_bytes", len(respBody)).
 		Msg("Text to speech conversion completed")
 
-	return c.AWSClient.UploadAudio(respBody, voiceID)
+	return c.AWSClient.UploadAudio(ctx, respBody, voiceID)
 }
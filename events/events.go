@@ -0,0 +1,138 @@
+// Package events broadcasts what happens in conversations, such as new messages,
+// delivery statuses and escalations, to the CRM through Redis pub/sub, so every
+// server instance can stream them to its clients.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Type identifies the kind of event.
+type Type string
+
+const (
+	UserMessage    Type = "user_message"
+	BotMessage     Type = "bot_message"
+	AgentMessage   Type = "agent_message"
+	DeliveryStatus Type = "delivery_status"
+	Escalation     Type = "escalation"
+)
+
+// Event is something that happened in a user's conversation.
+type Event struct {
+	Type      Type            `json:"type"`
+	UserID    string          `json:"user_id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// New returns an event of the given type for the user, with data encoded as JSON.
+func New(eventType Type, userID string, data any) (Event, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		Type:      eventType,
+		UserID:    userID,
+		Timestamp: time.Now(),
+		Data:      dataJSON,
+	}, nil
+}
+
+// Publisher sends events to the subscribers.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Bus publishes and subscribes to events over Redis pub/sub.
+type Bus struct {
+	client redis.Client
+}
+
+// NewBus returns a bus on the given Redis client.
+func NewBus(client redis.Client) *Bus {
+	return &Bus{client: client}
+}
+
+// Publish broadcasts the event to the subscribers of every server instance.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return b.client.PublishEvent(payload)
+}
+
+// Emit builds and publishes an event, logging failures instead of returning them:
+// the CRM stream is best effort and must not break message processing.
+func Emit(publisher Publisher, eventType Type, userID string, data any) {
+	if publisher == nil {
+		return
+	}
+	event, err := New(eventType, userID, data)
+	if err == nil {
+		err = publisher.Publish(context.Background(), event)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Str("event_type", string(eventType)).
+			Msg("Error publishing event")
+	}
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	events      chan Event
+	done        chan struct{}
+	unsubscribe func()
+	closeOnce   sync.Once
+}
+
+// Subscribe starts receiving events. The subscription must be closed when done.
+func (b *Bus) Subscribe() (*Subscription, error) {
+	payloads, unsubscribe, err := b.client.SubscribeEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{events: make(chan Event), done: make(chan struct{}), unsubscribe: unsubscribe}
+	go func() {
+		defer close(sub.events)
+		for payload := range payloads {
+			var event Event
+			if err := json.Unmarshal([]byte(payload), &event); err != nil {
+				log.Error().Err(err).Msg("Error decoding event")
+				continue
+			}
+			select {
+			case sub.events <- event:
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// Events returns the channel of events, closed once the subscription ends.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close ends the subscription.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.unsubscribe()
+	})
+}
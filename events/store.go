@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+)
+
+// MessageData is the data of a message event.
+type MessageData struct {
+	Role        string `json:"role"`
+	Content     string `json:"content"`
+	MessageUUID string `json:"message_uuid,omitempty"`
+	Agent       string `json:"agent,omitempty"`
+	Model       string `json:"model,omitempty"`
+}
+
+// publishingStore publishes an event for every message appended to the wrapped store.
+type publishingStore struct {
+	store.ConversationStore
+	publisher Publisher
+}
+
+// searchingPublishingStore keeps the search support of the wrapped store.
+type searchingPublishingStore struct {
+	publishingStore
+	store.Searcher
+}
+
+// NewStore wraps a conversation store so that each appended message is published
+// as a user_message, bot_message or agent_message event. The returned store
+// supports search when the wrapped one does.
+func NewStore(conversationStore store.ConversationStore, publisher Publisher) store.ConversationStore {
+	wrapped := publishingStore{ConversationStore: conversationStore, publisher: publisher}
+	if searcher, ok := conversationStore.(store.Searcher); ok {
+		return searchingPublishingStore{publishingStore: wrapped, Searcher: searcher}
+	}
+	return wrapped
+}
+
+// Append stores the message and publishes it once stored.
+func (s publishingStore) Append(ctx context.Context, userID string, message store.ChatMessage) error {
+	if err := s.ConversationStore.Append(ctx, userID, message); err != nil {
+		return err
+	}
+
+	eventType := UserMessage
+	switch message.Role {
+	case store.RoleAssistant:
+		eventType = BotMessage
+	case store.RoleAgent:
+		eventType = AgentMessage
+	}
+	Emit(s.publisher, eventType, userID, MessageData{
+		Role:        message.Role,
+		Content:     message.Content,
+		MessageUUID: message.MessageUUID,
+		Agent:       message.Agent,
+		Model:       message.Model,
+	})
+	return nil
+}
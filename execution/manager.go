@@ -2,24 +2,32 @@ package execution
 
 import (
 	"context"
+	"slices"
 	"sync"
 
+	"github.com/NextMind-AI/chatbot-go/metrics"
+
 	"github.com/rs/zerolog/log"
 )
 
 type UserExecution struct {
 	ctx    context.Context
 	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 type Manager struct {
 	userExecutions map[string]*UserExecution
-	mutex          sync.RWMutex
+	// running holds every execution of a user until it is cleaned up, including
+	// cancelled ones that are still unwinding
+	running map[string][]*UserExecution
+	mutex   sync.RWMutex
 }
 
 func NewManager() *Manager {
 	return &Manager{
 		userExecutions: make(map[string]*UserExecution),
+		running:        make(map[string][]*UserExecution),
 	}
 }
 
@@ -30,13 +38,34 @@ func (m *Manager) Start(userID string) context.Context {
 	if existingExecution, exists := m.userExecutions[userID]; exists {
 		log.Info().Str("user_id", userID).Msg("Cancelling previous execution for user")
 		existingExecution.cancel()
+		metrics.ExecutionsCancelled.Inc()
+	}
+
+	return m.start(userID)
+}
+
+// TryStart starts an execution for the user only when none is running, so background
+// work never cancels the turn a user is being answered in. It reports whether it started.
+func (m *Manager) TryStart(userID string) (context.Context, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.userExecutions[userID]; exists {
+		return nil, false
 	}
 
+	return m.start(userID), true
+}
+
+func (m *Manager) start(userID string) context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
-	m.userExecutions[userID] = &UserExecution{
+	execution := &UserExecution{
 		ctx:    ctx,
 		cancel: cancel,
+		done:   make(chan struct{}),
 	}
+	m.userExecutions[userID] = execution
+	m.running[userID] = append(m.running[userID], execution)
 
 	return ctx
 }
@@ -48,4 +77,40 @@ func (m *Manager) Cleanup(userID string, ctx context.Context) {
 	if execution, exists := m.userExecutions[userID]; exists && execution.ctx == ctx {
 		delete(m.userExecutions, userID)
 	}
+
+	running := m.running[userID]
+	if i := slices.IndexFunc(running, func(execution *UserExecution) bool { return execution.ctx == ctx }); i >= 0 {
+		running[i].cancel()
+		close(running[i].done)
+		running = slices.Delete(running, i, i+1)
+	}
+	if len(running) == 0 {
+		delete(m.running, userID)
+	} else {
+		m.running[userID] = running
+	}
+}
+
+// Stop cancels every execution of the user and waits until all of them are cleaned up,
+// so nothing they store happens after Stop returns. It gives up when ctx is done.
+func (m *Manager) Stop(ctx context.Context, userID string) error {
+	m.mutex.Lock()
+	running := slices.Clone(m.running[userID])
+	for _, execution := range running {
+		execution.cancel()
+	}
+	delete(m.userExecutions, userID)
+	m.mutex.Unlock()
+
+	if len(running) > 0 {
+		log.Info().Str("user_id", userID).Int("executions", len(running)).Msg("Stopping executions for user")
+	}
+	for _, execution := range running {
+		select {
+		case <-execution.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
 }
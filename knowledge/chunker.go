@@ -0,0 +1,85 @@
+package knowledge
+
+import (
+	"strings"
+)
+
+// SplitText splits text into chunks of at most size characters. Chunks follow paragraph
+// boundaries when possible, and each chunk starts with the last overlap characters of the
+// previous one so facts spanning a boundary are still found.
+func SplitText(text string, size, overlap int) []string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var pieces []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		pieces = append(pieces, splitWords(paragraph, size-overlap)...)
+	}
+
+	var chunks []string
+	var current strings.Builder
+	for _, piece := range pieces {
+		if current.Len() > 0 && current.Len()+len(piece)+2 > size {
+			chunk := current.String()
+			chunks = append(chunks, chunk)
+			current.Reset()
+			current.WriteString(tail(chunk, overlap))
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(piece)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitWords splits text into pieces of at most size characters at word boundaries.
+// A single word longer than size is kept whole.
+func splitWords(text string, size int) []string {
+	if len(text) <= size {
+		return []string{text}
+	}
+
+	var pieces []string
+	var current strings.Builder
+	for _, word := range strings.Fields(text) {
+		if current.Len() > 0 && current.Len()+len(word)+1 > size {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(word)
+	}
+	if current.Len() > 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
+
+// tail returns the last n characters of text, starting at a word boundary.
+func tail(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(text) <= n {
+		return text
+	}
+	text = text[len(text)-n:]
+	if i := strings.IndexAny(text, " \n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,116 @@
+package knowledge
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how related they are.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Dimensions() int
+}
+
+// OpenAIEmbedder embeds texts with the OpenAI embeddings API.
+type OpenAIEmbedder struct {
+	client     *openai.Client
+	model      string
+	dimensions int
+}
+
+// NewOpenAIEmbedder creates an embedder using text-embedding-3-small.
+func NewOpenAIEmbedder(apiKey string, httpClient http.Client) *OpenAIEmbedder {
+	client := openai.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(&httpClient),
+	)
+	return &OpenAIEmbedder{
+		client:     &client,
+		model:      openai.EmbeddingModelTextEmbedding3Small,
+		dimensions: 1536,
+	}
+}
+
+// Embed returns one vector per text.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Model: e.model,
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, data := range resp.Data {
+		vector := make([]float32, len(data.Embedding))
+		for i, value := range data.Embedding {
+			vector[i] = float32(value)
+		}
+		vectors[data.Index] = vector
+	}
+	return vectors, nil
+}
+
+// Dimensions returns the size of the vectors.
+func (e *OpenAIEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// HashEmbedder is an offline embedder that hashes the words of a text into a fixed-size
+// vector. Texts sharing words are similar, which is enough to test ingestion and retrieval
+// without network access, but it has no notion of meaning.
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder creates an offline embedder producing vectors of the given size.
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Embed returns one normalized bag-of-words vector per text.
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.dimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		for _, word := range words {
+			hash := fnv.New32a()
+			hash.Write([]byte(word))
+			vector[hash.Sum32()%uint32(e.dimensions)]++
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// Dimensions returns the size of the vectors.
+func (e *HashEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// normalize scales vector to unit length, so the dot product is the cosine similarity.
+func normalize(vector []float32) []float32 {
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
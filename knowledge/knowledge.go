@@ -0,0 +1,157 @@
+// Package knowledge implements a retrieval-augmented knowledge base for the chatbot.
+//
+// Documents (Markdown, plain text or PDF) are split into overlapping chunks, embedded
+// and stored in a vector index, either RediSearch or in-process. The bot reaches the
+// knowledge base through the search_knowledge tool, or by having the top-k chunks
+// injected before each response.
+//
+// Basic usage:
+//
+//	base := knowledge.NewBase(knowledge.NewOpenAIEmbedder(apiKey, http.Client{}), knowledge.NewMemoryIndex())
+//	if err := base.IngestDir(ctx, "./docs"); err != nil {
+//		log.Fatal().Err(err).Msg("Failed to ingest knowledge base")
+//	}
+//
+//	results, err := base.Search(ctx, "qual o prazo de entrega?", 3)
+package knowledge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	DefaultChunkSize    = 1000
+	DefaultChunkOverlap = 150
+	embedBatchSize      = 64
+)
+
+// Chunk is a piece of a document stored in the knowledge base.
+type Chunk struct {
+	ID     string
+	Source string
+	Index  int
+	Text   string
+}
+
+// Result is a chunk found by a search, with its cosine similarity to the query.
+type Result struct {
+	Chunk
+	Similarity float64
+}
+
+// Index stores chunk embeddings and finds the ones closest to a query vector.
+type Index interface {
+	Add(ctx context.Context, chunks []Chunk, vectors [][]float32) error
+	Search(ctx context.Context, vector []float32, k int) ([]Result, error)
+	DeleteSource(ctx context.Context, source string) error
+}
+
+// Base is a knowledge base: it ingests documents and searches them.
+type Base struct {
+	embedder Embedder
+	index    Index
+	// ChunkSize is the maximum number of characters in a chunk.
+	ChunkSize int
+	// ChunkOverlap is the number of characters repeated between consecutive chunks.
+	ChunkOverlap int
+}
+
+// NewBase creates a knowledge base storing the embeddings of embedder in index.
+func NewBase(embedder Embedder, index Index) *Base {
+	return &Base{
+		embedder:     embedder,
+		index:        index,
+		ChunkSize:    DefaultChunkSize,
+		ChunkOverlap: DefaultChunkOverlap,
+	}
+}
+
+// Ingest chunks and embeds text and stores it under source, replacing any previous
+// version of the same source.
+func (b *Base) Ingest(ctx context.Context, source, text string) (int, error) {
+	texts := SplitText(text, b.ChunkSize, b.ChunkOverlap)
+
+	if err := b.index.DeleteSource(ctx, source); err != nil {
+		return 0, fmt.Errorf("failed to delete previous chunks of %s: %w", source, err)
+	}
+
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+
+		vectors, err := b.embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed %s: %w", source, err)
+		}
+
+		chunks := make([]Chunk, 0, end-start)
+		for i, chunkText := range texts[start:end] {
+			chunks = append(chunks, Chunk{
+				ID:     chunkID(source, start+i),
+				Source: source,
+				Index:  start + i,
+				Text:   chunkText,
+			})
+		}
+
+		if err := b.index.Add(ctx, chunks, vectors); err != nil {
+			return 0, fmt.Errorf("failed to index %s: %w", source, err)
+		}
+	}
+
+	log.Info().
+		Str("source", source).
+		Int("chunks", len(texts)).
+		Msg("Ingested knowledge document")
+
+	return len(texts), nil
+}
+
+// IngestFile loads a Markdown, text or PDF file and ingests it with its path as source.
+func (b *Base) IngestFile(ctx context.Context, path string) (int, error) {
+	text, err := LoadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return b.Ingest(ctx, path, text)
+}
+
+// IngestDir ingests every supported file under dir, recursively.
+func (b *Base) IngestDir(ctx context.Context, dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !Supported(path) {
+			return nil
+		}
+		_, err = b.IngestFile(ctx, path)
+		return err
+	})
+}
+
+// Search returns the k chunks most similar to query.
+func (b *Base) Search(ctx context.Context, query string, k int) ([]Result, error) {
+	vectors, err := b.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	return b.index.Search(ctx, vectors[0], k)
+}
+
+// Delete removes all chunks of source.
+func (b *Base) Delete(ctx context.Context, source string) error {
+	return b.index.DeleteSource(ctx, source)
+}
+
+// chunkID derives a stable chunk ID from its source and position.
+func chunkID(source string, index int) string {
+	hash := sha256.Sum256([]byte(source))
+	return fmt.Sprintf("%s:%d", hex.EncodeToString(hash[:8]), index)
+}
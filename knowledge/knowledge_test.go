@@ -0,0 +1,73 @@
+package knowledge_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NextMind-AI/chatbot-go/knowledge"
+)
+
+func TestSplitText(t *testing.T) {
+	text := strings.Repeat("Primeiro parágrafo com algumas palavras. ", 10) + "\n\n" +
+		strings.Repeat("Segundo parágrafo sobre outro assunto. ", 10)
+
+	chunks := knowledge.SplitText(text, 200, 40)
+
+	if len(chunks) < 3 {
+		t.Fatalf("Expected the text to be split in several chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 200 {
+			t.Errorf("Chunk %d has %d characters, more than the chunk size", i, len(chunk))
+		}
+	}
+
+	// Consecutive chunks share their boundary
+	lastWord := strings.Fields(chunks[0])[len(strings.Fields(chunks[0]))-1]
+	if !strings.Contains(chunks[1], lastWord) {
+		t.Errorf("Expected chunk 1 to overlap with the end of chunk 0 (%q), got %q", lastWord, chunks[1])
+	}
+}
+
+func TestBase_IngestAndSearch(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"entrega.md": "# Entrega\n\nO prazo de entrega para capitais é de 3 dias úteis.",
+		"troca.txt":  "Trocas e devoluções podem ser feitas em até 30 dias após o recebimento.",
+		"notas.csv":  "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	base := knowledge.NewBase(knowledge.NewHashEmbedder(256), knowledge.NewMemoryIndex())
+	if err := base.IngestDir(ctx, dir); err != nil {
+		t.Fatalf("IngestDir returned error: %v", err)
+	}
+
+	results, err := base.Search(ctx, "qual o prazo de entrega?", 1)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(results) != 1 || filepath.Base(results[0].Source) != "entrega.md" {
+		t.Fatalf("Expected the delivery document, got %+v", results)
+	}
+
+	// Re-ingesting a source replaces its chunks instead of duplicating them
+	if _, err := base.Ingest(ctx, results[0].Source, "O prazo de entrega agora é de 2 dias úteis."); err != nil {
+		t.Fatalf("Ingest returned error: %v", err)
+	}
+	results, err = base.Search(ctx, "prazo de entrega", 5)
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if len(results) != 2 || !strings.Contains(results[0].Text, "2 dias") {
+		t.Errorf("Expected the updated delivery chunk first and no duplicates, got %+v", results)
+	}
+}
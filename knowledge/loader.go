@@ -0,0 +1,61 @@
+package knowledge
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Supported reports whether LoadFile can read the file, based on its extension.
+func Supported(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".txt", ".pdf":
+		return true
+	default:
+		return false
+	}
+}
+
+// LoadFile returns the text of a Markdown, plain text or PDF file.
+func LoadFile(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".txt":
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	case ".pdf":
+		return loadPDF(path)
+	default:
+		return "", fmt.Errorf("unsupported knowledge file type: %s", path)
+	}
+}
+
+// loadPDF extracts the plain text of every page of a PDF file.
+func loadPDF(path string) (string, error) {
+	file, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PDF %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var text bytes.Buffer
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pageText, err := page.GetPlainText(nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to read page %d of %s: %w", i, path, err)
+		}
+		text.WriteString(pageText)
+		text.WriteString("\n\n")
+	}
+	return text.String(), nil
+}
@@ -0,0 +1,83 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryIndex is an in-process index doing exact cosine search over all chunks.
+// It suits knowledge bases of up to a few tens of thousands of chunks loaded at startup.
+type MemoryIndex struct {
+	mutex   sync.RWMutex
+	chunks  []Chunk
+	vectors [][]float32
+}
+
+// NewMemoryIndex creates an empty in-process index.
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{}
+}
+
+// Add stores chunks with their vectors.
+func (idx *MemoryIndex) Add(_ context.Context, chunks []Chunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("got %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	for i, chunk := range chunks {
+		idx.chunks = append(idx.chunks, chunk)
+		idx.vectors = append(idx.vectors, normalize(vectors[i]))
+	}
+	return nil
+}
+
+// Search returns the k chunks most similar to vector.
+func (idx *MemoryIndex) Search(_ context.Context, vector []float32, k int) ([]Result, error) {
+	vector = normalize(append([]float32{}, vector...))
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	results := make([]Result, 0, len(idx.chunks))
+	for i, chunk := range idx.chunks {
+		if len(idx.vectors[i]) != len(vector) {
+			return nil, fmt.Errorf("query has %d dimensions, index has %d", len(vector), len(idx.vectors[i]))
+		}
+		var similarity float64
+		for j, value := range vector {
+			similarity += float64(value) * float64(idx.vectors[i][j])
+		}
+		results = append(results, Result{Chunk: chunk, Similarity: similarity})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Similarity > results[j].Similarity
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// DeleteSource removes all chunks of source.
+func (idx *MemoryIndex) DeleteSource(_ context.Context, source string) error {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	chunks := idx.chunks[:0]
+	vectors := idx.vectors[:0]
+	for i, chunk := range idx.chunks {
+		if chunk.Source != source {
+			chunks = append(chunks, chunk)
+			vectors = append(vectors, idx.vectors[i])
+		}
+	}
+	idx.chunks = chunks
+	idx.vectors = vectors
+	return nil
+}
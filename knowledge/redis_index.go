@@ -0,0 +1,76 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+)
+
+// RedisStore is the RediSearch storage used by RedisIndex. It is implemented by *redis.Client.
+type RedisStore interface {
+	EnsureKnowledgeIndex(dimensions int) error
+	AddKnowledgeChunks(chunks []redis.KnowledgeChunk) error
+	SearchKnowledge(vector []float32, k int) ([]redis.KnowledgeMatch, error)
+	DeleteKnowledgeSource(source string) error
+}
+
+// RedisIndex stores chunks in a RediSearch HNSW vector index, shared by every instance
+// of the bot and kept across restarts.
+type RedisIndex struct {
+	store RedisStore
+}
+
+// NewRedisIndex creates the vector index for vectors of the given size if needed.
+func NewRedisIndex(store RedisStore, dimensions int) (*RedisIndex, error) {
+	if err := store.EnsureKnowledgeIndex(dimensions); err != nil {
+		return nil, fmt.Errorf("failed to create knowledge index: %w", err)
+	}
+	return &RedisIndex{store: store}, nil
+}
+
+// Add stores chunks with their vectors.
+func (idx *RedisIndex) Add(_ context.Context, chunks []Chunk, vectors [][]float32) error {
+	if len(chunks) != len(vectors) {
+		return fmt.Errorf("got %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+
+	redisChunks := make([]redis.KnowledgeChunk, 0, len(chunks))
+	for i, chunk := range chunks {
+		redisChunks = append(redisChunks, redis.KnowledgeChunk{
+			ID:        chunk.ID,
+			Source:    chunk.Source,
+			Index:     chunk.Index,
+			Text:      chunk.Text,
+			Embedding: vectors[i],
+		})
+	}
+	return idx.store.AddKnowledgeChunks(redisChunks)
+}
+
+// Search returns the k chunks most similar to vector.
+func (idx *RedisIndex) Search(_ context.Context, vector []float32, k int) ([]Result, error) {
+	matches, err := idx.store.SearchKnowledge(vector, k)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, Result{
+			Chunk: Chunk{
+				ID:     match.ID,
+				Source: match.Source,
+				Index:  match.Index,
+				Text:   match.Text,
+			},
+			Similarity: match.Similarity,
+		})
+	}
+	return results, nil
+}
+
+// DeleteSource removes all chunks of source.
+func (idx *RedisIndex) DeleteSource(_ context.Context, source string) error {
+	return idx.store.DeleteKnowledgeSource(source)
+}
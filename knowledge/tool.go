@@ -0,0 +1,80 @@
+package knowledge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/openai"
+)
+
+// SearchTool returns a search_knowledge tool the model calls to look up the knowledge base.
+// It returns the top k chunks for the query.
+func SearchTool(base *Base, k int) openai.Tool {
+	return openai.Tool{
+		Definition: llm.ToolDefinition{
+			Name:        "search_knowledge",
+			Description: "Busca na base de conhecimento da empresa (catálogo de produtos, preços, políticas e perguntas frequentes). Use sempre que o usuário perguntar algo que dependa dessas informações.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "O que buscar, em linguagem natural",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			query, _ := args["query"].(string)
+			if strings.TrimSpace(query) == "" {
+				return "", fmt.Errorf("query is required")
+			}
+
+			results, err := base.Search(ctx, query, k)
+			if err != nil {
+				return "", err
+			}
+			if len(results) == 0 {
+				return "Nada encontrado na base de conhecimento.", nil
+			}
+			return FormatResults(results), nil
+		},
+	}
+}
+
+// Retriever returns a retriever that injects the top k chunks whose similarity to the
+// user's messages is at least minSimilarity.
+func Retriever(base *Base, k int, minSimilarity float64) openai.KnowledgeRetriever {
+	return func(ctx context.Context, query string) (string, error) {
+		results, err := base.Search(ctx, query, k)
+		if err != nil {
+			return "", err
+		}
+
+		relevant := results[:0]
+		for _, result := range results {
+			if result.Similarity >= minSimilarity {
+				relevant = append(relevant, result)
+			}
+		}
+		if len(relevant) == 0 {
+			return "", nil
+		}
+		return FormatResults(relevant), nil
+	}
+}
+
+// FormatResults formats search results for the model, citing the source of each chunk.
+func FormatResults(results []Result) string {
+	var formatted strings.Builder
+	for i, result := range results {
+		if i > 0 {
+			formatted.WriteString("\n\n---\n\n")
+		}
+		fmt.Fprintf(&formatted, "[%s]\n%s", result.Source, result.Text)
+	}
+	return formatted.String()
+}
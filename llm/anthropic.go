@@ -0,0 +1,426 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	AnthropicBaseURL   = "https://api.anthropic.com"
+	AnthropicVersion   = "2023-06-01"
+	anthropicMaxTokens = 4096
+)
+
+// ErrSchemaWithTools is returned for requests with both tools and a response schema. The
+// schema is produced through a forced tool, so the model could not call the other tools,
+// and a reply mixing tool calls with the schema would lose one of them.
+var ErrSchemaWithTools = errors.New("anthropic: a response schema cannot be combined with tools")
+
+// AnthropicProvider talks to the Anthropic Messages API.
+//
+// Structured output is implemented with a forced tool whose input schema is the
+// requested JSON schema, so the streamed tool input is the JSON document itself.
+type AnthropicProvider struct {
+	apiKey     string
+	baseURL    string
+	maxTokens  int
+	httpClient *http.Client
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API.
+// An empty baseURL uses the public Anthropic endpoint.
+func NewAnthropicProvider(apiKey, baseURL string, httpClient http.Client) *AnthropicProvider {
+	if baseURL == "" {
+		baseURL = AnthropicBaseURL
+	}
+	return &AnthropicProvider{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		maxTokens:  anthropicMaxTokens,
+		httpClient: &httpClient,
+	}
+}
+
+// Name returns the provider name.
+func (p *AnthropicProvider) Name() string {
+	return "anthropic"
+}
+
+type anthropicRequest struct {
+	Model      string               `json:"model"`
+	MaxTokens  int                  `json:"max_tokens"`
+	System     string               `json:"system,omitempty"`
+	Messages   []anthropicMessage   `json:"messages"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Stream     bool                 `json:"stream,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+type anthropicResponse struct {
+	Model   string                  `json:"model"`
+	Content []anthropicContentBlock `json:"content"`
+	Usage   anthropicUsage          `json:"usage"`
+}
+
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete runs a request and returns the whole response.
+func (p *AnthropicProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	body, schemaTool, err := p.buildRequest(req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := p.send(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var apiResp anthropicResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode anthropic response: %w", err)
+	}
+
+	resp := &Response{
+		Model: apiResp.Model,
+		Usage: Usage{
+			PromptTokens:     apiResp.Usage.InputTokens,
+			CompletionTokens: apiResp.Usage.OutputTokens,
+			TotalTokens:      apiResp.Usage.InputTokens + apiResp.Usage.OutputTokens,
+		},
+	}
+
+	for _, block := range apiResp.Content {
+		switch block.Type {
+		case "text":
+			resp.Content += block.Text
+		case "tool_use":
+			if block.Name == schemaTool {
+				resp.Content += string(block.Input)
+				continue
+			}
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: string(block.Input),
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+// Stream runs a streaming request.
+func (p *AnthropicProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	body, schemaTool, err := p.buildRequest(req, true)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := p.send(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anthropicStream{
+		body:          httpResp.Body,
+		reader:        newSSEReader(httpResp.Body),
+		schemaTool:    schemaTool,
+		contentBlocks: map[int]bool{},
+	}, nil
+}
+
+// buildRequest converts a provider-neutral request to the Messages API format.
+// It returns the name of the tool used for structured output, if any.
+func (p *AnthropicProvider) buildRequest(req Request, stream bool) (anthropicRequest, string, error) {
+	if req.ResponseSchema != nil && len(req.Tools) > 0 {
+		return anthropicRequest{}, "", ErrSchemaWithTools
+	}
+
+	apiReq := anthropicRequest{
+		Model:     req.Model,
+		MaxTokens: p.maxTokens,
+		Stream:    stream,
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case RoleSystem:
+			system = append(system, msg.Content)
+		case RoleUser:
+			apiReq.Messages = appendAnthropicBlock(apiReq.Messages, "user", anthropicContentBlock{Type: "text", Text: msg.Content})
+		case RoleAssistant:
+			if msg.Content != "" {
+				apiReq.Messages = appendAnthropicBlock(apiReq.Messages, "assistant", anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, toolCall := range msg.ToolCalls {
+				input := json.RawMessage(toolCall.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				apiReq.Messages = appendAnthropicBlock(apiReq.Messages, "assistant", anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    toolCall.ID,
+					Name:  toolCall.Name,
+					Input: input,
+				})
+			}
+		case RoleTool:
+			apiReq.Messages = appendAnthropicBlock(apiReq.Messages, "user", anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		}
+	}
+
+	for _, tool := range req.Tools {
+		var inputSchema any = tool.Parameters
+		if tool.Parameters == nil {
+			inputSchema = map[string]any{"type": "object"}
+		}
+		apiReq.Tools = append(apiReq.Tools, anthropicTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: inputSchema,
+		})
+	}
+
+	if req.ToolChoice != "" {
+		apiReq.ToolChoice = &anthropicToolChoice{Type: "tool", Name: req.ToolChoice}
+	}
+
+	var schemaTool string
+	if req.ResponseSchema != nil {
+		// Force a tool whose input is the structured response
+		schemaTool = req.ResponseSchema.Name
+		apiReq.Tools = []anthropicTool{{
+			Name:        schemaTool,
+			Description: req.ResponseSchema.Description,
+			InputSchema: cleanSchema(req.ResponseSchema.Schema),
+		}}
+		apiReq.ToolChoice = &anthropicToolChoice{Type: "tool", Name: schemaTool}
+	}
+
+	apiReq.System = strings.Join(system, "\n\n")
+	return apiReq, schemaTool, nil
+}
+
+// appendAnthropicBlock adds a content block, merging consecutive messages of the same role
+// since the Messages API expects user and assistant turns to alternate.
+func appendAnthropicBlock(messages []anthropicMessage, role string, block anthropicContentBlock) []anthropicMessage {
+	if len(messages) > 0 && messages[len(messages)-1].Role == role {
+		messages[len(messages)-1].Content = append(messages[len(messages)-1].Content, block)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: []anthropicContentBlock{block}})
+}
+
+// cleanSchema drops JSON schema meta keywords that are not accepted as a tool input schema.
+func cleanSchema(schema any) any {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var cleaned map[string]any
+	if err := json.Unmarshal(schemaJSON, &cleaned); err != nil {
+		return schema
+	}
+	delete(cleaned, "$schema")
+	delete(cleaned, "$id")
+	return cleaned
+}
+
+func (p *AnthropicProvider) send(ctx context.Context, body anthropicRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+
+		apiErr := &APIError{Provider: p.Name(), StatusCode: resp.StatusCode, Message: string(respBody)}
+		var errResp anthropicErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
+			apiErr.Message = errResp.Error.Message
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// anthropicStream decodes the Messages API event stream.
+// When structured output is forced through a tool, only that tool's input deltas are
+// reported as content, so it streams its JSON exactly like a text response. Otherwise
+// only text deltas are; the input of any other tool the model calls is never mixed into
+// the reply.
+type anthropicStream struct {
+	body       io.ReadCloser
+	reader     *sseReader
+	schemaTool string
+	// contentBlocks holds the indexes of the content blocks whose deltas are content
+	contentBlocks map[int]bool
+	current       StreamChunk
+	usage         Usage
+	finished      bool
+	err           error
+}
+
+type anthropicStreamEvent struct {
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	ContentBlock struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *anthropicStream) Next() bool {
+	if s.finished || s.err != nil {
+		return false
+	}
+
+	for {
+		event, err := s.reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// The connection closed before message_stop
+				err = io.ErrUnexpectedEOF
+			}
+			s.err = err
+			return false
+		}
+
+		var data anthropicStreamEvent
+		if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+			s.err = fmt.Errorf("failed to decode anthropic stream event: %w", err)
+			return false
+		}
+
+		switch data.Type {
+		case "message_start":
+			s.usage.PromptTokens = data.Message.Usage.InputTokens
+		case "content_block_start":
+			s.contentBlocks[data.Index] = s.isContentBlock(data.ContentBlock.Type, data.ContentBlock.Name)
+		case "content_block_delta":
+			if !s.contentBlocks[data.Index] {
+				continue
+			}
+			content := data.Delta.Text
+			if data.Delta.Type == "input_json_delta" {
+				content = data.Delta.PartialJSON
+			}
+			s.current = StreamChunk{Content: content}
+			return true
+		case "message_delta":
+			s.usage.CompletionTokens = data.Usage.OutputTokens
+		case "message_stop":
+			s.finished = true
+			s.usage.TotalTokens = s.usage.PromptTokens + s.usage.CompletionTokens
+			usage := s.usage
+			s.current = StreamChunk{Usage: &usage}
+			return true
+		case "error":
+			statusCode := http.StatusInternalServerError
+			if data.Error.Type == "overloaded_error" {
+				statusCode = 529
+			}
+			s.err = &APIError{Provider: "anthropic", StatusCode: statusCode, Message: data.Error.Message}
+			return false
+		}
+	}
+}
+
+// isContentBlock reports whether the deltas of a content block belong to the reply.
+func (s *anthropicStream) isContentBlock(blockType, name string) bool {
+	if s.schemaTool != "" {
+		return blockType == "tool_use" && name == s.schemaTool
+	}
+	return blockType == "text"
+}
+
+func (s *anthropicStream) Current() StreamChunk {
+	return s.current
+}
+
+func (s *anthropicStream) Err() error {
+	return s.err
+}
+
+func (s *anthropicStream) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,183 @@
+package llm_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+)
+
+func TestAnthropicProvider_StructuredStream(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	server.Enqueue(llmtest.AnthropicToolInputStream("message_list", `{"messages":[`, `{"content":"Oi","type":"text"}]}`))
+
+	provider := llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	stream, err := provider.Stream(context.Background(), llm.Request{
+		Model: "claude-test",
+		Messages: []llm.Message{
+			llm.SystemMessage("prompt"),
+			llm.UserMessage("Olá"),
+			llm.AssistantToolCallMessage("", llm.ToolCall{ID: "call_1", Name: "get_time", Arguments: `{}`}),
+			llm.ToolMessage("14:30", "call_1"),
+		},
+		ResponseSchema: &llm.JSONSchema{
+			Name:   "message_list",
+			Schema: map[string]any{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	var usage *llm.Usage
+	for stream.Next() {
+		chunk := stream.Current()
+		content.WriteString(chunk.Content)
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+
+	if content.String() != `{"messages":[{"content":"Oi","type":"text"}]}` {
+		t.Errorf("Unexpected streamed content: %q", content.String())
+	}
+	if usage == nil || usage.TotalTokens != 15 {
+		t.Errorf("Expected usage with 15 total tokens, got %+v", usage)
+	}
+
+	var sent map[string]any
+	if err := json.Unmarshal([]byte(server.Requests()[0]), &sent); err != nil {
+		t.Fatalf("Invalid request body: %v", err)
+	}
+	if sent["system"] != "prompt" {
+		t.Errorf("Expected system prompt to be sent separately, got %v", sent["system"])
+	}
+	if choice, _ := sent["tool_choice"].(map[string]any); choice["name"] != "message_list" {
+		t.Errorf("Expected structured output tool to be forced, got %v", sent["tool_choice"])
+	}
+	if messages, _ := sent["messages"].([]any); len(messages) != 3 {
+		t.Errorf("Expected user, assistant and tool result turns, got %d", len(messages))
+	}
+}
+
+func TestAnthropicProvider_CompleteWithToolCalls(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	server.Enqueue(llmtest.AnthropicMessage("", [2]string{"sleep", `{"seconds":12}`}))
+
+	provider := llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	resp, err := provider.Complete(context.Background(), llm.Request{
+		Model:      "claude-test",
+		Messages:   []llm.Message{llm.UserMessage("Oi")},
+		Tools:      []llm.ToolDefinition{{Name: "sleep"}},
+		ToolChoice: "sleep",
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments != `{"seconds":12}` {
+		t.Errorf("Unexpected tool calls: %+v", resp.ToolCalls)
+	}
+}
+
+func TestAnthropicProvider_TruncatedStream(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	truncated := llmtest.AnthropicStream("Olá", " mundo")
+	truncated.Events = truncated.Events[:3]
+	truncated.Truncate = true
+	server.Enqueue(truncated)
+
+	provider := llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	stream, err := provider.Stream(context.Background(), llm.Request{
+		Model:    "claude-test",
+		Messages: []llm.Message{llm.UserMessage("Oi")},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	defer stream.Close()
+
+	for stream.Next() {
+	}
+	if !errors.Is(stream.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("Expected unexpected EOF for a broken stream, got %v", stream.Err())
+	}
+}
+
+func TestAnthropicProvider_StreamIgnoresOtherToolInput(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	// A text reply followed by a call to a real tool at index 1
+	response := llmtest.AnthropicStream(`{"messages":[]}`)
+	toolUse := []llmtest.Event{
+		{Name: "content_block_start", Data: `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_time","input":{}}}`},
+		{Name: "content_block_delta", Data: `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"zone\":\"BRT\"}"}}`},
+		{Name: "content_block_stop", Data: `{"type":"content_block_stop","index":1}`},
+	}
+	end := len(response.Events) - 2
+	response.Events = append(response.Events[:end], append(toolUse, response.Events[end:]...)...)
+	server.Enqueue(response)
+
+	provider := llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	stream, err := provider.Stream(context.Background(), llm.Request{
+		Model:    "claude-test",
+		Messages: []llm.Message{llm.UserMessage("Que horas são?")},
+		Tools:    []llm.ToolDefinition{{Name: "get_time"}},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	for stream.Next() {
+		content.WriteString(stream.Current().Content)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+
+	if content.String() != `{"messages":[]}` {
+		t.Errorf("Expected only the text reply to be streamed, got %q", content.String())
+	}
+}
+
+func TestAnthropicProvider_RejectsSchemaWithTools(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	provider := llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	_, err := provider.Stream(context.Background(), llm.Request{
+		Model:          "claude-test",
+		Messages:       []llm.Message{llm.UserMessage("Que horas são?")},
+		Tools:          []llm.ToolDefinition{{Name: "get_time"}},
+		ResponseSchema: &llm.JSONSchema{Name: "message_list", Schema: map[string]any{"type": "object"}},
+	})
+	if !errors.Is(err, llm.ErrSchemaWithTools) {
+		t.Fatalf("Stream returned %v, want ErrSchemaWithTools", err)
+	}
+	if llm.IsRetryable(err) {
+		t.Error("ErrSchemaWithTools must not be retried")
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("made %d requests, want none", len(server.Requests()))
+	}
+}
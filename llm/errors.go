@@ -0,0 +1,15 @@
+package llm
+
+import "fmt"
+
+// APIError is an error response returned by a provider's HTTP API.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface for APIError.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d): %s", e.Provider, e.StatusCode, e.Message)
+}
@@ -0,0 +1,289 @@
+// Package llmtest provides a local fake LLM server that replays canned responses,
+// so providers and the conversation flow can be tested without network access.
+//
+// Basic usage:
+//
+//	server := llmtest.NewServer()
+//	defer server.Close()
+//
+//	server.Enqueue(llmtest.OpenAIStream(`{"messages":[`, `{"content":"Oi","type":"text"}]}`))
+//	provider := llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{})
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Response is a canned HTTP response served by the fake server.
+// When Events is set the response is written as a Server-Sent Events stream,
+// otherwise Body is written as-is.
+type Response struct {
+	StatusCode int
+	Body       string
+	Events     []Event
+	// Truncate closes the connection after the events without the stream terminator,
+	// simulating a stream that breaks mid-way.
+	Truncate bool
+}
+
+// Event is a single Server-Sent Event.
+type Event struct {
+	Name string
+	Data string
+}
+
+// Server is an HTTP server that answers every request with the next queued response.
+type Server struct {
+	*httptest.Server
+
+	mutex     sync.Mutex
+	responses []Response
+	requests  []string
+}
+
+// NewServer starts a fake LLM server. Callers must Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Enqueue adds responses to be served in order, one per request.
+func (s *Server) Enqueue(responses ...Response) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responses = append(s.responses, responses...)
+}
+
+// Requests returns the bodies of all requests received so far.
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, string(body))
+	if len(s.responses) == 0 {
+		s.mutex.Unlock()
+		http.Error(w, `{"error":{"message":"no canned response left"}}`, http.StatusInternalServerError)
+		return
+	}
+	resp := s.responses[0]
+	s.responses = s.responses[1:]
+	s.mutex.Unlock()
+
+	statusCode := resp.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	if resp.Events == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		io.WriteString(w, resp.Body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(statusCode)
+	flusher, _ := w.(http.Flusher)
+
+	for _, event := range resp.Events {
+		if event.Name != "" {
+			fmt.Fprintf(w, "event: %s\n", event.Name)
+		}
+		fmt.Fprintf(w, "data: %s\n\n", event.Data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if resp.Truncate {
+		// Hijack the connection and drop it so the client sees a broken stream
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+			}
+		}
+	}
+}
+
+// ReplayFile loads a raw Server-Sent Events capture, e.g. recorded with curl, as a response.
+func ReplayFile(path string) (Response, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Response{}, err
+	}
+	return Replay(string(raw)), nil
+}
+
+// Replay parses a raw Server-Sent Events stream into a response.
+func Replay(raw string) Response {
+	var events []Event
+	for _, block := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n\n") {
+		var event Event
+		var data []string
+		for _, line := range strings.Split(block, "\n") {
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event.Name = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+		if event.Name == "" && len(data) == 0 {
+			continue
+		}
+		event.Data = strings.Join(data, "\n")
+		events = append(events, event)
+	}
+	return Response{Events: events}
+}
+
+// ErrorResponse returns a JSON error response with the given status code.
+func ErrorResponse(statusCode int, message string) Response {
+	body, _ := json.Marshal(map[string]any{
+		"error": map[string]string{"message": message, "type": "error"},
+	})
+	return Response{StatusCode: statusCode, Body: string(body)}
+}
+
+// OpenAIStream returns a Chat Completions stream whose content deltas are chunks.
+func OpenAIStream(chunks ...string) Response {
+	event := func(delta map[string]string, finishReason any) Event {
+		data, _ := json.Marshal(map[string]any{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion.chunk",
+			"created": 0,
+			"model":   "test-model",
+			"choices": []map[string]any{{
+				"index":         0,
+				"delta":         delta,
+				"finish_reason": finishReason,
+			}},
+		})
+		return Event{Data: string(data)}
+	}
+
+	var events []Event
+	for _, chunk := range chunks {
+		events = append(events, event(map[string]string{"content": chunk}, nil))
+	}
+	events = append(events, event(map[string]string{}, "stop"), Event{Data: "[DONE]"})
+	return Response{Events: events}
+}
+
+// OpenAICompletion returns a Chat Completions response with the given content and tool calls.
+// Each tool call is given as name and JSON arguments pairs.
+func OpenAICompletion(content string, toolCalls ...[2]string) Response {
+	var calls []map[string]any
+	for i, toolCall := range toolCalls {
+		calls = append(calls, map[string]any{
+			"id":   fmt.Sprintf("call_%d", i),
+			"type": "function",
+			"function": map[string]string{
+				"name":      toolCall[0],
+				"arguments": toolCall[1],
+			},
+		})
+	}
+
+	message := map[string]any{"role": "assistant", "content": content}
+	if len(calls) > 0 {
+		message["tool_calls"] = calls
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"id":      "chatcmpl-test",
+		"object":  "chat.completion",
+		"created": 0,
+		"model":   "test-model",
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+	})
+	return Response{Body: string(body)}
+}
+
+// AnthropicStream returns a Messages API stream whose text deltas are chunks.
+func AnthropicStream(chunks ...string) Response {
+	return anthropicStream(map[string]any{"type": "text", "text": ""}, "text_delta", "text", chunks)
+}
+
+// AnthropicToolInputStream returns a Messages API stream whose tool input JSON deltas are chunks,
+// as produced when structured output is forced through the tool called toolName.
+func AnthropicToolInputStream(toolName string, chunks ...string) Response {
+	block := map[string]any{"type": "tool_use", "id": "toolu_0", "name": toolName, "input": map[string]any{}}
+	return anthropicStream(block, "input_json_delta", "partial_json", chunks)
+}
+
+func anthropicStream(block map[string]any, deltaType, deltaField string, chunks []string) Response {
+	event := func(name string, data map[string]any) Event {
+		encoded, _ := json.Marshal(data)
+		return Event{Name: name, Data: string(encoded)}
+	}
+
+	events := []Event{
+		event("message_start", map[string]any{
+			"type":    "message_start",
+			"message": map[string]any{"model": "test-model", "usage": map[string]int{"input_tokens": 10, "output_tokens": 0}},
+		}),
+		event("content_block_start", map[string]any{"type": "content_block_start", "index": 0, "content_block": block}),
+	}
+	for _, chunk := range chunks {
+		events = append(events, event("content_block_delta", map[string]any{
+			"type":  "content_block_delta",
+			"index": 0,
+			"delta": map[string]string{"type": deltaType, deltaField: chunk},
+		}))
+	}
+	events = append(events,
+		event("content_block_stop", map[string]any{"type": "content_block_stop", "index": 0}),
+		event("message_delta", map[string]any{"type": "message_delta", "usage": map[string]int{"output_tokens": 5}}),
+		event("message_stop", map[string]any{"type": "message_stop"}),
+	)
+	return Response{Events: events}
+}
+
+// AnthropicMessage returns a Messages API response with the given text and tool calls.
+// Each tool call is given as name and JSON input pairs.
+func AnthropicMessage(text string, toolCalls ...[2]string) Response {
+	var content []map[string]any
+	if text != "" {
+		content = append(content, map[string]any{"type": "text", "text": text})
+	}
+	for i, toolCall := range toolCalls {
+		content = append(content, map[string]any{
+			"type":  "tool_use",
+			"id":    fmt.Sprintf("toolu_%d", i),
+			"name":  toolCall[0],
+			"input": json.RawMessage(toolCall[1]),
+		})
+	}
+
+	body, _ := json.Marshal(map[string]any{
+		"id":      "msg_test",
+		"type":    "message",
+		"role":    "assistant",
+		"model":   "test-model",
+		"content": content,
+		"usage":   map[string]int{"input_tokens": 10, "output_tokens": 5},
+	})
+	return Response{Body: string(body)}
+}
@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/packages/ssestream"
+)
+
+// OpenAIProvider talks to the OpenAI Chat Completions API or to any server implementing it.
+type OpenAIProvider struct {
+	client *openai.Client
+	name   string
+}
+
+// NewOpenAIProvider creates a provider for the OpenAI API.
+func NewOpenAIProvider(apiKey string, httpClient http.Client) *OpenAIProvider {
+	client := openai.NewClient(
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(&httpClient),
+		// Retries are driven by RetryPolicy so they can fall back to other models
+		option.WithMaxRetries(0),
+	)
+	return &OpenAIProvider{client: &client, name: "openai"}
+}
+
+// NewOpenAICompatibleProvider creates a provider for a server exposing the OpenAI
+// Chat Completions API at baseURL, such as vLLM, Ollama or the llama.cpp server.
+// The API key may be empty when the server does not require one.
+func NewOpenAICompatibleProvider(baseURL, apiKey string, httpClient http.Client) *OpenAIProvider {
+	if apiKey == "" {
+		apiKey = "not-needed"
+	}
+	client := openai.NewClient(
+		option.WithBaseURL(baseURL),
+		option.WithAPIKey(apiKey),
+		option.WithHTTPClient(&httpClient),
+		option.WithMaxRetries(0),
+	)
+	return &OpenAIProvider{client: &client, name: "openai-compatible"}
+}
+
+// Name returns the provider name.
+func (p *OpenAIProvider) Name() string {
+	return p.name
+}
+
+// Complete runs a chat completion and returns the whole response.
+func (p *OpenAIProvider) Complete(ctx context.Context, req Request) (*Response, error) {
+	completion, err := p.client.Chat.Completions.New(ctx, p.params(req))
+	if err != nil {
+		return nil, p.convertError(err)
+	}
+
+	resp := &Response{
+		Model: completion.Model,
+		Usage: Usage{
+			PromptTokens:     completion.Usage.PromptTokens,
+			CompletionTokens: completion.Usage.CompletionTokens,
+			TotalTokens:      completion.Usage.TotalTokens,
+		},
+	}
+
+	if len(completion.Choices) > 0 {
+		message := completion.Choices[0].Message
+		resp.Content = message.Content
+		for _, toolCall := range message.ToolCalls {
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:        toolCall.ID,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+	}
+
+	return resp, nil
+}
+
+// Stream runs a streaming chat completion.
+func (p *OpenAIProvider) Stream(ctx context.Context, req Request) (Stream, error) {
+	params := p.params(req)
+	// The final chunk then carries the token usage of the whole completion, with no choices
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+	stream := p.client.Chat.Completions.NewStreaming(ctx, params)
+	return &openAIStream{stream: stream, provider: p}, nil
+}
+
+// params converts a provider-neutral request to the SDK parameters.
+func (p *OpenAIProvider) params(req Request) openai.ChatCompletionNewParams {
+	params := openai.ChatCompletionNewParams{
+		Model:    req.Model,
+		Messages: p.messages(req.Messages),
+	}
+
+	for _, tool := range req.Tools {
+		params.Tools = append(params.Tools, openai.ChatCompletionToolParam{
+			Function: openai.FunctionDefinitionParam{
+				Name:        tool.Name,
+				Description: openai.String(tool.Description),
+				Parameters:  openai.FunctionParameters(tool.Parameters),
+			},
+		})
+	}
+
+	if req.ToolChoice != "" {
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{
+			OfChatCompletionNamedToolChoice: &openai.ChatCompletionNamedToolChoiceParam{
+				Function: openai.ChatCompletionNamedToolChoiceFunctionParam{
+					Name: req.ToolChoice,
+				},
+			},
+		}
+	}
+
+	if req.ResponseSchema != nil {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:        req.ResponseSchema.Name,
+					Description: openai.String(req.ResponseSchema.Description),
+					Schema:      req.ResponseSchema.Schema,
+					Strict:      openai.Bool(req.ResponseSchema.Strict),
+				},
+			},
+		}
+	}
+
+	return params
+}
+
+func (p *OpenAIProvider) messages(messages []Message) []openai.ChatCompletionMessageParamUnion {
+	converted := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, msg := range messages {
+		switch msg.Role {
+		case RoleSystem:
+			converted = append(converted, openai.SystemMessage(msg.Content))
+		case RoleUser:
+			converted = append(converted, openai.UserMessage(msg.Content))
+		case RoleAssistant:
+			if len(msg.ToolCalls) == 0 {
+				converted = append(converted, openai.AssistantMessage(msg.Content))
+				continue
+			}
+			assistant := openai.ChatCompletionAssistantMessageParam{}
+			if msg.Content != "" {
+				assistant.Content.OfString = openai.String(msg.Content)
+			}
+			for _, toolCall := range msg.ToolCalls {
+				assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallParam{
+					ID: toolCall.ID,
+					Function: openai.ChatCompletionMessageToolCallFunctionParam{
+						Name:      toolCall.Name,
+						Arguments: toolCall.Arguments,
+					},
+				})
+			}
+			converted = append(converted, openai.ChatCompletionMessageParamUnion{OfAssistant: &assistant})
+		case RoleTool:
+			converted = append(converted, openai.ToolMessage(msg.Content, msg.ToolCallID))
+		}
+	}
+	return converted
+}
+
+// convertError turns SDK API errors into APIError so callers can inspect status codes.
+func (p *OpenAIProvider) convertError(err error) error {
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		return &APIError{
+			Provider:   p.name,
+			StatusCode: apiErr.StatusCode,
+			Message:    apiErr.Message,
+		}
+	}
+	return err
+}
+
+// openAIStream adapts the SDK stream to the Stream interface.
+type openAIStream struct {
+	stream   *ssestream.Stream[openai.ChatCompletionChunk]
+	provider *OpenAIProvider
+	current  StreamChunk
+	finished bool
+	err      error
+}
+
+func (s *openAIStream) Next() bool {
+	if !s.stream.Next() {
+		if s.stream.Err() == nil && !s.finished {
+			// The connection closed before the model finished its answer
+			s.err = io.ErrUnexpectedEOF
+		}
+		return false
+	}
+
+	evt := s.stream.Current()
+	s.current = StreamChunk{}
+	if len(evt.Choices) > 0 {
+		s.current.Content = evt.Choices[0].Delta.Content
+		if evt.Choices[0].FinishReason != "" {
+			s.finished = true
+		}
+	}
+	if evt.Usage.TotalTokens > 0 {
+		s.current.Usage = &Usage{
+			PromptTokens:     evt.Usage.PromptTokens,
+			CompletionTokens: evt.Usage.CompletionTokens,
+			TotalTokens:      evt.Usage.TotalTokens,
+		}
+	}
+	return true
+}
+
+func (s *openAIStream) Current() StreamChunk {
+	return s.current
+}
+
+func (s *openAIStream) Err() error {
+	if err := s.stream.Err(); err != nil {
+		return s.provider.convertError(err)
+	}
+	return s.err
+}
+
+func (s *openAIStream) Close() error {
+	return s.stream.Close()
+}
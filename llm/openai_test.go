@@ -0,0 +1,120 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+)
+
+func TestOpenAIProvider_Stream(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	server.Enqueue(llmtest.OpenAIStream(`{"messages":[`, `{"content":"Oi","type":"text"}`, `]}`))
+
+	provider := llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{})
+	stream, err := provider.Stream(context.Background(), llm.Request{
+		Model:    "test-model",
+		Messages: []llm.Message{llm.SystemMessage("prompt"), llm.UserMessage("Olá")},
+		ResponseSchema: &llm.JSONSchema{
+			Name:   "message_list",
+			Schema: map[string]any{"type": "object"},
+			Strict: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	for stream.Next() {
+		content.WriteString(stream.Current().Content)
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("Stream error: %v", err)
+	}
+
+	if content.String() != `{"messages":[{"content":"Oi","type":"text"}]}` {
+		t.Errorf("Unexpected streamed content: %q", content.String())
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0], `"json_schema"`) {
+		t.Errorf("Expected request with json_schema response format, got %v", requests)
+	}
+}
+
+func TestOpenAIProvider_CompleteWithToolCalls(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	server.Enqueue(llmtest.OpenAICompletion("", [2]string{"sleep", `{"seconds":7}`}))
+
+	provider := llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{})
+	resp, err := provider.Complete(context.Background(), llm.Request{
+		Model:      "test-model",
+		Messages:   []llm.Message{llm.UserMessage("Oi")},
+		Tools:      []llm.ToolDefinition{{Name: "sleep", Parameters: map[string]any{"type": "object"}}},
+		ToolChoice: "sleep",
+	})
+	if err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "sleep" || resp.ToolCalls[0].Arguments != `{"seconds":7}` {
+		t.Errorf("Unexpected tool calls: %+v", resp.ToolCalls)
+	}
+	if resp.Usage.TotalTokens != 15 {
+		t.Errorf("Expected 15 total tokens, got %d", resp.Usage.TotalTokens)
+	}
+}
+
+func TestOpenAIProvider_APIError(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	server.Enqueue(llmtest.ErrorResponse(http.StatusBadRequest, "invalid model"))
+
+	provider := llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{})
+	_, err := provider.Complete(context.Background(), llm.Request{
+		Model:    "test-model",
+		Messages: []llm.Message{llm.UserMessage("Oi")},
+	})
+
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected APIError with status 400, got %v", err)
+	}
+}
+
+func TestOpenAIProvider_TruncatedStreamIsRetryable(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	truncated := llmtest.OpenAIStream(`{"messages":[`, `{"content":"Oi","type":"text"}`)
+	truncated.Events = truncated.Events[:2]
+	truncated.Truncate = true
+	server.Enqueue(truncated)
+
+	provider := llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{})
+	stream, err := provider.Stream(context.Background(), llm.Request{
+		Model:    "test-model",
+		Messages: []llm.Message{llm.UserMessage("Oi")},
+	})
+	if err != nil {
+		t.Fatalf("Stream returned error: %v", err)
+	}
+	defer stream.Close()
+
+	for stream.Next() {
+	}
+	if !llm.IsRetryable(stream.Err()) {
+		t.Errorf("Expected a broken stream to be retryable, got %v", stream.Err())
+	}
+}
@@ -0,0 +1,140 @@
+// Package llm defines a provider-neutral interface for chat completion backends.
+//
+// The chatbot talks to language models only through Provider, so the same
+// conversation flow (sleep analysis, tool calls and structured streaming) runs on:
+//   - OpenAI, through the official SDK
+//   - any OpenAI-compatible server (vLLM, Ollama, llama.cpp server) via a custom base URL
+//   - Anthropic Messages API
+//
+// Basic usage:
+//
+//	provider := llm.NewOpenAIProvider(apiKey, http.Client{})
+//
+//	resp, err := provider.Complete(ctx, llm.Request{
+//		Model:    "gpt-4.1-mini",
+//		Messages: []llm.Message{llm.UserMessage("Olá!")},
+//	})
+package llm
+
+import (
+	"context"
+)
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// Message is a single chat message sent to the model.
+// Assistant messages may carry tool calls, and tool messages reference the call they answer.
+type Message struct {
+	Role       string
+	Content    string
+	ToolCalls  []ToolCall
+	ToolCallID string
+}
+
+// ToolCall is a function call requested by the model.
+// Arguments holds the raw JSON object generated by the model.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// ToolDefinition describes a function the model can call.
+// Parameters is a JSON schema object describing the arguments.
+type ToolDefinition struct {
+	Name        string
+	Description string
+	Parameters  map[string]any
+}
+
+// JSONSchema constrains the model output to a JSON document matching Schema.
+type JSONSchema struct {
+	Name        string
+	Description string
+	Schema      any
+	Strict      bool
+}
+
+// Request is a chat completion request.
+type Request struct {
+	Model    string
+	Messages []Message
+	Tools    []ToolDefinition
+	// ToolChoice is the name of a tool the model must call; empty lets the model decide.
+	ToolChoice string
+	// ResponseSchema, when set, forces a structured JSON response.
+	ResponseSchema *JSONSchema
+}
+
+// Usage reports the tokens consumed by a request.
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalTokens      int64
+}
+
+// Response is the result of a non-streaming completion.
+type Response struct {
+	Model     string
+	Content   string
+	ToolCalls []ToolCall
+	Usage     Usage
+}
+
+// StreamChunk is a piece of a streamed response.
+// Usage is only set on the chunk that reports token usage, when the provider sends one.
+type StreamChunk struct {
+	Content string
+	Usage   *Usage
+}
+
+// Stream iterates over the chunks of a streamed response.
+// It follows the iterator style of the OpenAI SDK: call Next until it returns
+// false, read each chunk with Current, then check Err.
+type Stream interface {
+	Next() bool
+	Current() StreamChunk
+	Err() error
+	Close() error
+}
+
+// Provider is a chat completion backend.
+type Provider interface {
+	// Name identifies the provider in logs, e.g. "openai" or "anthropic".
+	Name() string
+	// Complete runs a request and returns the whole response.
+	Complete(ctx context.Context, req Request) (*Response, error)
+	// Stream runs a request and returns its content as it is generated.
+	Stream(ctx context.Context, req Request) (Stream, error)
+}
+
+// SystemMessage creates a system message.
+func SystemMessage(content string) Message {
+	return Message{Role: RoleSystem, Content: content}
+}
+
+// UserMessage creates a user message.
+func UserMessage(content string) Message {
+	return Message{Role: RoleUser, Content: content}
+}
+
+// AssistantMessage creates an assistant message.
+func AssistantMessage(content string) Message {
+	return Message{Role: RoleAssistant, Content: content}
+}
+
+// AssistantToolCallMessage creates an assistant message requesting the given tool calls.
+func AssistantToolCallMessage(content string, toolCalls ...ToolCall) Message {
+	return Message{Role: RoleAssistant, Content: content, ToolCalls: toolCalls}
+}
+
+// ToolMessage creates the result message of the tool call with the given ID.
+func ToolMessage(content, toolCallID string) Message {
+	return Message{Role: RoleTool, Content: content, ToolCallID: toolCallID}
+}
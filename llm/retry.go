@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried against the same model
+// before falling back to the next one.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per model, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry; it doubles on every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured:
+// three attempts per model waiting 500ms, 1s, ... up to 8s between them.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     8 * time.Second,
+	}
+}
+
+// WithDefaults fills the zero fields of p from DefaultRetryPolicy.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaults.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaults.MaxBackoff
+	}
+	return p
+}
+
+// Backoff returns how long to wait before the given retry, starting at 1.
+// The exponential delay is jittered by up to 20% so concurrent conversations
+// hitting the same rate limit do not retry in lockstep.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(backoff)/5 + 1))
+	return backoff - jitter
+}
+
+// Wait sleeps for the backoff of the given retry, returning early with the
+// context error if ctx is cancelled.
+func (p RetryPolicy) Wait(ctx context.Context, retry int) error {
+	timer := time.NewTimer(p.Backoff(retry))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRetryable reports whether a failed request may succeed if sent again:
+// rate limits, server errors, overloaded providers, network failures and
+// streams that broke mid-way. Cancelled requests are never retried.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusRequestTimeout,
+			apiErr.StatusCode == http.StatusConflict,
+			apiErr.StatusCode == http.StatusTooManyRequests,
+			apiErr.StatusCode >= 500:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
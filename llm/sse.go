@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseEvent is a single Server-Sent Event.
+type sseEvent struct {
+	Event string
+	Data  string
+}
+
+// sseReader decodes a Server-Sent Events stream.
+type sseReader struct {
+	scanner *bufio.Scanner
+}
+
+func newSSEReader(r io.Reader) *sseReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &sseReader{scanner: scanner}
+}
+
+// Next returns the next event, or io.EOF once the stream ends.
+func (r *sseReader) Next() (sseEvent, error) {
+	var event sseEvent
+	var data []string
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		if line == "" {
+			if event.Event == "" && len(data) == 0 {
+				continue
+			}
+			event.Data = strings.Join(data, "\n")
+			return event, nil
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return sseEvent{}, err
+	}
+
+	if event.Event != "" || len(data) > 0 {
+		event.Data = strings.Join(data, "\n")
+		return event, nil
+	}
+
+	return sseEvent{}, io.EOF
+}
@@ -0,0 +1,13 @@
+package llm
+
+import "unicode/utf8"
+
+// TokenCounter returns the number of tokens a text takes in the model's context.
+type TokenCounter func(text string) int
+
+// EstimateTokens approximates the token count of text without a tokenizer,
+// assuming about four characters per token, which holds well for English and
+// Portuguese with the GPT and Claude tokenizers.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
@@ -0,0 +1,148 @@
+// Package metrics defines the Prometheus metrics of the message pipeline.
+//
+// The collectors are registered in the default Prometheus registry, so an
+// application embedding the chatbot can expose them next to its own metrics.
+// The chatbot server serves them at /metrics through Handler.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "chatbot"
+
+// Outcome labels
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// latencyBuckets cover calls from a few milliseconds to a slow LLM response.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60, 120}
+
+var (
+	// WebhooksReceived counts the Vonage webhooks received, by webhook and message type or status.
+	WebhooksReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhooks_received_total",
+		Help:      "Vonage webhooks received, by webhook and message type or delivery status.",
+	}, []string{"webhook", "type"})
+
+	// DuplicateMessagesDropped counts streamed messages skipped because an earlier attempt
+	// of the same response already sent them.
+	DuplicateMessagesDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "duplicate_messages_dropped_total",
+		Help:      "Streamed messages dropped on retry because an earlier attempt already sent them.",
+	})
+
+	// ExecutionsCancelled counts the replies cancelled because a newer message from the
+	// same user arrived or the conversation was paused.
+	ExecutionsCancelled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "executions_cancelled_total",
+		Help:      "Message processing executions cancelled by a newer execution for the same user.",
+	})
+
+	// SleepSeconds observes the wait chosen by the sleep analyzer before replying.
+	SleepSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sleep_seconds",
+		Help:      "Seconds the sleep analyzer chose to wait before replying.",
+		Buckets:   prometheus.LinearBuckets(5, 2.5, 7),
+	})
+
+	// LLMRequestDuration observes each LLM request attempt, streamed or not.
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Duration of LLM requests, by provider, model, mode (complete or stream) and outcome.",
+		Buckets:   latencyBuckets,
+	}, []string{"provider", "model", "mode", "outcome"})
+
+	// LLMTimeToFirstMessage observes the time from the start of a streamed response to
+	// its first message parsed and queued for sending.
+	LLMTimeToFirstMessage = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_time_to_first_message_seconds",
+		Help:      "Time from the start of a streamed response to its first parsed message.",
+		Buckets:   latencyBuckets,
+	}, []string{"provider", "model"})
+
+	// LLMTokens counts the tokens reported by the providers, by kind (prompt or completion).
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Tokens used by LLM requests, by provider, model and kind (prompt or completion).",
+	}, []string{"provider", "model", "kind"})
+
+	// ModelRoutes counts the turns answered by each model, by the reason it was chosen.
+	ModelRoutes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "model_routes_total",
+		Help:      "Turns routed to each model, by reason (default, prompt or the matching rule).",
+	}, []string{"model", "reason"})
+
+	// ToolCallDuration observes tool handler calls.
+	ToolCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls, by tool.",
+		Buckets:   latencyBuckets,
+	}, []string{"tool"})
+
+	// ToolCallErrors counts tool calls that returned an error, including recovered panics.
+	ToolCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_call_errors_total",
+		Help:      "Tool calls that returned an error, by tool.",
+	}, []string{"tool"})
+
+	// VonageSendErrors counts messages Vonage did not accept, by message type and HTTP
+	// status code; "transport" is used when no response was received.
+	VonageSendErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "vonage_send_errors_total",
+		Help:      "WhatsApp messages that failed to send through Vonage, by message type and status code.",
+	}, []string{"type", "status"})
+
+	// ElevenLabsRequestDuration observes the speech-to-text and text-to-speech API calls.
+	ElevenLabsRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "elevenlabs_request_duration_seconds",
+		Help:      "Duration of ElevenLabs API calls, by operation (stt or tts) and outcome.",
+		Buckets:   latencyBuckets,
+	}, []string{"operation", "outcome"})
+)
+
+// Outcome returns the outcome label of a call that returned err.
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+// Since returns the seconds elapsed since start, for observing durations.
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// StatusLabel returns the status label of an HTTP status code, or "transport" for 0.
+func StatusLabel(statusCode int) string {
+	if statusCode == 0 {
+		return "transport"
+	}
+	return strconv.Itoa(statusCode)
+}
+
+// Handler serves the metrics of the default registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
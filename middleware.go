@@ -0,0 +1,47 @@
+package chatbot
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/openai"
+)
+
+// ToolMiddleware wraps a ToolHandler with additional behaviour (using the openai package type)
+type ToolMiddleware = openai.ToolMiddleware
+
+// ToolCallInfo describes the tool call being executed (using the openai package type)
+type ToolCallInfo = openai.ToolCallInfo
+
+// Redactor masks sensitive data in tool arguments and results (using the openai package type)
+type Redactor = openai.Redactor
+
+// DefaultRedactor returns a Redactor for CPF numbers, card numbers and e-mail addresses
+func DefaultRedactor() *Redactor {
+	return openai.DefaultRedactor()
+}
+
+// LoggingMiddleware writes a structured audit log entry for every tool call
+func LoggingMiddleware(redactor *Redactor) ToolMiddleware {
+	return openai.LoggingToolMiddleware(redactor)
+}
+
+// RedactionMiddleware masks sensitive data in tool results before they reach the model, and in
+// the arguments and results logged by the LoggingMiddleware placed after it
+func RedactionMiddleware(redactor *Redactor) ToolMiddleware {
+	return openai.RedactionToolMiddleware(redactor)
+}
+
+// CacheMiddleware caches tool results per user and arguments, typically in a *redis.Client
+func CacheMiddleware(cache openai.ToolResultCache, ttl time.Duration) ToolMiddleware {
+	return openai.CacheToolMiddleware(cache, ttl)
+}
+
+// RateLimitMiddleware limits how many times each user can call a tool per window
+func RateLimitMiddleware(limiter openai.ToolRateLimiter, limit int, window time.Duration) ToolMiddleware {
+	return openai.RateLimitToolMiddleware(limiter, limit, window)
+}
+
+// RecoveryMiddleware turns panics in tool handlers into errors; it is always applied by New
+func RecoveryMiddleware() ToolMiddleware {
+	return openai.RecoveryToolMiddleware()
+}
@@ -0,0 +1,137 @@
+package openai
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/vonage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// asyncToolInstructions tells the model how to handle the immediate answer of an async tool.
+const asyncToolInstructions = "Esta tarefa está sendo executada em segundo plano. " +
+	"Avise o usuário que você está trabalhando nisso e que enviará o resultado assim que estiver pronto. " +
+	"Não invente o resultado."
+
+// findTool returns the registered tool with the given function name.
+func (c *Client) findTool(name string) (Tool, bool) {
+	for _, tool := range c.tools {
+		if tool.Definition.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
+
+// ToolHandler returns the handler of the registered tool with the given function name.
+func (c *Client) ToolHandler(name string) (ToolHandler, bool) {
+	tool, ok := c.findTool(name)
+	if !ok || tool.Handler == nil {
+		return nil, false
+	}
+	return tool.Handler, true
+}
+
+// enqueueAsyncToolCall stores an async tool call as a job in Redis and returns
+// the tool result the model receives right away.
+func (c *Client) enqueueAsyncToolCall(config streamingConfig, toolCallID, toolName, arguments string) string {
+	job := redis.ToolJob{
+		ID:         newJobID(),
+		UserID:     config.userID,
+		UserName:   config.userName,
+		ToolName:   toolName,
+		ToolCallID: toolCallID,
+		Arguments:  arguments,
+	}
+
+	if err := config.redisClient.EnqueueToolJob(job); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", config.userID).
+			Str("tool_name", toolName).
+			Msg("Failed to enqueue async tool job")
+		return fmt.Sprintf("Error: failed to start background task: %s", err.Error())
+	}
+
+	log.Info().
+		Str("user_id", config.userID).
+		Str("tool_name", toolName).
+		Str("job_id", job.ID).
+		Msg("Async tool job enqueued")
+
+	result, _ := json.Marshal(map[string]string{
+		"job_id":       job.ID,
+		"status":       redis.ToolJobPending,
+		"instructions": asyncToolInstructions,
+	})
+	return string(result)
+}
+
+// ProcessToolJobResult starts a new generation turn for the job's user with the
+// finished job injected as the tool call and its tool message.
+func (c *Client) ProcessToolJobResult(
+	ctx context.Context,
+	job redis.ToolJob,
+	chatHistory []redis.ChatMessage,
+	vonageClient *vonage.Client,
+	redisClient *redis.Client,
+	elevenLabsClient *elevenlabs.Client,
+	toNumber string,
+) error {
+	config := streamingConfig{
+		userID:           job.UserID,
+		userName:         job.UserName,
+		chatHistory:      chatHistory,
+		vonageClient:     vonageClient,
+		redisClient:      redisClient,
+		elevenLabsClient: elevenLabsClient,
+		toNumber:         toNumber,
+	}
+
+	result := job.Result
+	if job.Status == redis.ToolJobFailed {
+		result = fmt.Sprintf("Error: %s", job.Error)
+	}
+
+	messages, promptModel := c.conversationMessages(config)
+	ctx = c.routeTurn(ctx, config, promptModel)
+	messages = append(messages,
+		llm.AssistantToolCallMessage("", llm.ToolCall{
+			ID:        job.ToolCallID,
+			Name:      job.ToolName,
+			Arguments: job.Arguments,
+		}),
+		llm.ToolMessage(result, job.ToolCallID),
+	)
+
+	log.Info().
+		Str("user_id", job.UserID).
+		Str("tool_name", job.ToolName).
+		Str("job_id", job.ID).
+		Msg("Generating follow-up response for async tool job")
+
+	if len(c.tools) > 0 {
+		finalMessages, err := c.handleToolCalls(ctx, messages, config)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("user_id", job.UserID).
+				Msg("Error handling tool calls, continuing with original messages")
+		} else {
+			messages = finalMessages
+		}
+	}
+
+	return c.streamResponseWithoutTools(ctx, config, messages)
+}
+
+// newJobID returns a random identifier for an async tool job.
+func newJobID() string {
+	return rand.Text()
+}
@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+)
+
+// AttributeStore persists custom attributes about users, also edited by agents in the CRM.
+// It is implemented by *redis.Client.
+type AttributeStore interface {
+	GetConversationAttributes(userID string) (map[string]string, error)
+	SetConversationAttributes(userID string, attributes map[string]string) error
+}
+
+// AttributeTools returns the built-in tools that let the model read and set the user's
+// attributes. The readOnly attributes, such as a plan set by agents, can be read but not set.
+func AttributeTools(store AttributeStore, readOnly []string) []Tool {
+	return []Tool{
+		{
+			Definition: llm.ToolDefinition{
+				Name:        "get_user_attributes",
+				Description: "Retorna os atributos cadastrados do usuário, como plano, cidade ou número de cliente. Use quando precisar de um dado do cadastro do usuário.",
+				Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (string, error) {
+				userID, err := attributeToolUser(ctx)
+				if err != nil {
+					return "", err
+				}
+				attributes, err := store.GetConversationAttributes(userID)
+				if err != nil {
+					return "", err
+				}
+				if len(attributes) == 0 {
+					return "Nenhum atributo cadastrado para este usuário.", nil
+				}
+				attributesJSON, err := json.Marshal(attributes)
+				if err != nil {
+					return "", err
+				}
+				return string(attributesJSON), nil
+			},
+		},
+		{
+			Definition: llm.ToolDefinition{
+				Name:        "set_user_attribute",
+				Description: "Cadastra ou atualiza um atributo do usuário, como cidade, e-mail ou produto de interesse. Use quando o usuário informar um dado que deve ficar no cadastro.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"key": map[string]any{
+							"type":        "string",
+							"description": "Nome do atributo em snake_case. Ex: 'cidade', 'email'",
+						},
+						"value": map[string]any{
+							"type":        "string",
+							"description": "Valor do atributo",
+						},
+					},
+					"required": []string{"key", "value"},
+				},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (string, error) {
+				userID, err := attributeToolUser(ctx)
+				if err != nil {
+					return "", err
+				}
+				key, _ := args["key"].(string)
+				value, _ := args["value"].(string)
+				key = strings.TrimSpace(key)
+				if key == "" {
+					return "", fmt.Errorf("key is required")
+				}
+				if slices.Contains(readOnly, key) {
+					return "", fmt.Errorf("attribute %s is read-only", key)
+				}
+				if err := store.SetConversationAttributes(userID, map[string]string{key: value}); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Atributo %s salvo.", key), nil
+			},
+		},
+	}
+}
+
+// attributeToolUser returns the user the attribute tool is being called for.
+func attributeToolUser(ctx context.Context) (string, error) {
+	info, ok := ToolCallInfoFromContext(ctx)
+	if !ok || info.UserID == "" {
+		return "", fmt.Errorf("attribute tools require the calling user")
+	}
+	return info.UserID, nil
+}
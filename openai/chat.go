@@ -4,29 +4,24 @@ import (
 	"context"
 	"time"
 
+	"github.com/NextMind-AI/chatbot-go/llm"
 	"github.com/NextMind-AI/chatbot-go/redis"
-
-	"github.com/openai/openai-go"
 )
 
-// ProcessChat processes a chat conversation without tools using OpenAI's API.
+// ProcessChat processes a chat conversation without tools using the configured provider.
 // It takes a context and a slice of messages and returns the AI's response.
 func (c *Client) ProcessChat(
 	ctx context.Context,
-	messages []openai.ChatCompletionMessageParamUnion,
+	messages []llm.Message,
 ) (string, error) {
-	chatCompletion, err := c.client.Chat.Completions.New(
-		ctx,
-		openai.ChatCompletionNewParams{
-			Messages: messages,
-			Model:    openai.ChatModelGPT4_1Mini,
-		},
-	)
+	completion, err := c.complete(ctx, "", llm.Request{
+		Messages: messages,
+	})
 	if err != nil {
 		return "", err
 	}
 
-	return chatCompletion.Choices[0].Message.Content, nil
+	return completion.Content, nil
 }
 
 // ProcessChatWithTools processes a chat conversation with the new two-step approach:
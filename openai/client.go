@@ -2,41 +2,106 @@ package openai
 
 import (
 	"context"
-	"net/http"
+	"strings"
 
-	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/store"
 )
 
+// DefaultModel is the model used when none is configured.
+const DefaultModel = "gpt-4.1-mini"
+
 // ToolHandler represents a function that handles a tool call and returns the result
 type ToolHandler func(ctx context.Context, args map[string]any) (string, error)
 
-// Tool represents a custom tool that can be called by the AI
+// Tool represents a custom tool that can be called by the AI.
+// When Async is true the handler runs in a background worker and the result
+// is delivered to the user in a follow-up turn.
 type Tool struct {
-	Definition openai.ChatCompletionToolParam
+	Definition llm.ToolDefinition
 	Handler    ToolHandler
+	Async      bool
 }
 
 // PromptGenerator is a function that generates the system prompt based on user context
 type PromptGenerator func(userName, userPhone string) string
 
-// Client wraps the OpenAI client with additional functionality for chat processing.
+// PromptContext is everything known about the user when the system prompt is generated.
+type PromptContext struct {
+	UserName  string
+	UserPhone string
+	// Memories are long-term facts about the user, oldest first
+	Memories []string
+	// Variant is the user's experiment variant, empty without an experiment
+	Variant string
+	// model is set through UseModel and shared by every copy of the context
+	model *string
+}
+
+// UseModel makes the turn being prompted use model, overriding the model router.
+// It is meant to be called from a ContextPromptGenerator.
+func (p PromptContext) UseModel(model string) {
+	if p.model != nil {
+		*p.model = model
+	}
+}
+
+// chosenModel returns the model set through UseModel, if any.
+func (p PromptContext) chosenModel() string {
+	if p.model == nil {
+		return ""
+	}
+	return *p.model
+}
+
+// ContextPromptGenerator generates the system prompt from the full prompt context.
+// Unlike PromptGenerator, it decides itself where the user's memories go.
+type ContextPromptGenerator func(prompt PromptContext) string
+
+// MemoriesSection formats the memories to be appended to a system prompt.
+// It returns an empty string when there are no memories.
+func (p PromptContext) MemoriesSection() string {
+	if len(p.Memories) == 0 {
+		return ""
+	}
+	var section strings.Builder
+	section.WriteString("\n\n**O QUE VOCÊ JÁ SABE SOBRE ESTE USUÁRIO (de conversas anteriores):**\n")
+	for _, memory := range p.Memories {
+		section.WriteString("- " + memory + "\n")
+	}
+	return section.String()
+}
+
+// Client drives the conversation flow on top of an LLM provider.
 // It provides methods for both simple chat completion and tool-enabled conversations.
 type Client struct {
-	client          *openai.Client
+	provider        llm.Provider
 	promptGenerator PromptGenerator
-	tools           []Tool
-	model           string
+	// contextPromptGenerator, when set, replaces promptGenerator
+	contextPromptGenerator ContextPromptGenerator
+	memoryStore            MemoryStore
+	conversationStore      store.ConversationStore
+	knowledgeRetriever     KnowledgeRetriever
+	tools                  []Tool
+	model                  string
+	retryPolicy            llm.RetryPolicy
+	fallbacks              []ModelTarget
+	historyPolicy          HistoryPolicy
+	usageRecorder          UsageRecorder
+	// router picks the model of each turn; nil uses model
+	router           *ModelRouter
+	routerAttributes AttributeReader
+	// toolStates switches tools off at runtime; nil offers every tool
+	toolStates ToolStateReader
+	// experiment assigns users to prompt and model variants; nil answers everyone alike
+	experiment *Experiment
+	// historySummaries keeps the rolling summaries next to the conversations; nil sends the full history
+	historySummaries store.HistorySummaryStore
 }
 
-// NewClient creates a new OpenAI client wrapper with the specified API key and HTTP client.
-// The HTTP client allows for custom configuration such as timeouts and proxy settings.
-func NewClient(apiKey string, httpClient http.Client, promptGenerator PromptGenerator, tools []Tool, model string) Client {
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey),
-		option.WithHTTPClient(&httpClient),
-	)
-
+// NewClient creates a new client that sends its requests through the given provider,
+// such as llm.NewOpenAIProvider or llm.NewAnthropicProvider.
+func NewClient(provider llm.Provider, promptGenerator PromptGenerator, tools []Tool, model string) Client {
 	// Use default prompt generator if none provided
 	if promptGenerator == nil {
 		promptGenerator = func(userName, userPhone string) string {
@@ -54,15 +119,42 @@ func NewClient(apiKey string, httpClient http.Client, promptGenerator PromptGene
 
 	// Use default model if none provided
 	if model == "" {
-		model = openai.ChatModelGPT4_1Mini
+		model = DefaultModel
 	}
 
 	openaiClient := Client{
-		client:          &client,
+		provider:        provider,
 		promptGenerator: promptGenerator,
 		tools:           tools,
 		model:           model,
+		retryPolicy:     llm.DefaultRetryPolicy(),
+		historyPolicy:   HistoryPolicy{}.withDefaults(),
 	}
 
 	return openaiClient
 }
+
+// SetContextPromptGenerator replaces the prompt generator with one that receives the full prompt context.
+func (c *Client) SetContextPromptGenerator(generator ContextPromptGenerator) {
+	c.contextPromptGenerator = generator
+}
+
+// systemPrompt generates the system prompt. With a plain PromptGenerator the user's
+// memories are appended to the generated prompt. Users in an experiment get the prompt
+// and model of their variant.
+func (c *Client) systemPrompt(prompt PromptContext) string {
+	if c.experiment != nil {
+		variant := c.experiment.Assign(prompt.UserPhone)
+		prompt.Variant = variant.Name
+		if variant.Model != "" {
+			prompt.UseModel(variant.Model)
+		}
+		if variant.Prompt != nil {
+			return variant.Prompt(prompt)
+		}
+	}
+	if c.contextPromptGenerator != nil {
+		return c.contextPromptGenerator(prompt)
+	}
+	return c.promptGenerator(prompt.UserName, prompt.UserPhone) + prompt.MemoriesSection()
+}
@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+)
+
+// Escalator hands a conversation over to a human agent.
+type Escalator interface {
+	EscalateToHuman(userID, reason, priority string) error
+}
+
+// EscalationTool returns the built-in escalate_to_human tool, which lets the model pause
+// the bot and queue the conversation for a human agent.
+func EscalationTool(escalator Escalator) Tool {
+	return Tool{
+		Definition: llm.ToolDefinition{
+			Name:        "escalate_to_human",
+			Description: "Transfere a conversa para um atendente humano. Use quando o usuário pedir para falar com uma pessoa, demonstrar irritação ou frustração, fizer ameaças legais ou reclamações formais, ou quando você não conseguir resolver o problema após algumas tentativas. Depois de chamar esta ferramenta, não envie mais mensagens.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"reason": map[string]any{
+						"type":        "string",
+						"description": "Motivo da transferência em uma frase, para o atendente. Ex: 'Cliente irritado com atraso na entrega do pedido 1234'",
+					},
+					"priority": map[string]any{
+						"type":        "string",
+						"enum":        []string{"low", "normal", "high", "urgent"},
+						"description": "Urgência: 'urgent' para ameaças legais ou riscos, 'high' para clientes irritados, 'normal' nos demais casos",
+					},
+				},
+				"required": []string{"reason", "priority"},
+			},
+		},
+		Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			info, ok := ToolCallInfoFromContext(ctx)
+			if !ok || info.UserID == "" {
+				return "", fmt.Errorf("escalate_to_human requires the calling user")
+			}
+			reason, _ := args["reason"].(string)
+			if strings.TrimSpace(reason) == "" {
+				return "", fmt.Errorf("reason is required")
+			}
+			priority, _ := args["priority"].(string)
+
+			if err := escalator.EscalateToHuman(info.UserID, reason, priority); err != nil {
+				return "", err
+			}
+			return "Conversa transferida para um atendente humano. Não envie mais mensagens.", nil
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Experiment splits users between prompt and model variants to compare them on real
+// traffic. Each user is assigned a variant by hashing the experiment name with the user's
+// ID, so they keep the same variant for as long as the variants and weights don't change.
+type Experiment struct {
+	Name     string
+	Variants []Variant
+}
+
+// Variant is an arm of an experiment. Fields left at their zero value keep the client's
+// prompt generator and model.
+type Variant struct {
+	Name   string                 // Recorded on the stored messages and reported in the analytics
+	Weight int                    // Share of the users relative to the other variants
+	Model  string                 // Model of the variant's turns; the prompt generator may still override it
+	Prompt ContextPromptGenerator // Replaces the client's prompt generator for the variant's users
+}
+
+// Validate checks that the experiment has a name and that its variants have distinct names
+// and a positive total weight. Names may not contain colons, which separate analytics fields.
+func (e Experiment) Validate() error {
+	if e.Name == "" {
+		return errors.New("experiment name is required")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %q has no variants", e.Name)
+	}
+	names := make(map[string]bool, len(e.Variants))
+	total := 0
+	for _, variant := range e.Variants {
+		if variant.Name == "" || strings.Contains(variant.Name, ":") {
+			return fmt.Errorf("experiment %q: invalid variant name %q", e.Name, variant.Name)
+		}
+		if names[variant.Name] {
+			return fmt.Errorf("experiment %q: duplicate variant %q", e.Name, variant.Name)
+		}
+		names[variant.Name] = true
+		if variant.Weight < 0 {
+			return fmt.Errorf("experiment %q: variant %q has a negative weight", e.Name, variant.Name)
+		}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return fmt.Errorf("experiment %q: the variant weights add up to zero", e.Name)
+	}
+	return nil
+}
+
+// Assign returns the variant of the user.
+func (e Experiment) Assign(userID string) Variant {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(e.Name + ":" + userID))
+	point := int(hash.Sum64() % uint64(total))
+	for _, variant := range e.Variants {
+		if point < variant.Weight {
+			return variant
+		}
+		point -= variant.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+// SetExperiment makes the client answer each user with the prompt and model of their variant.
+func (c *Client) SetExperiment(experiment Experiment) error {
+	if err := experiment.Validate(); err != nil {
+		return err
+	}
+	c.experiment = &experiment
+	return nil
+}
+
+// Variant returns the name of the user's variant, or an empty string without an experiment.
+func (c *Client) Variant(userID string) string {
+	if c.experiment == nil {
+		return ""
+	}
+	return c.experiment.Assign(userID).Name
+}
@@ -0,0 +1,71 @@
+package openai
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExperiment_Assign(t *testing.T) {
+	experiment := Experiment{Name: "prompt-v2", Variants: []Variant{
+		{Name: "control", Weight: 3},
+		{Name: "short", Weight: 1},
+		{Name: "off", Weight: 0},
+	}}
+
+	counts := map[string]int{}
+	for i := range 4000 {
+		userID := fmt.Sprintf("55119%08d", i)
+		variant := experiment.Assign(userID)
+		if again := experiment.Assign(userID); again.Name != variant.Name {
+			t.Fatalf("user %s assigned to %s, then %s", userID, variant.Name, again.Name)
+		}
+		counts[variant.Name]++
+	}
+
+	if counts["off"] != 0 {
+		t.Errorf("%d users assigned to a variant without weight", counts["off"])
+	}
+	if share := float64(counts["short"]) / 4000; share < 0.22 || share > 0.28 {
+		t.Errorf("short share = %.3f, want about 0.25", share)
+	}
+}
+
+func TestExperiment_Validate(t *testing.T) {
+	invalid := []Experiment{
+		{Variants: []Variant{{Name: "a", Weight: 1}}},
+		{Name: "empty"},
+		{Name: "colon", Variants: []Variant{{Name: "a:b", Weight: 1}}},
+		{Name: "duplicate", Variants: []Variant{{Name: "a", Weight: 1}, {Name: "a", Weight: 1}}},
+		{Name: "negative", Variants: []Variant{{Name: "a", Weight: 2}, {Name: "b", Weight: -1}}},
+		{Name: "zero", Variants: []Variant{{Name: "a"}}},
+	}
+	for _, experiment := range invalid {
+		if err := experiment.Validate(); err == nil {
+			t.Errorf("experiment %+v accepted", experiment)
+		}
+	}
+}
+
+func TestSystemPrompt_Variant(t *testing.T) {
+	client := NewClient(nil, func(userName, userPhone string) string { return "default" }, nil, "default-model")
+	err := client.SetExperiment(Experiment{Name: "prompt-v2", Variants: []Variant{{
+		Name:   "short",
+		Weight: 1,
+		Model:  "variant-model",
+		Prompt: func(prompt PromptContext) string { return "variant " + prompt.Variant },
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prompt := client.promptContext("5511999999999", "Ana")
+	if got := client.systemPrompt(prompt); got != "variant short" {
+		t.Errorf("systemPrompt = %q, want the variant's prompt", got)
+	}
+	if got := prompt.chosenModel(); got != "variant-model" {
+		t.Errorf("chosen model = %q, want the variant's model", got)
+	}
+	if got := client.Variant("5511999999999"); got != "short" {
+		t.Errorf("Variant = %q, want short", got)
+	}
+}
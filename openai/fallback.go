@@ -0,0 +1,126 @@
+package openai
+
+import (
+	"context"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ModelTarget is a model that can answer a request, tried in order after the primary one
+// when it keeps failing. A nil Provider uses the client's provider.
+type ModelTarget struct {
+	Provider llm.Provider
+	Model    string
+}
+
+// SetRetryPolicy configures how failed requests are retried and which models are tried
+// next once the primary model exhausts its attempts.
+func (c *Client) SetRetryPolicy(policy llm.RetryPolicy, fallbacks []ModelTarget) {
+	c.retryPolicy = policy.WithDefaults()
+	c.fallbacks = fallbacks
+}
+
+// targets returns the primary model, or the one routed in ctx, followed by the configured fallbacks.
+func (c *Client) targets(ctx context.Context) []ModelTarget {
+	model := routedModel(ctx)
+	if model == "" {
+		model = c.model
+	}
+	targets := []ModelTarget{{Provider: c.provider, Model: model}}
+	for _, fallback := range c.fallbacks {
+		if fallback.Provider == nil {
+			fallback.Provider = c.provider
+		}
+		targets = append(targets, fallback)
+	}
+	return targets
+}
+
+// withFallback runs attempt against each target in order until one succeeds.
+// Retryable errors are retried on the same target with exponential backoff;
+// any other error moves on to the next target. It returns the target that answered.
+func (c *Client) withFallback(
+	ctx context.Context,
+	userID string,
+	attempt func(target ModelTarget) error,
+) (ModelTarget, error) {
+	var lastErr error
+
+	for i, target := range c.targets(ctx) {
+		if i > 0 {
+			log.Warn().
+				Str("user_id", userID).
+				Str("provider", target.Provider.Name()).
+				Str("model", target.Model).
+				Msg("Falling back to next model")
+		}
+
+		for try := 1; try <= c.retryPolicy.MaxAttempts; try++ {
+			err := attempt(target)
+			if err == nil {
+				if i > 0 || try > 1 {
+					log.Info().
+						Str("user_id", userID).
+						Str("provider", target.Provider.Name()).
+						Str("model", target.Model).
+						Int("attempt", try).
+						Msg("LLM request succeeded after retry")
+				}
+				return target, nil
+			}
+			if ctx.Err() != nil {
+				return target, ctx.Err()
+			}
+			lastErr = err
+
+			retryable := llm.IsRetryable(err)
+			log.Warn().
+				Err(err).
+				Str("user_id", userID).
+				Str("provider", target.Provider.Name()).
+				Str("model", target.Model).
+				Int("attempt", try).
+				Bool("retryable", retryable).
+				Msg("LLM request failed")
+
+			if !retryable || try == c.retryPolicy.MaxAttempts {
+				break
+			}
+			if err := c.retryPolicy.Wait(ctx, try); err != nil {
+				return target, err
+			}
+		}
+	}
+
+	return ModelTarget{}, lastErr
+}
+
+// complete runs a non-streaming request with retries and fallbacks.
+func (c *Client) complete(ctx context.Context, userID string, req llm.Request) (*llm.Response, error) {
+	var resp *llm.Response
+	_, err := c.withFallback(ctx, userID, func(target ModelTarget) error {
+		req.Model = target.Model
+		ctx, span := tracing.Start(ctx, "llm.complete",
+			attribute.String("llm.provider", target.Provider.Name()),
+			attribute.String("llm.model", target.Model),
+		)
+		start := time.Now()
+		var err error
+		resp, err = target.Provider.Complete(ctx, req)
+		observeLLMRequest(target.Provider, target.Model, "complete", start, err)
+		tracing.End(span, err)
+		if err == nil {
+			c.recordUsage(userID, target.Provider, target.Model, resp.Usage)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
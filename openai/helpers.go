@@ -1,25 +1,41 @@
 package openai
 
 import (
+	"github.com/NextMind-AI/chatbot-go/llm"
 	"github.com/NextMind-AI/chatbot-go/redis"
-
-	"github.com/openai/openai-go"
 )
 
-// convertChatHistoryWithUserName converts Redis chat messages to OpenAI message format with personalized system prompt.
+// convertChatHistoryWithUserName converts Redis chat messages to LLM messages with personalized system prompt.
 // It includes the user's name and phone number in the system prompt to provide context to the AI.
-func (c *Client) convertChatHistoryWithUserName(chatHistory []redis.ChatMessage, userName string, userID string) []openai.ChatCompletionMessageParamUnion {
-	personalizedPrompt := c.promptGenerator(userName, userID)
-	messages := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage(personalizedPrompt),
+func (c *Client) convertChatHistoryWithUserName(chatHistory []redis.ChatMessage, userName string, userID string) []llm.Message {
+	return c.convertChatHistory(chatHistory, PromptContext{UserName: userName, UserPhone: userID})
+}
+
+// convertChatHistory converts Redis chat messages to LLM messages, starting with the system prompt
+// generated for the given context. Only the part of the history allowed by the history policy is included.
+func (c *Client) convertChatHistory(chatHistory []redis.ChatMessage, prompt PromptContext) []llm.Message {
+	messages := []llm.Message{
+		llm.SystemMessage(c.systemPrompt(prompt)),
 	}
-	for _, msg := range chatHistory {
+	for _, msg := range c.windowHistory(chatHistory) {
 		switch msg.Role {
 		case "user":
-			messages = append(messages, openai.UserMessage(msg.Content))
-		case "assistant":
-			messages = append(messages, openai.AssistantMessage(msg.Content))
+			messages = append(messages, llm.UserMessage(msg.Content))
+		case "assistant", "agent":
+			// Messages of a human agent who took over are replies on the bot's behalf
+			messages = append(messages, llm.AssistantMessage(msg.Content))
 		}
 	}
 	return messages
 }
+
+// toolDefinitions returns the definitions of the registered tools that are not disabled.
+func (c *Client) toolDefinitions(disabled map[string]bool) []llm.ToolDefinition {
+	definitions := make([]llm.ToolDefinition, 0, len(c.tools))
+	for _, tool := range c.tools {
+		if !disabled[tool.Definition.Name] {
+			definitions = append(definitions, tool.Definition)
+		}
+	}
+	return definitions
+}
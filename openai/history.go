@@ -0,0 +1,277 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HistoryMode selects how much of the stored conversation is sent to the model.
+type HistoryMode string
+
+const (
+	// HistoryFull sends the entire stored conversation.
+	HistoryFull HistoryMode = "full"
+	// HistoryLastTokens sends the most recent messages that fit in MaxTokens.
+	HistoryLastTokens HistoryMode = "tokens"
+	// HistoryLastTurns sends the last MaxTurns turns, a turn being a user
+	// message followed by the replies to it.
+	HistoryLastTurns HistoryMode = "turns"
+	// HistoryRollingSummary condenses older turns into a summary stored with the
+	// conversation and sends it as a system message followed by the recent messages.
+	// It needs a store.HistorySummaryStore; without one the full history is sent.
+	HistoryRollingSummary HistoryMode = "summary"
+)
+
+const (
+	defaultHistoryMaxTokens = 4000
+	defaultHistoryMaxTurns  = 10
+	// messageTokenOverhead accounts for the role and formatting tokens of each message.
+	messageTokenOverhead = 4
+	summaryTimeout       = 2 * time.Minute
+)
+
+// HistoryPolicy controls the conversation window sent to the model.
+type HistoryPolicy struct {
+	Mode HistoryMode
+	// MaxTokens is the history budget in HistoryLastTokens mode. In HistoryRollingSummary
+	// mode, older turns are summarized once the unsummarized messages exceed it.
+	MaxTokens int
+	// MaxTurns is the number of turns kept in HistoryLastTurns mode, and the number of
+	// recent turns kept verbatim when summarizing in HistoryRollingSummary mode.
+	MaxTurns int
+	// SummaryModel is the model used to write summaries; defaults to the client's model.
+	SummaryModel string
+	// CountTokens defaults to llm.EstimateTokens.
+	CountTokens llm.TokenCounter
+}
+
+// withDefaults fills the zero fields of the policy.
+func (p HistoryPolicy) withDefaults() HistoryPolicy {
+	if p.Mode == "" {
+		p.Mode = HistoryFull
+	}
+	if p.MaxTokens <= 0 {
+		p.MaxTokens = defaultHistoryMaxTokens
+	}
+	if p.MaxTurns <= 0 {
+		p.MaxTurns = defaultHistoryMaxTurns
+	}
+	if p.CountTokens == nil {
+		p.CountTokens = llm.EstimateTokens
+	}
+	return p
+}
+
+// SetHistoryPolicy configures the conversation window sent to the model.
+func (c *Client) SetHistoryPolicy(policy HistoryPolicy) {
+	c.historyPolicy = policy.withDefaults()
+}
+
+// SetConversationStore sets where the bot's replies are appended to the conversation.
+func (c *Client) SetConversationStore(conversationStore store.ConversationStore) {
+	c.conversationStore = conversationStore
+}
+
+// SetHistorySummaryStore sets where rolling summaries are kept. It should be the
+// conversation store itself, so clearing a conversation also clears its summary.
+func (c *Client) SetHistorySummaryStore(summaries store.HistorySummaryStore) {
+	c.historySummaries = summaries
+}
+
+// HistorySummary returns the rolling summary of the user's conversation, if any.
+func (c *Client) HistorySummary(userID string) (store.HistorySummary, bool, error) {
+	if c.historySummaries == nil {
+		return store.HistorySummary{}, false, nil
+	}
+	return c.historySummaries.GetHistorySummary(context.Background(), userID)
+}
+
+var summaryPrompt = `Você resume conversas entre um assistente e um usuário no WhatsApp.
+
+Escreva um resumo conciso, em português, que preserve tudo o que o assistente precisa para continuar a conversa: quem é o usuário, o que ele pediu, decisões tomadas, dados informados (datas, valores, nomes, pedidos) e pendências.
+
+Se houver um resumo anterior, incorpore as novas mensagens a ele em vez de repeti-lo. Responda somente com o resumo.`
+
+// conversationMessages builds the messages sent to the model for a conversation,
+// applying the history policy. In rolling-summary mode it prepends the stored summary
+// and starts a background summarization when the recent messages grow too long.
+// It also returns the model the prompt generator chose for the turn, if any.
+func (c *Client) conversationMessages(config streamingConfig) ([]llm.Message, string) {
+	prompt := c.promptContext(config.userID, config.userName)
+	if c.historyPolicy.Mode != HistoryRollingSummary || c.historySummaries == nil || config.redisClient == nil {
+		return c.convertChatHistory(config.chatHistory, prompt), prompt.chosenModel()
+	}
+
+	history := config.chatHistory
+	summary, found, err := c.historySummaries.GetHistorySummary(context.Background(), config.userID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", config.userID).
+			Msg("Error loading history summary, sending full history")
+		found = false
+	}
+	if found && !summaryMatches(summary, history) {
+		log.Warn().
+			Str("user_id", config.userID).
+			Int("summary_message_count", summary.MessageCount).
+			Int("history_length", len(history)).
+			Msg("History summary does not match stored history, ignoring it")
+		found = false
+	}
+	if !found {
+		summary = store.HistorySummary{}
+	}
+
+	recent := history[summary.MessageCount:]
+	if c.historyTokens(recent) > c.historyPolicy.MaxTokens {
+		go c.summarizeHistory(config.userID, history, summary, config.redisClient)
+	}
+
+	messages := c.convertChatHistory(recent, prompt)
+	if summary.Summary != "" {
+		summaryMessage := llm.SystemMessage("Resumo da conversa até aqui:\n" + summary.Summary)
+		messages = append(messages[:1], append([]llm.Message{summaryMessage}, messages[1:]...)...)
+	}
+	return messages, prompt.chosenModel()
+}
+
+// windowHistory returns the part of the history allowed by the policy.
+func (c *Client) windowHistory(history []redis.ChatMessage) []redis.ChatMessage {
+	switch c.historyPolicy.Mode {
+	case HistoryLastTokens:
+		return lastTokens(history, c.historyPolicy.MaxTokens, c.historyPolicy.CountTokens)
+	case HistoryLastTurns:
+		return history[turnStart(history, c.historyPolicy.MaxTurns):]
+	default:
+		return history
+	}
+}
+
+// historyTokens returns the number of tokens the messages take in the context.
+func (c *Client) historyTokens(history []redis.ChatMessage) int {
+	total := 0
+	for _, msg := range history {
+		total += c.historyPolicy.CountTokens(msg.Content) + messageTokenOverhead
+	}
+	return total
+}
+
+// lastTokens returns the most recent messages that fit in maxTokens.
+// The last message is always kept, even if it alone exceeds the budget.
+func lastTokens(history []redis.ChatMessage, maxTokens int, countTokens llm.TokenCounter) []redis.ChatMessage {
+	total := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		total += countTokens(history[i].Content) + messageTokenOverhead
+		if total > maxTokens && i < len(history)-1 {
+			return history[i+1:]
+		}
+	}
+	return history
+}
+
+// turnStart returns the index where the last maxTurns turns begin.
+func turnStart(history []redis.ChatMessage, maxTurns int) int {
+	turns := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" && (i == 0 || history[i-1].Role != "user") {
+			turns++
+			if turns == maxTurns {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// summaryMatches reports whether the summary still covers the start of history.
+func summaryMatches(summary store.HistorySummary, history []redis.ChatMessage) bool {
+	if summary.MessageCount <= 0 || summary.MessageCount > len(history) {
+		return false
+	}
+	return history[summary.MessageCount-1].Timestamp.Equal(summary.LastMessageTime)
+}
+
+// summarizeHistory folds the messages older than the last MaxTurns turns into the summary.
+// It runs in the background, detached from the request context, so a new user message
+// cancelling the reply does not throw the summary away.
+func (c *Client) summarizeHistory(userID string, history []redis.ChatMessage, previous store.HistorySummary, redisClient *redis.Client) {
+	end := turnStart(history, c.historyPolicy.MaxTurns)
+	if end <= previous.MessageCount {
+		return
+	}
+
+	acquired, err := redisClient.AcquireHistorySummaryLock(userID, summaryTimeout)
+	if err != nil || !acquired {
+		return
+	}
+	defer redisClient.ReleaseHistorySummaryLock(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+	defer cancel()
+
+	var transcript strings.Builder
+	if previous.Summary != "" {
+		fmt.Fprintf(&transcript, "Resumo anterior:\n%s\n\nNovas mensagens:\n", previous.Summary)
+	}
+	for _, msg := range history[previous.MessageCount:end] {
+		speaker := "Usuário"
+		if msg.Role != "user" {
+			speaker = "Assistente"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, msg.Content)
+	}
+
+	if model := c.historyPolicy.SummaryModel; model != "" {
+		ctx = withRoutedModel(ctx, model)
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("model", c.targets(ctx)[0].Model).
+		Int("from_message", previous.MessageCount).
+		Int("to_message", end).
+		Msg("Summarizing older conversation turns")
+
+	completion, err := c.complete(ctx, userID, llm.Request{
+		Messages: []llm.Message{
+			llm.SystemMessage(summaryPrompt),
+			llm.UserMessage(transcript.String()),
+		},
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error summarizing conversation history")
+		return
+	}
+
+	summary := store.HistorySummary{
+		Summary:         strings.TrimSpace(completion.Content),
+		MessageCount:    end,
+		LastMessageTime: history[end-1].Timestamp,
+		UpdatedAt:       time.Now(),
+	}
+	if err := c.historySummaries.SaveHistorySummary(ctx, userID, summary); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error storing conversation summary")
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Int("summarized_messages", end).
+		Int("summary_length", len(summary.Summary)).
+		Msg("Conversation summary updated")
+}
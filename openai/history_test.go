@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func testHistory() []redis.ChatMessage {
+	return []redis.ChatMessage{
+		{Role: "user", Content: "Oi"},
+		{Role: "assistant", Content: "Olá! Como posso ajudar?"},
+		{Role: "user", Content: "Quero saber o preço"},
+		{Role: "user", Content: "do plano anual"},
+		{Role: "assistant", Content: "O plano anual custa R$ 1.200,00."},
+		{Role: "user", Content: "Obrigado"},
+	}
+}
+
+func TestWindowHistory_LastTurns(t *testing.T) {
+	client := Client{historyPolicy: HistoryPolicy{Mode: HistoryLastTurns, MaxTurns: 2}.withDefaults()}
+
+	window := client.windowHistory(testHistory())
+
+	// Consecutive user messages belong to the same turn
+	if len(window) != 4 || window[0].Content != "Quero saber o preço" {
+		t.Errorf("Expected the last 2 turns starting at 'Quero saber o preço', got %+v", window)
+	}
+}
+
+func TestWindowHistory_LastTokens(t *testing.T) {
+	oneToken := func(text string) int { return 1 }
+	client := Client{historyPolicy: HistoryPolicy{Mode: HistoryLastTokens, MaxTokens: 15, CountTokens: oneToken}.withDefaults()}
+
+	window := client.windowHistory(testHistory())
+
+	// Each message costs 1 token plus the per-message overhead of 4
+	if len(window) != 3 || window[0].Content != "do plano anual" {
+		t.Errorf("Expected the 3 most recent messages, got %+v", window)
+	}
+}
+
+func TestWindowHistory_LastTokensKeepsLastMessage(t *testing.T) {
+	client := Client{historyPolicy: HistoryPolicy{Mode: HistoryLastTokens, MaxTokens: 1, CountTokens: llm.EstimateTokens}.withDefaults()}
+
+	window := client.windowHistory(testHistory())
+
+	if len(window) != 1 || window[0].Content != "Obrigado" {
+		t.Errorf("Expected only the last message, got %+v", window)
+	}
+}
+
+func TestSummaryMatches(t *testing.T) {
+	now := time.Now()
+	history := []redis.ChatMessage{
+		{Role: "user", Content: "Oi", Timestamp: now},
+		{Role: "assistant", Content: "Olá", Timestamp: now.Add(time.Second)},
+	}
+
+	if !summaryMatches(store.HistorySummary{MessageCount: 2, LastMessageTime: now.Add(time.Second)}, history) {
+		t.Error("Expected summary covering the whole history to match")
+	}
+	if summaryMatches(store.HistorySummary{MessageCount: 3, LastMessageTime: now}, history) {
+		t.Error("Expected summary covering more messages than the history not to match")
+	}
+	if summaryMatches(store.HistorySummary{MessageCount: 1, LastMessageTime: now.Add(time.Hour)}, history) {
+		t.Error("Expected summary with a different last message time not to match")
+	}
+}
+
+func TestSummarizeHistory_UsesRetryPolicyAndSummaryModel(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.Enqueue(llmtest.ErrorResponse(http.StatusInternalServerError, "overloaded"), llmtest.OpenAICompletion("Usuário quer o plano anual."))
+
+	redisClient, err := redis.Connect(miniredis.RunT(t).Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{}), nil, nil, "chat-model")
+	client.historyPolicy = HistoryPolicy{Mode: HistoryRollingSummary, MaxTurns: 1, SummaryModel: "summary-model"}.withDefaults()
+	client.SetRetryPolicy(llm.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, nil)
+	client.SetHistorySummaryStore(redis.NewHistoryStore(redisClient, 0))
+
+	client.summarizeHistory("5511999999999", testHistory(), store.HistorySummary{}, &redisClient)
+
+	summary, found, err := client.HistorySummary("5511999999999")
+	if err != nil || !found {
+		t.Fatalf("summary not stored after a retried failure: found=%v err=%v", found, err)
+	}
+	if summary.Summary != "Usuário quer o plano anual." || summary.MessageCount != 5 {
+		t.Errorf("summary = %+v, want the retried completion covering 5 messages", summary)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want the failed one retried", len(requests))
+	}
+	var sent struct{ Model string }
+	if err := json.Unmarshal([]byte(requests[1]), &sent); err != nil || sent.Model != "summary-model" {
+		t.Errorf("model = %q, want the summary model", sent.Model)
+	}
+}
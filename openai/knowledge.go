@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KnowledgeRetriever returns reference material relevant to query, formatted to be added
+// to the prompt, or an empty string when nothing relevant is found.
+type KnowledgeRetriever func(ctx context.Context, query string) (string, error)
+
+// SetKnowledgeRetriever makes the client add the material found for the user's latest
+// messages as a system message before generating each response.
+func (c *Client) SetKnowledgeRetriever(retriever KnowledgeRetriever) {
+	c.knowledgeRetriever = retriever
+}
+
+// injectKnowledge adds the reference material for the pending user messages right after the system prompt.
+func (c *Client) injectKnowledge(
+	ctx context.Context,
+	userID string,
+	chatHistory []redis.ChatMessage,
+	messages []llm.Message,
+) []llm.Message {
+	if c.knowledgeRetriever == nil || len(messages) == 0 {
+		return messages
+	}
+
+	query := pendingUserMessages(chatHistory)
+	if query == "" {
+		return messages
+	}
+
+	material, err := c.knowledgeRetriever(ctx, query)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error retrieving knowledge, continuing without it")
+		return messages
+	}
+	if material == "" {
+		return messages
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Int("material_length", len(material)).
+		Msg("Injecting knowledge base material into the prompt")
+
+	knowledgeMessage := llm.SystemMessage("Use as informações abaixo, da base de conhecimento, se forem relevantes para responder:\n\n" + material)
+	return append(messages[:1], append([]llm.Message{knowledgeMessage}, messages[1:]...)...)
+}
+
+// pendingUserMessages joins the user messages sent after the last assistant reply.
+func pendingUserMessages(chatHistory []redis.ChatMessage) string {
+	var pending []string
+	for i := len(chatHistory) - 1; i >= 0 && chatHistory[i].Role == "user"; i-- {
+		pending = append([]string{chatHistory[i].Content}, pending...)
+	}
+	return strings.Join(pending, "\n")
+}
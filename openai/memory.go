@@ -0,0 +1,225 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryStore persists long-term facts about users. It is implemented by *redis.Client.
+type MemoryStore interface {
+	AddUserMemory(userID, content, source string) (redis.Memory, error)
+	GetUserMemories(userID string) ([]redis.Memory, error)
+	DeleteUserMemory(userID, memoryID string) (bool, error)
+}
+
+// EnableMemory makes the client inject the user's memories into the system prompt.
+func (c *Client) EnableMemory(store MemoryStore) {
+	c.memoryStore = store
+}
+
+// promptContext collects what is known about the user for the system prompt.
+func (c *Client) promptContext(userID, userName string) PromptContext {
+	prompt := PromptContext{UserName: userName, UserPhone: userID, model: new(string)}
+	if c.memoryStore == nil {
+		return prompt
+	}
+
+	memories, err := c.memoryStore.GetUserMemories(userID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error loading user memories")
+		return prompt
+	}
+	for _, memory := range memories {
+		prompt.Memories = append(prompt.Memories, memory.Content)
+	}
+	return prompt
+}
+
+// MemoryTools returns the built-in tools that let the model save, list and delete
+// memories, so users can ask the bot what it remembers about them and to forget it.
+func MemoryTools(store MemoryStore) []Tool {
+	return []Tool{
+		{
+			Definition: llm.ToolDefinition{
+				Name:        "remember",
+				Description: "Salva um fato duradouro sobre o usuário para lembrar em conversas futuras, como nome, preferências, endereço ou pedidos. Use quando o usuário compartilhar algo útil para o futuro ou pedir para você lembrar de algo.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"fact": map[string]any{
+							"type":        "string",
+							"description": "O fato, em uma frase curta e autocontida. Ex: 'Prefere ser chamado de Beto'",
+						},
+					},
+					"required": []string{"fact"},
+				},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (string, error) {
+				userID, err := memoryToolUser(ctx)
+				if err != nil {
+					return "", err
+				}
+				fact, _ := args["fact"].(string)
+				if strings.TrimSpace(fact) == "" {
+					return "", fmt.Errorf("fact is required")
+				}
+				memory, err := store.AddUserMemory(userID, fact, redis.MemorySourceTool)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("Fato salvo (id %s).", memory.ID), nil
+			},
+		},
+		{
+			Definition: llm.ToolDefinition{
+				Name:        "list_memories",
+				Description: "Lista tudo o que você lembra sobre o usuário, com os IDs de cada fato. Use quando o usuário perguntar o que você sabe sobre ele.",
+				Parameters:  map[string]any{"type": "object", "properties": map[string]any{}},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (string, error) {
+				userID, err := memoryToolUser(ctx)
+				if err != nil {
+					return "", err
+				}
+				memories, err := store.GetUserMemories(userID)
+				if err != nil {
+					return "", err
+				}
+				if len(memories) == 0 {
+					return "Nenhum fato salvo sobre este usuário.", nil
+				}
+				memoriesJSON, err := json.Marshal(memories)
+				if err != nil {
+					return "", err
+				}
+				return string(memoriesJSON), nil
+			},
+		},
+		{
+			Definition: llm.ToolDefinition{
+				Name:        "forget_memory",
+				Description: "Apaga um fato salvo sobre o usuário, ou todos eles. Use quando o usuário pedir para você esquecer algo.",
+				Parameters: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"memory_id": map[string]any{
+							"type":        "string",
+							"description": "ID do fato retornado por list_memories, ou 'all' para apagar todos",
+						},
+					},
+					"required": []string{"memory_id"},
+				},
+			},
+			Handler: func(ctx context.Context, args map[string]any) (string, error) {
+				userID, err := memoryToolUser(ctx)
+				if err != nil {
+					return "", err
+				}
+				memoryID, _ := args["memory_id"].(string)
+				if memoryID != "all" {
+					deleted, err := store.DeleteUserMemory(userID, memoryID)
+					if err != nil {
+						return "", err
+					}
+					if !deleted {
+						return "", fmt.Errorf("memory %s not found", memoryID)
+					}
+					return "Fato apagado.", nil
+				}
+
+				memories, err := store.GetUserMemories(userID)
+				if err != nil {
+					return "", err
+				}
+				for _, memory := range memories {
+					if _, err := store.DeleteUserMemory(userID, memory.ID); err != nil {
+						return "", err
+					}
+				}
+				return fmt.Sprintf("%d fatos apagados.", len(memories)), nil
+			},
+		},
+	}
+}
+
+// memoryToolUser returns the user the memory tool is being called for.
+func memoryToolUser(ctx context.Context) (string, error) {
+	info, ok := ToolCallInfoFromContext(ctx)
+	if !ok || info.UserID == "" {
+		return "", fmt.Errorf("memory tools require the calling user")
+	}
+	return info.UserID, nil
+}
+
+var memoryExtractionPrompt = `Você extrai fatos duradouros sobre um usuário a partir de uma conversa de WhatsApp com um assistente.
+
+Extraia somente fatos úteis em conversas futuras: nome e como prefere ser chamado, preferências, dados de contato e endereço informados, pedidos e compras, problemas relatados e combinados feitos.
+
+Regras:
+- Cada fato deve ser uma frase curta e autocontida, em português
+- Não repita fatos que já são conhecidos
+- Ignore cumprimentos, detalhes passageiros e informações sobre o assistente
+- Se não houver fatos novos, retorne uma lista vazia`
+
+// memoryExtraction is the structured output of the extraction call.
+type memoryExtraction struct {
+	Facts []string `json:"facts" jsonschema_description:"Novos fatos duradouros sobre o usuário"`
+}
+
+// ExtractMemories asks the model for durable facts about the user found in the given
+// messages that are not among the known memories.
+func (c *Client) ExtractMemories(
+	ctx context.Context,
+	userID string,
+	messages []redis.ChatMessage,
+	known []redis.Memory,
+) ([]string, error) {
+	var transcript strings.Builder
+	if len(known) > 0 {
+		transcript.WriteString("Fatos já conhecidos:\n")
+		for _, memory := range known {
+			transcript.WriteString("- " + memory.Content + "\n")
+		}
+		transcript.WriteString("\n")
+	}
+	transcript.WriteString("Conversa:\n")
+	for _, msg := range messages {
+		speaker := "Usuário"
+		if msg.Role != "user" {
+			speaker = "Assistente"
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, msg.Content)
+	}
+
+	completion, err := c.complete(ctx, userID, llm.Request{
+		Messages: []llm.Message{
+			llm.SystemMessage(memoryExtractionPrompt),
+			llm.UserMessage(transcript.String()),
+		},
+		ResponseSchema: &llm.JSONSchema{
+			Name:        "memory_extraction",
+			Description: "Fatos duradouros sobre o usuário",
+			Schema:      GenerateSchema[memoryExtraction](),
+			Strict:      true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var extraction memoryExtraction
+	if err := json.Unmarshal([]byte(completion.Content), &extraction); err != nil {
+		return nil, fmt.Errorf("invalid memory extraction response: %w", err)
+	}
+	return extraction.Facts, nil
+}
@@ -0,0 +1,21 @@
+package openai
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/metrics"
+)
+
+// observeLLMRequest records the duration and outcome of a request attempt to provider.
+func observeLLMRequest(provider llm.Provider, model, mode string, start time.Time, err error) {
+	metrics.LLMRequestDuration.
+		WithLabelValues(provider.Name(), model, mode, metrics.Outcome(err)).
+		Observe(metrics.Since(start))
+}
+
+// observeLLMUsage records the tokens a provider reported for a request.
+func observeLLMUsage(provider llm.Provider, model string, usage llm.Usage) {
+	metrics.LLMTokens.WithLabelValues(provider.Name(), model, "prompt").Add(float64(usage.PromptTokens))
+	metrics.LLMTokens.WithLabelValues(provider.Name(), model, "completion").Add(float64(usage.CompletionTokens))
+}
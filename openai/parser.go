@@ -2,11 +2,14 @@ package openai
 
 import (
 	"encoding/json"
-	"fmt"
+	"regexp"
 	"strings"
 )
 
-// StreamingJSONParser handles incremental parsing of JSON responses from OpenAI's streaming API.
+// messagesArrayPattern matches the opening of the "messages" array, whatever the spacing.
+var messagesArrayPattern = regexp.MustCompile(`"messages"\s*:\s*\[`)
+
+// StreamingJSONParser handles incremental parsing of JSON responses from the streaming API.
 // It's designed to extract complete Message objects as they become available in the stream,
 // even when JSON data arrives in partial chunks.
 type StreamingJSONParser struct {
@@ -29,64 +32,48 @@ func NewStreamingJSONParser() *StreamingJSONParser {
 // to parse any complete Message objects. Returns a slice of newly parsed messages.
 func (p *StreamingJSONParser) AddChunk(chunk string) []Message {
 	p.buffer.WriteString(chunk)
-
-	// Debug logging to understand what content we're receiving
-	if len(chunk) > 0 {
-		fmt.Printf("DEBUG: AddChunk received: %q\n", chunk)
-		fmt.Printf("DEBUG: Buffer now contains: %q\n", p.buffer.String())
-	}
-
-	messages := p.parseNewMessages()
-	if len(messages) > 0 {
-		fmt.Printf("DEBUG: Parsed %d messages from chunk\n", len(messages))
-	}
-
-	return messages
+	return p.parseNewMessages()
 }
 
 // parseNewMessages scans the buffer for complete message objects and parses them.
-// It maintains the parsing position to avoid re-parsing already processed messages.
+// It maintains the parsing position to avoid re-parsing already processed messages,
+// so each message is returned as soon as its closing brace arrives.
 func (p *StreamingJSONParser) parseNewMessages() []Message {
 	content := p.buffer.String()
 	var parsedMessages []Message
 
-	fmt.Printf("DEBUG: parseNewMessages called, buffer length: %d, current MsgCount: %d\n", len(content), p.MsgCount)
-
-	// Sempre tenta fazer parse do JSON completo se contém "messages"
-	if strings.Contains(content, `"messages":[`) || strings.Contains(content, `"messages": [`) {
-		fmt.Printf("DEBUG: Attempting to parse complete JSON\n")
-
-		var messageList MessageList
-		if err := json.Unmarshal([]byte(content), &messageList); err == nil {
-			fmt.Printf("DEBUG: Successfully parsed complete JSON with %d total messages\n", len(messageList.Messages))
-
-			// Retorna apenas as mensagens que ainda não foram processadas
-			if len(messageList.Messages) > p.MsgCount {
-				newMessages := messageList.Messages[p.MsgCount:]
-				oldMsgCount := p.MsgCount
-				p.MsgCount = len(messageList.Messages)
+	if !p.foundMessages {
+		loc := messagesArrayPattern.FindStringIndex(content)
+		if loc == nil {
+			return parsedMessages
+		}
+		p.foundMessages = true
+		p.lastParsedPos = loc[1]
+	}
 
-				fmt.Printf("DEBUG: Returning %d new messages (from %d to %d)\n", len(newMessages), oldMsgCount, p.MsgCount)
+	for {
+		start := p.lastParsedPos
+		for start < len(content) && strings.ContainsRune(" \t\r\n,", rune(content[start])) {
+			start++
+		}
+		if start >= len(content) || content[start] != '{' {
+			// Either more data is needed or the array is closed
+			return parsedMessages
+		}
 
-				for i, msg := range newMessages {
-					fmt.Printf("DEBUG: New message %d: content=%q, type=%q\n", oldMsgCount+i+1, msg.Content, msg.Type)
-				}
+		end := p.findMessageEnd(content, start)
+		if end == -1 {
+			return parsedMessages
+		}
+		p.lastParsedPos = end + 1
 
-				return newMessages
-			} else {
-				fmt.Printf("DEBUG: No new messages to return (already processed %d messages)\n", p.MsgCount)
-				return parsedMessages
-			}
-		} else {
-			fmt.Printf("DEBUG: Failed to parse complete JSON: %v\n", err)
-			fmt.Printf("DEBUG: JSON content: %q\n", content)
+		var msg Message
+		if err := json.Unmarshal([]byte(content[start:end+1]), &msg); err != nil {
+			continue
 		}
-	} else {
-		fmt.Printf("DEBUG: No 'messages' array found in content yet\n")
+		p.MsgCount++
+		parsedMessages = append(parsedMessages, msg)
 	}
-
-	fmt.Printf("DEBUG: parseNewMessages returning 0 messages (incomplete or invalid JSON)\n")
-	return parsedMessages
 }
 
 // findMessageEnd locates the closing brace of a JSON object starting at startIdx.
@@ -97,12 +84,6 @@ func (p *StreamingJSONParser) findMessageEnd(content string, startIdx int) int {
 	inString := false
 	escaped := false
 
-	endPreview := startIdx + 50
-	if endPreview > len(content) {
-		endPreview = len(content)
-	}
-	fmt.Printf("DEBUG: findMessageEnd called for content starting at %d: %q\n", startIdx, content[startIdx:endPreview])
-
 	for i := startIdx; i < len(content); i++ {
 		char := content[i]
 
@@ -128,13 +109,11 @@ func (p *StreamingJSONParser) findMessageEnd(content string, startIdx int) int {
 			case '}':
 				braceCount--
 				if braceCount == 0 {
-					fmt.Printf("DEBUG: findMessageEnd found closing brace at index %d\n", i)
 					return i
 				}
 			}
 		}
 	}
 
-	fmt.Printf("DEBUG: findMessageEnd could not find closing brace, returning -1\n")
 	return -1
 }
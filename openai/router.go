@@ -0,0 +1,274 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/metrics"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Route reasons, logged with the chosen model
+const (
+	RouteDefault = "default"
+	RoutePrompt  = "prompt"
+)
+
+// ModelRouter picks the model that answers each turn, so short greetings can go to a
+// cheap model and complex questions to a stronger one. The first rule matching the
+// turn wins; without a match the client's model is used. A model chosen by the prompt
+// generator through PromptContext.UseModel overrides the rules.
+type ModelRouter struct {
+	Rules      []RouteRule
+	Classifier IntentClassifier
+}
+
+// RouteRule selects Model for the turns that meet all of its conditions.
+// Conditions left at their zero value match every turn.
+type RouteRule struct {
+	Name         string   // Logged with the choice (default the model)
+	Model        string   // Model used when the rule matches
+	MinLength    int      // Pending user messages of at least this many characters
+	MaxLength    int      // Pending user messages of at most this many characters
+	WithTools    bool     // Only turns where tools are offered to the model
+	WithoutTools bool     // Only turns where no tools are offered
+	Intents      []string // Turns the classifier assigns one of these intents
+	Attribute    string   // User attribute, such as tier, that must have one of Values
+	Values       []string // Accepted values of Attribute
+}
+
+// IntentClassifier classifies the user's pending messages with a cheap model, for rules
+// with Intents. It only runs when such a rule is reached.
+type IntentClassifier struct {
+	Model   string            // Model that classifies (default the client's model)
+	Intents map[string]string // Intent names and descriptions the model chooses from
+}
+
+// AttributeReader reads the user's attributes for rules on Attribute.
+type AttributeReader interface {
+	GetConversationAttributes(userID string) (map[string]string, error)
+}
+
+// SetModelRouter makes the client pick the model of each turn with router. Attributes
+// are read from attributes; it may be nil when no rule uses them.
+func (c *Client) SetModelRouter(router ModelRouter, attributes AttributeReader) {
+	c.router = &router
+	c.routerAttributes = attributes
+}
+
+// routeInput is what is known about a turn when its rules are evaluated. The intent and
+// the attributes are loaded on first use, since they cost a request.
+type routeInput struct {
+	userID      string
+	pending     string
+	length      int
+	tools       bool
+	intent      *string
+	attributes  map[string]string
+	attrsLoaded bool
+}
+
+// routeTurn picks the model of the turn and returns a context that sends the turn's
+// requests to it. promptModel is the model chosen by the prompt generator, if any.
+func (c *Client) routeTurn(ctx context.Context, config streamingConfig, promptModel string) context.Context {
+	model, reason, intent := c.chooseModel(ctx, config, promptModel)
+
+	log.Info().
+		Str("user_id", config.userID).
+		Str("model", model).
+		Str("reason", reason).
+		Str("intent", intent).
+		Msg("Model selected for turn")
+	metrics.ModelRoutes.WithLabelValues(model, reason).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("chatbot.model", model),
+		attribute.String("chatbot.route", reason),
+	)
+
+	return withRoutedModel(ctx, model)
+}
+
+// chooseModel returns the model of the turn, why it was chosen and the classified intent, if any.
+func (c *Client) chooseModel(ctx context.Context, config streamingConfig, promptModel string) (model, reason, intent string) {
+	if promptModel != "" {
+		return promptModel, RoutePrompt, ""
+	}
+	if c.router == nil {
+		return c.model, RouteDefault, ""
+	}
+
+	pending := pendingUserMessages(config.chatHistory)
+	input := &routeInput{
+		userID:  config.userID,
+		pending: pending,
+		length:  utf8.RuneCountInString(pending),
+		// Tools switched off at runtime are not offered, so they don't count
+		tools: len(c.toolDefinitions(c.disabledTools())) > 0,
+	}
+	for _, rule := range c.router.Rules {
+		if c.ruleMatches(ctx, rule, input) {
+			name := rule.Name
+			if name == "" {
+				name = rule.Model
+			}
+			return rule.Model, "rule:" + name, input.classifiedIntent()
+		}
+	}
+	return c.model, RouteDefault, input.classifiedIntent()
+}
+
+func (in *routeInput) classifiedIntent() string {
+	if in.intent == nil {
+		return ""
+	}
+	return *in.intent
+}
+
+// ruleMatches checks the cheap conditions first, so the classifier and the attribute
+// store are only queried for rules that could still match.
+func (c *Client) ruleMatches(ctx context.Context, rule RouteRule, in *routeInput) bool {
+	if rule.MinLength > 0 && in.length < rule.MinLength {
+		return false
+	}
+	if rule.MaxLength > 0 && in.length > rule.MaxLength {
+		return false
+	}
+	if rule.WithTools && !in.tools {
+		return false
+	}
+	if rule.WithoutTools && in.tools {
+		return false
+	}
+	if rule.Attribute != "" {
+		if !in.attrsLoaded {
+			in.attrsLoaded = true
+			in.attributes = c.routeAttributes(in.userID)
+		}
+		if !slices.Contains(rule.Values, in.attributes[rule.Attribute]) {
+			return false
+		}
+	}
+	if len(rule.Intents) > 0 {
+		if in.intent == nil {
+			intent := c.classifyIntent(ctx, in.userID, in.pending)
+			in.intent = &intent
+		}
+		if !slices.Contains(rule.Intents, *in.intent) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) routeAttributes(userID string) map[string]string {
+	if c.routerAttributes == nil {
+		return nil
+	}
+	attributes, err := c.routerAttributes.GetConversationAttributes(userID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error loading attributes for model routing")
+		return nil
+	}
+	return attributes
+}
+
+const intentClassifierPrompt = `Você é um classificador de intenções. Leia as últimas mensagens do usuário e escolha a intenção que melhor as descreve entre as opções abaixo.
+
+%s
+Você DEVE chamar a função classify_intent com o nome da intenção escolhida.`
+
+// classifyIntent returns the intent of the pending messages, or an empty string when
+// there are no intents or the classification fails.
+func (c *Client) classifyIntent(ctx context.Context, userID, pending string) string {
+	intents := c.router.Classifier.Intents
+	if len(intents) == 0 || pending == "" {
+		return ""
+	}
+
+	names := make([]string, 0, len(intents))
+	for name := range intents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var options strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&options, "- %s: %s\n", name, intents[name])
+	}
+
+	tool := llm.ToolDefinition{
+		Name:        "classify_intent",
+		Description: "Record the intent of the user's latest messages",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"intent": map[string]any{
+					"type": "string",
+					"enum": names,
+				},
+			},
+			"required": []string{"intent"},
+		},
+	}
+
+	if model := c.router.Classifier.Model; model != "" {
+		ctx = withRoutedModel(ctx, model)
+	}
+	completion, err := c.complete(ctx, userID, llm.Request{
+		Messages: []llm.Message{
+			llm.SystemMessage(fmt.Sprintf(intentClassifierPrompt, options.String())),
+			llm.UserMessage(pending),
+		},
+		Tools:      []llm.ToolDefinition{tool},
+		ToolChoice: tool.Name,
+	})
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error classifying intent for model routing")
+		return ""
+	}
+	if len(completion.ToolCalls) == 0 {
+		log.Warn().
+			Str("user_id", userID).
+			Msg("Intent classifier didn't return a tool call")
+		return ""
+	}
+
+	var args struct {
+		Intent string `json:"intent"`
+	}
+	if err := json.Unmarshal([]byte(completion.ToolCalls[0].Arguments), &args); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error parsing intent classifier response")
+		return ""
+	}
+	return args.Intent
+}
+
+type routedModelKey struct{}
+
+// withRoutedModel returns a context whose requests go to model before the fallbacks.
+func withRoutedModel(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, routedModelKey{}, model)
+}
+
+// routedModel returns the model chosen for the requests made with ctx, or an empty string.
+func routedModel(ctx context.Context) string {
+	model, _ := ctx.Value(routedModelKey{}).(string)
+	return model
+}
@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+	"github.com/NextMind-AI/chatbot-go/redis"
+)
+
+type fakeAttributes map[string]string
+
+func (a fakeAttributes) GetConversationAttributes(userID string) (map[string]string, error) {
+	return a, nil
+}
+
+func routeConfig(messages ...string) streamingConfig {
+	history := []redis.ChatMessage{{Role: "assistant", Content: "Olá!"}}
+	for _, message := range messages {
+		history = append(history, redis.ChatMessage{Role: "user", Content: message})
+	}
+	return streamingConfig{userID: "5511999999999", chatHistory: history}
+}
+
+func TestChooseModel_Rules(t *testing.T) {
+	client := NewClient(nil, nil, nil, "default-model")
+	client.SetModelRouter(ModelRouter{Rules: []RouteRule{
+		{Name: "premium", Model: "strong-model", Attribute: "tier", Values: []string{"premium"}},
+		{Name: "short", Model: "cheap-model", MaxLength: 10},
+		{Model: "tools-model", WithTools: true},
+	}}, fakeAttributes{"tier": "free"})
+
+	cases := []struct {
+		messages   []string
+		wantModel  string
+		wantReason string
+	}{
+		{[]string{"oi"}, "cheap-model", "rule:short"},
+		// Consecutive pending messages are measured together
+		{[]string{"oi", "tudo bem com você?"}, "default-model", RouteDefault},
+	}
+	for _, tc := range cases {
+		model, reason, _ := client.chooseModel(context.Background(), routeConfig(tc.messages...), "")
+		if model != tc.wantModel || reason != tc.wantReason {
+			t.Errorf("chooseModel(%q) = %s, %s, want %s, %s", tc.messages, model, reason, tc.wantModel, tc.wantReason)
+		}
+	}
+
+	client.routerAttributes = fakeAttributes{"tier": "premium"}
+	if model, reason, _ := client.chooseModel(context.Background(), routeConfig("oi"), ""); model != "strong-model" || reason != "rule:premium" {
+		t.Errorf("Expected the premium tier to get strong-model, got %s, %s", model, reason)
+	}
+
+	if model, reason, _ := client.chooseModel(context.Background(), routeConfig("oi"), "override-model"); model != "override-model" || reason != RoutePrompt {
+		t.Errorf("Expected the prompt generator to override the rules, got %s, %s", model, reason)
+	}
+}
+
+type fakeToolStates []string
+
+func (s fakeToolStates) GetDisabledTools() ([]string, error) {
+	return s, nil
+}
+
+func TestChooseModel_DisabledTools(t *testing.T) {
+	tools := []Tool{{Definition: llm.ToolDefinition{Name: "generate_quote"}}}
+	client := NewClient(nil, nil, tools, "default-model")
+	client.SetModelRouter(ModelRouter{Rules: []RouteRule{{Model: "tools-model", WithTools: true}}}, nil)
+
+	if model, _, _ := client.chooseModel(context.Background(), routeConfig("oi"), ""); model != "tools-model" {
+		t.Errorf("chooseModel = %s with the tool enabled, want tools-model", model)
+	}
+
+	client.SetToolStates(fakeToolStates{"generate_quote"})
+	if model, _, _ := client.chooseModel(context.Background(), routeConfig("oi"), ""); model != "default-model" {
+		t.Errorf("chooseModel = %s with every tool disabled, want default-model", model)
+	}
+}
+
+func TestChooseModel_Intent(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.Enqueue(llmtest.OpenAICompletion("", [2]string{"classify_intent", `{"intent":"complaint"}`}))
+
+	client := NewClient(llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{}), nil, nil, "default-model")
+	client.SetRetryPolicy(llm.RetryPolicy{MaxAttempts: 1}, nil)
+	client.SetModelRouter(ModelRouter{
+		Rules: []RouteRule{
+			{Name: "greeting", Model: "cheap-model", MaxLength: 2},
+			{Name: "complaint", Model: "strong-model", Intents: []string{"complaint"}},
+		},
+		Classifier: IntentClassifier{
+			Model:   "classifier-model",
+			Intents: map[string]string{"complaint": "Reclamação", "question": "Dúvida"},
+		},
+	}, nil)
+
+	model, reason, intent := client.chooseModel(context.Background(), routeConfig("meu pedido chegou quebrado"), "")
+	if model != "strong-model" || reason != "rule:complaint" || intent != "complaint" {
+		t.Errorf("chooseModel = %s, %s, %s, want strong-model, rule:complaint, complaint", model, reason, intent)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0], `"model":"classifier-model"`) {
+		t.Errorf("Expected a single classification request to classifier-model, got %v", requests)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/metrics"
 	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/tracing"
 
-	"github.com/openai/openai-go"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var sleepAnalyzerPrompt = `Você é um analisador de conversas. Sua ÚNICA função é determinar quanto tempo esperar antes de responder a uma mensagem do usuário.
@@ -48,19 +51,25 @@ func (c *Client) DetermineSleepTime(
 	userID string,
 	userName string,
 	chatHistory []redis.ChatMessage,
-) (int, error) {
-	// Convert the full chat history to OpenAI format for context
-	messages := []openai.ChatCompletionMessageParamUnion{
-		openai.SystemMessage(sleepAnalyzerPrompt),
+) (sleepSeconds int, err error) {
+	ctx, span := tracing.Start(ctx, "DetermineSleepTime")
+	defer func() {
+		span.SetAttributes(attribute.Int("chatbot.sleep_seconds", sleepSeconds))
+		tracing.End(span, err)
+	}()
+
+	// Convert the full chat history to LLM messages for context
+	messages := []llm.Message{
+		llm.SystemMessage(sleepAnalyzerPrompt),
 	}
 
 	// Add the conversation history for full context
-	for _, msg := range chatHistory {
+	for _, msg := range c.windowHistory(chatHistory) {
 		switch msg.Role {
 		case "user":
-			messages = append(messages, openai.UserMessage(msg.Content))
-		case "assistant":
-			messages = append(messages, openai.AssistantMessage(msg.Content))
+			messages = append(messages, llm.UserMessage(msg.Content))
+		case "assistant", "agent":
+			messages = append(messages, llm.AssistantMessage(msg.Content))
 		}
 	}
 
@@ -79,21 +88,11 @@ func (c *Client) DetermineSleepTime(
 		Int("conversation_length", len(chatHistory)).
 		Msg("Analyzing full conversation context to determine sleep time")
 
-	chatCompletion, err := c.client.Chat.Completions.New(
-		ctx,
-		openai.ChatCompletionNewParams{
-			Messages: messages,
-			Model:    c.model,
-			Tools:    []openai.ChatCompletionToolParam{sleepTool},
-			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
-				OfChatCompletionNamedToolChoice: &openai.ChatCompletionNamedToolChoiceParam{
-					Function: openai.ChatCompletionNamedToolChoiceFunctionParam{
-						Name: sleepTool.Function.Name,
-					},
-				},
-			},
-		},
-	)
+	completion, err := c.complete(ctx, userID, llm.Request{
+		Messages:   messages,
+		Tools:      []llm.ToolDefinition{sleepTool},
+		ToolChoice: sleepTool.Name,
+	})
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -103,11 +102,11 @@ func (c *Client) DetermineSleepTime(
 	}
 
 	// Extract sleep duration from the tool call
-	if len(chatCompletion.Choices) > 0 && len(chatCompletion.Choices[0].Message.ToolCalls) > 0 {
-		toolCall := chatCompletion.Choices[0].Message.ToolCalls[0]
+	if len(completion.ToolCalls) > 0 {
+		toolCall := completion.ToolCalls[0]
 
 		var args map[string]any
-		err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
+		err := json.Unmarshal([]byte(toolCall.Arguments), &args)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -161,6 +160,8 @@ func (c *Client) ExecuteSleepAndRespond(
 			Str("user_id", config.userID).
 			Msg("Error determining sleep time, continuing without sleep")
 	} else {
+		metrics.SleepSeconds.Observe(float64(sleepSeconds))
+
 		// Step 2: Execute the sleep
 		log.Info().
 			Str("user_id", config.userID).
@@ -182,9 +183,11 @@ func (c *Client) ExecuteSleepAndRespond(
 	}
 
 	// Step 3: Handle custom tools if any are defined
-	messages := c.convertChatHistoryWithUserName(config.chatHistory, config.userName, config.userID)
+	messages, promptModel := c.conversationMessages(config)
+	ctx = c.routeTurn(ctx, config, promptModel)
+	messages = c.injectKnowledge(ctx, config.userID, config.chatHistory, messages)
 	if len(c.tools) > 0 {
-		finalMessages, err := c.handleToolCalls(ctx, messages, config.userID)
+		finalMessages, err := c.handleToolCalls(ctx, messages, config)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -4,15 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/metrics"
 	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+	"github.com/NextMind-AI/chatbot-go/tracing"
 	"github.com/NextMind-AI/chatbot-go/vonage"
 
-	"github.com/openai/openai-go"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // streamingConfig holds the configuration for a streaming chat completion request.
@@ -27,8 +33,8 @@ type streamingConfig struct {
 }
 
 // ProcessChatStreaming processes a chat conversation with streaming response.
-// It sends messages to the user via WhatsApp as they are generated by the AI.
-// This method does not use any tools.
+// It sends messages to the user via WhatsApp as they are generated by the AI,
+// after running the tools the model calls.
 func (c *Client) ProcessChatStreaming(
 	ctx context.Context,
 	userID string,
@@ -76,280 +82,272 @@ func (c *Client) ProcessChatStreamingWithTools(
 }
 
 // processStreamingChat handles the core streaming logic.
-// Since tools are no longer used, this simply converts history and streams the response.
+// It converts the history and streams the response, without waiting first.
 func (c *Client) processStreamingChat(ctx context.Context, config streamingConfig) error {
-	messages := c.convertChatHistoryWithUserName(config.chatHistory, config.userName, config.userID)
+	messages, promptModel := c.conversationMessages(config)
+	ctx = c.routeTurn(ctx, config, promptModel)
+	messages = c.injectKnowledge(ctx, config.userID, config.chatHistory, messages)
 	return c.streamResponse(ctx, config, messages)
 }
 
 // streamResponse creates a streaming chat completion and sends messages via WhatsApp as they arrive.
-// It handles the parsing of streamed JSON and manages message deduplication with guaranteed ordering.
+// The tools the model calls are run first, and the structured response is then streamed
+// without tools, since a response schema cannot be combined with tools on every provider.
 func (c *Client) streamResponse(
 	ctx context.Context,
 	config streamingConfig,
-	messages []openai.ChatCompletionMessageParamUnion,
+	messages []llm.Message,
 ) error {
-	schemaParam := createSchemaParam()
-
-	// Prepare tools for the request
-	tools := []openai.ChatCompletionToolParam{}
-	for _, tool := range c.tools {
-		tools = append(tools, tool.Definition)
-	}
+	log.Info().
+		Str("user_id", config.userID).
+		Msg("Starting new streaming response")
 
-	params := openai.ChatCompletionNewParams{
-		Messages: messages,
-		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
-			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{JSONSchema: schemaParam},
-		},
-		Model: c.model,
+	if len(c.tools) > 0 {
+		finalMessages, err := c.handleToolCalls(ctx, messages, config)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("user_id", config.userID).
+				Msg("Error handling tool calls, continuing with original messages")
+		} else {
+			messages = finalMessages
+		}
 	}
 
-	// Add tools if any are defined
-	if len(tools) > 0 {
-		params.Tools = tools
-	}
+	return c.streamMessages(ctx, config, llm.Request{
+		Messages:       messages,
+		ResponseSchema: createSchemaParam(),
+	})
+}
 
+// streamResponseWithoutTools streams the response without including tools in the streaming call
+func (c *Client) streamResponseWithoutTools(
+	ctx context.Context,
+	config streamingConfig,
+	messages []llm.Message,
+) error {
 	log.Info().
 		Str("user_id", config.userID).
-		Msg("Starting new streaming response")
+		Msg("Starting new streaming response (without tools)")
 
-	stream := c.client.Chat.Completions.NewStreaming(ctx, params)
+	return c.streamMessages(ctx, config, llm.Request{
+		Messages:       messages,
+		ResponseSchema: createSchemaParam(),
+	})
+}
 
-	parser := NewStreamingJSONParser()
-	var fullContent strings.Builder
-	sentMessages := make(map[int]bool)
+// streamMessages streams a structured response, retrying and falling back to other models
+// on failure. A single sender goroutine spans all attempts. Once messages have been queued,
+// a retry passes them back to the model and skips as many messages as were already queued,
+// so the user never receives duplicates.
+func (c *Client) streamMessages(
+	ctx context.Context,
+	config streamingConfig,
+	req llm.Request,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "streamResponse")
+	defer func() { tracing.End(span, err) }()
 
 	messageQueue := make(chan messageWithIndex, 100)
 	done := make(chan struct{})
 
+	// sent is only read after done is closed
+	var sent []Message
 	go func() {
 		defer close(done)
 		log.Info().
 			Str("user_id", config.userID).
 			Msg("Started goroutine for sequential message sending")
-		c.sendMessagesSequentially(ctx, config, messageQueue)
+		sent = c.sendMessagesSequentially(ctx, config, messageQueue)
 		log.Info().
 			Str("user_id", config.userID).
 			Msg("Goroutine for sequential message sending finished")
 	}()
 
-	totalMessagesQueued := 0
-
-	for stream.Next() {
-		evt := stream.Current()
-		log.Debug().
-			Str("user_id", config.userID).
-			Msg("Received new stream event")
-		if len(evt.Choices) > 0 {
-			content := evt.Choices[0].Delta.Content
-			fullContent.WriteString(content)
-
-			log.Debug().
-				Str("user_id", config.userID).
-				Str("content_chunk", content).
-				Msg("Appended content chunk to fullContent")
-
-			newMessages := parser.AddChunk(content)
-
-			for i, msg := range newMessages {
-				messageIndex := parser.MsgCount - len(newMessages) + i
-				if !sentMessages[messageIndex] {
-					sentMessages[messageIndex] = true
-					totalMessagesQueued++
-
-					log.Info().
-						Str("user_id", config.userID).
-						Int("message_index", messageIndex).
-						Int("total_queued", totalMessagesQueued).
-						Str("content", msg.Content).
-						Str("type", msg.Type).
-						Msg("Queueing streamed message for sequential sending")
-
-					select {
-					case messageQueue <- messageWithIndex{
-						message: msg,
-						index:   messageIndex,
-					}:
-						log.Debug().
-							Str("user_id", config.userID).
-							Int("message_index", messageIndex).
-							Msg("Message successfully sent to messageQueue")
-					case <-ctx.Done():
-						log.Warn().
-							Str("user_id", config.userID).
-							Int("total_queued", totalMessagesQueued).
-							Msg("Context done while sending to messageQueue, closing queue")
-						close(messageQueue)
-						<-done
-						return ctx.Err()
-					}
-				}
-			}
+	var queued []Message
+	target, err := c.withFallback(ctx, config.userID, func(target ModelTarget) error {
+		attemptReq := req
+		if len(queued) > 0 {
+			attemptReq.Messages = append(slices.Clone(req.Messages), resumeMessages(queued)...)
 		}
-	}
+		return c.streamAttempt(ctx, config, target, attemptReq, messageQueue, &queued)
+	})
 
 	log.Info().
 		Str("user_id", config.userID).
-		Int("total_messages_queued", totalMessagesQueued).
+		Int("total_messages_queued", len(queued)).
 		Msg("Stream finished, closing messageQueue")
+	span.SetAttributes(attribute.Int("chatbot.messages_queued", len(queued)))
 
 	close(messageQueue)
 	<-done
+	span.SetAttributes(attribute.Int("chatbot.messages_sent", len(sent)))
+
+	if len(queued) == 0 {
+		log.Warn().
+			Str("user_id", config.userID).
+			Msg("No messages were queued during streaming, the user got no reply")
+	}
 
-	if err := stream.Err(); err != nil {
+	if err != nil {
 		log.Error().
 			Str("user_id", config.userID).
 			Err(err).
 			Msg("Stream encountered error")
+
+		// Keep the history in line with what the user actually received
+		if len(sent) > 0 {
+			model := target.Model
+			if model == "" {
+				model = c.targets(ctx)[0].Model
+			}
+			if storeErr := c.finalizeStreamingResponse(config, sent, model); storeErr != nil {
+				log.Error().
+					Err(storeErr).
+					Str("user_id", config.userID).
+					Msg("Error storing partial response")
+			}
+		}
 		return err
 	}
 
 	log.Info().
 		Str("user_id", config.userID).
-		Msg("Finalizing streaming response")
-	return c.finalizeStreamingResponse(config.userID, fullContent.String(), config.redisClient)
+		Str("provider", target.Provider.Name()).
+		Str("model", target.Model).
+		Int("message_count", len(sent)).
+		Msg("Response generated, finalizing streaming response")
+	return c.finalizeStreamingResponse(config, sent, target.Model)
 }
 
-// streamResponseWithoutTools streams the response without including tools in the streaming call
-func (c *Client) streamResponseWithoutTools(
+// resumeMessages tells the model which messages of an interrupted answer were already
+// sent, so the retried answer starts with them instead of contradicting them.
+func resumeMessages(queued []Message) []llm.Message {
+	sent, _ := json.Marshal(MessageList{Messages: queued})
+	return []llm.Message{
+		llm.AssistantMessage(string(sent)),
+		llm.SystemMessage(fmt.Sprintf("Sua resposta acima foi interrompida e o usuário já recebeu essas %d mensagens. Responda de novo começando exatamente pelas mesmas mensagens, na mesma ordem, e continue a partir delas.", len(queued))),
+	}
+}
+
+// streamAttempt streams a single response from target and queues each message as soon as
+// it is parsed. Messages whose index is below len(*queued) went out in an earlier attempt
+// and are skipped; the parser's MsgCount gives the index of every new message.
+func (c *Client) streamAttempt(
 	ctx context.Context,
 	config streamingConfig,
-	messages []openai.ChatCompletionMessageParamUnion,
-) error {
-	schemaParam := createSchemaParam()
-
-	log.Info().
-		Str("user_id", config.userID).
-		Msg("Starting new streaming response (without tools)")
+	target ModelTarget,
+	req llm.Request,
+	messageQueue chan<- messageWithIndex,
+	queued *[]Message,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "llm.stream",
+		attribute.String("llm.provider", target.Provider.Name()),
+		attribute.String("llm.model", target.Model),
+	)
+	defer func() { tracing.End(span, err) }()
 
-	stream := c.client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
-		Messages: messages,
-		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
-			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{JSONSchema: schemaParam},
-		},
-		Model: c.model,
-	})
+	req.Model = target.Model
+	start := time.Now()
+	stream, err := target.Provider.Stream(ctx, req)
+	if err != nil {
+		observeLLMRequest(target.Provider, target.Model, "stream", start, err)
+		return err
+	}
+	defer stream.Close()
 
 	parser := NewStreamingJSONParser()
 	var fullContent strings.Builder
-	sentMessages := make(map[int]bool)
-	totalMessagesQueued := 0
-
-	messageQueue := make(chan messageWithIndex, 100)
-	done := make(chan struct{})
-
-	go func() {
-		defer close(done)
-		log.Info().
-			Str("user_id", config.userID).
-			Msg("Started goroutine for sequential message sending (without tools)")
-		c.sendMessagesSequentially(ctx, config, messageQueue)
-		log.Info().
-			Str("user_id", config.userID).
-			Msg("Goroutine for sequential message sending finished (without tools)")
-	}()
+	firstMessage := true
 
 	for stream.Next() {
 		evt := stream.Current()
+		if evt.Usage != nil {
+			c.recordUsage(config.userID, target.Provider, target.Model, *evt.Usage)
+		}
+		if evt.Content == "" {
+			continue
+		}
+		fullContent.WriteString(evt.Content)
+
 		log.Debug().
 			Str("user_id", config.userID).
-			Msg("Received new stream event (without tools)")
-		if len(evt.Choices) > 0 {
-			content := evt.Choices[0].Delta.Content
-			fullContent.WriteString(content)
-
-			log.Debug().
-				Str("user_id", config.userID).
-				Str("content_chunk", content).
-				Msg("Appended content chunk to fullContent (without tools)")
-
-			newMessages := parser.AddChunk(content)
-
-			if len(newMessages) > 0 {
-				log.Info().
+			Str("content_chunk", evt.Content).
+			Msg("Appended content chunk to fullContent")
+
+		newMessages := parser.AddChunk(evt.Content)
+		if firstMessage && len(newMessages) > 0 {
+			firstMessage = false
+			span.AddEvent("first message parsed")
+			metrics.LLMTimeToFirstMessage.
+				WithLabelValues(target.Provider.Name(), target.Model).
+				Observe(metrics.Since(start))
+		}
+		for i, msg := range newMessages {
+			messageIndex := parser.MsgCount - len(newMessages) + i
+			if messageIndex < len(*queued) {
+				log.Debug().
 					Str("user_id", config.userID).
-					Int("new_messages_count", len(newMessages)).
-					Msg("Parser found new messages in chunk (without tools)")
+					Int("message_index", messageIndex).
+					Msg("Skipping message already sent by a previous attempt")
+				metrics.DuplicateMessagesDropped.Inc()
+				continue
 			}
+			*queued = append(*queued, msg)
 
-			for i, msg := range newMessages {
-				messageIndex := parser.MsgCount - len(newMessages) + i
-				if !sentMessages[messageIndex] {
-					sentMessages[messageIndex] = true
-					totalMessagesQueued++
-
-					log.Info().
-						Str("user_id", config.userID).
-						Int("message_index", messageIndex).
-						Int("total_queued", totalMessagesQueued).
-						Str("content", msg.Content).
-						Str("type", msg.Type).
-						Msg("Queueing streamed message for sequential sending (without tools)")
-
-					select {
-					case messageQueue <- messageWithIndex{
-						message: msg,
-						index:   messageIndex,
-					}:
-						log.Debug().
-							Str("user_id", config.userID).
-							Int("message_index", messageIndex).
-							Msg("Message sent to messageQueue (without tools)")
-					case <-ctx.Done():
-						log.Warn().
-							Str("user_id", config.userID).
-							Int("total_queued", totalMessagesQueued).
-							Msg("Context done while sending to messageQueue (without tools), closing queue")
-						close(messageQueue)
-						<-done
-						return ctx.Err()
-					}
-				}
+			log.Info().
+				Str("user_id", config.userID).
+				Str("model", target.Model).
+				Int("message_index", messageIndex).
+				Str("content", msg.Content).
+				Str("type", msg.Type).
+				Msg("Queueing streamed message for sequential sending")
+
+			select {
+			case messageQueue <- messageWithIndex{
+				message: msg,
+				index:   messageIndex,
+			}:
+			case <-ctx.Done():
+				log.Warn().
+					Str("user_id", config.userID).
+					Int("total_queued", len(*queued)).
+					Msg("Context done while sending to messageQueue")
+				return ctx.Err()
 			}
 		}
 	}
 
-	log.Info().
-		Str("user_id", config.userID).
-		Int("total_messages_queued", totalMessagesQueued).
-		Msg("Stream finished, closing messageQueue (without tools)")
-
-	// Check if no messages were queued during streaming
-	if totalMessagesQueued == 0 {
-		log.Warn().
-			Str("user_id", config.userID).
-			Msg("WARNING: No messages were queued during streaming - this is why the bot didn't respond!")
+	err = stream.Err()
+	observeLLMRequest(target.Provider, target.Model, "stream", start, err)
+	if err != nil {
+		return err
 	}
 
-	close(messageQueue)
-	<-done
-
-	if err := stream.Err(); err != nil {
+	var messageList MessageList
+	if err := json.Unmarshal([]byte(fullContent.String()), &messageList); err != nil {
 		log.Error().
-			Str("user_id", config.userID).
 			Err(err).
-			Msg("Stream encountered error (without tools)")
-		return err
+			Str("user_id", config.userID).
+			Str("content", fullContent.String()).
+			Msg("Error parsing final JSON response")
+		return fmt.Errorf("invalid JSON response from %s: %w", target.Model, err)
 	}
 
-	log.Info().
-		Str("user_id", config.userID).
-		Msg("Finalizing streaming response (without tools)")
-	return c.finalizeStreamingResponse(config.userID, fullContent.String(), config.redisClient)
+	return nil
 }
 
 // handleToolCalls processes tool calls from the AI and returns updated messages
 func (c *Client) handleToolCalls(
 	ctx context.Context,
-	messages []openai.ChatCompletionMessageParamUnion,
-	userID string,
-) ([]openai.ChatCompletionMessageParamUnion, error) {
+	messages []llm.Message,
+	config streamingConfig,
+) ([]llm.Message, error) {
+	userID := config.userID
+
 	// Prepare tools for the request
-	tools := []openai.ChatCompletionToolParam{}
-	for _, tool := range c.tools {
-		tools = append(tools, tool.Definition)
-	}
+	disabled := c.disabledTools()
+	tools := c.toolDefinitions(disabled)
 
 	log.Info().
 		Str("user_id", userID).
@@ -357,17 +355,16 @@ func (c *Client) handleToolCalls(
 		Msg("Calling AI with custom tools")
 
 	// Make initial chat completion request with tools
-	completion, err := c.client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	completion, err := c.complete(ctx, userID, llm.Request{
 		Messages: messages,
 		Tools:    tools,
-		Model:    c.model,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completion with tools: %w", err)
 	}
 
 	// Check if there are any tool calls
-	if len(completion.Choices) == 0 || len(completion.Choices[0].Message.ToolCalls) == 0 {
+	if len(completion.ToolCalls) == 0 {
 		log.Info().
 			Str("user_id", userID).
 			Msg("No tool calls made, proceeding with streaming")
@@ -375,64 +372,81 @@ func (c *Client) handleToolCalls(
 	}
 
 	// Add the assistant's message with tool calls to the conversation
-	updatedMessages := append(messages, completion.Choices[0].Message.ToParam())
+	updatedMessages := append(messages, llm.AssistantToolCallMessage(completion.Content, completion.ToolCalls...))
 
 	// Process each tool call
-	for _, toolCall := range completion.Choices[0].Message.ToolCalls {
+	for _, toolCall := range completion.ToolCalls {
 		log.Info().
 			Str("user_id", userID).
-			Str("tool_name", toolCall.Function.Name).
+			Str("tool_name", toolCall.Name).
 			Str("tool_id", toolCall.ID).
 			Msg("Processing tool call")
 
-		// Find the tool handler
-		var handler ToolHandler
-		for _, tool := range c.tools {
-			if tool.Definition.Function.Name == toolCall.Function.Name {
-				handler = tool.Handler
-				break
-			}
+		// A tool disabled while the model was answering is refused rather than run
+		if disabled[toolCall.Name] {
+			log.Warn().
+				Str("user_id", userID).
+				Str("tool_name", toolCall.Name).
+				Msg("Model called a disabled tool")
+			updatedMessages = append(updatedMessages, llm.ToolMessage("Error: this tool is disabled", toolCall.ID))
+			continue
 		}
 
-		if handler == nil {
+		// Find the tool handler
+		tool, found := c.findTool(toolCall.Name)
+		handler := tool.Handler
+
+		if !found || handler == nil {
 			log.Error().
 				Str("user_id", userID).
-				Str("tool_name", toolCall.Function.Name).
+				Str("tool_name", toolCall.Name).
 				Msg("No handler found for tool")
 			continue
 		}
 
 		// Parse tool arguments
 		var args map[string]any
-		err := json.Unmarshal([]byte(toolCall.Function.Arguments), &args)
+		err := json.Unmarshal([]byte(toolCall.Arguments), &args)
 		if err != nil {
 			log.Error().
 				Err(err).
 				Str("user_id", userID).
-				Str("tool_name", toolCall.Function.Name).
+				Str("tool_name", toolCall.Name).
 				Msg("Failed to parse tool arguments")
 			continue
 		}
 
+		// Async tools run in a background worker; the model only receives the job ID
+		if tool.Async {
+			result := c.enqueueAsyncToolCall(config, toolCall.ID, toolCall.Name, toolCall.Arguments)
+			updatedMessages = append(updatedMessages, llm.ToolMessage(result, toolCall.ID))
+			continue
+		}
+
 		// Call the tool handler
-		result, err := handler(ctx, args)
+		toolCtx := WithToolCallInfo(ctx, ToolCallInfo{
+			Name:   toolCall.Name,
+			CallID: toolCall.ID,
+			UserID: userID,
+		})
+		result, err := handler(toolCtx, args)
 		if err != nil {
 			log.Error().
 				Err(err).
 				Str("user_id", userID).
-				Str("tool_name", toolCall.Function.Name).
+				Str("tool_name", toolCall.Name).
 				Msg("Tool handler returned error")
 			result = fmt.Sprintf("Error: %s", err.Error())
 		}
 
 		log.Info().
 			Str("user_id", userID).
-			Str("tool_name", toolCall.Function.Name).
+			Str("tool_name", toolCall.Name).
 			Str("result", result).
 			Msg("Tool call completed")
 
 		// Add the tool result to the conversation
-		updatedMessages = append(updatedMessages, openai.ToolMessage(result, toolCall.ID))
+		updatedMessages = append(updatedMessages, llm.ToolMessage(result, toolCall.ID))
 	}
 
 	return updatedMessages, nil
@@ -444,12 +458,13 @@ type messageWithIndex struct {
 	index   int
 }
 
-// sendMessagesSequentially processes messages from the queue one at a time to ensure ordering
+// sendMessagesSequentially processes messages from the queue one at a time to ensure ordering.
+// It returns the messages that were actually sent.
 func (c *Client) sendMessagesSequentially(
 	ctx context.Context,
 	config streamingConfig,
 	messageQueue <-chan messageWithIndex,
-) {
+) (sent []Message) {
 	isFirstMessage := true
 	messagesProcessed := 0
 
@@ -502,8 +517,8 @@ func (c *Client) sendMessagesSequentially(
 			}
 			isFirstMessage = false
 
-			// Enviar a mensagem
-			if msg.Type == "audio" {
+			// Enviar a mensagem; sem ElevenLabs ou S3 os áudios são enviados como texto
+			if msg.Type == "audio" && config.elevenLabsClient.CanSpeak() {
 				if err := c.sendAudioMessage(ctx, config, msg, messageIndex); err != nil {
 					log.Error().
 						Err(err).
@@ -512,6 +527,7 @@ func (c *Client) sendMessagesSequentially(
 						Msg("Failed to send audio message, continuing with next")
 				} else {
 					messagesProcessed++
+					sent = append(sent, msg)
 				}
 			} else {
 				if err := c.sendTextMessage(ctx, config, msg, messageIndex); err != nil {
@@ -522,6 +538,7 @@ func (c *Client) sendMessagesSequentially(
 						Msg("Failed to send text message, continuing with next")
 				} else {
 					messagesProcessed++
+					sent = append(sent, msg)
 				}
 			}
 
@@ -537,18 +554,25 @@ func (c *Client) sendMessagesSequentially(
 
 // Funções auxiliares para melhor organização e logs
 func (c *Client) sendAudioMessage(
-	_ context.Context,
+	ctx context.Context,
 	config streamingConfig,
 	msg Message,
 	messageIndex int,
-) error {
+) (err error) {
+	// A message already being sent is not interrupted when the turn is cancelled
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "sendAudioMessage",
+		attribute.Int("chatbot.message_index", messageIndex),
+		attribute.Int("chatbot.message_length", len(msg.Content)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	log.Info().
 		Str("user_id", config.userID).
 		Int("message_index", messageIndex).
 		Str("content", msg.Content).
 		Msg("Converting text to speech")
 
-	audioURL, err := config.elevenLabsClient.ConvertTextToSpeechDefault(msg.Content)
+	audioURL, err := config.elevenLabsClient.ConvertTextToSpeechDefault(ctx, msg.Content)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -558,6 +582,16 @@ func (c *Client) sendAudioMessage(
 			Msg("Error converting text to speech")
 		return err
 	}
+	c.recordSpeechUsage(config.userID, msg.Content)
+
+	// Generated audio is tracked per user so it can be exported and erased on request
+	if err := config.redisClient.AddUserAudio(config.userID, audioURL); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", config.userID).
+			Str("audio_url", audioURL).
+			Msg("Error recording generated audio")
+	}
 
 	log.Info().
 		Str("user_id", config.userID).
@@ -566,6 +600,7 @@ func (c *Client) sendAudioMessage(
 		Msg("Sending audio message to Vonage")
 
 	response, err := config.vonageClient.SendWhatsAppAudioMessage(
+		ctx,
 		config.toNumber,
 		audioURL,
 	)
@@ -587,15 +622,29 @@ func (c *Client) sendAudioMessage(
 		Int("message_index", messageIndex).
 		Msg("Successfully sent audio message via Vonage")
 
+	if err := config.redisClient.RecordOutboundMessage(config.userID, time.Now(), true, true); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", config.userID).
+			Msg("Error recording outbound message analytics")
+	}
+
 	return nil
 }
 
 func (c *Client) sendTextMessage(
-	_ context.Context,
+	ctx context.Context,
 	config streamingConfig,
 	msg Message,
 	messageIndex int,
-) error {
+) (err error) {
+	// A message already being sent is not interrupted when the turn is cancelled
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "sendTextMessage",
+		attribute.Int("chatbot.message_index", messageIndex),
+		attribute.Int("chatbot.message_length", len(msg.Content)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	log.Info().
 		Str("user_id", config.userID).
 		Int("message_index", messageIndex).
@@ -603,6 +652,7 @@ func (c *Client) sendTextMessage(
 		Msg("Sending text message to Vonage")
 
 	response, err := config.vonageClient.SendWhatsAppTextMessage(
+		ctx,
 		config.toNumber,
 		msg.Content,
 	)
@@ -624,67 +674,71 @@ func (c *Client) sendTextMessage(
 		Int("message_index", messageIndex).
 		Msg("Successfully sent text message via Vonage")
 
-	return nil
-}
-
-// finalizeStreamingResponse validates the final JSON response and stores it in Redis.
-// It ensures the complete response is properly formatted and saved for chat history.
-func (c *Client) finalizeStreamingResponse(
-	userID string,
-	fullContent string,
-	redisClient *redis.Client,
-) error {
-	log.Info().
-		Str("user_id", userID).
-		Int("content_length", len(fullContent)).
-		Msg("Finalizing streaming response - validating JSON")
-
-	// Debug: show the complete JSON being parsed
-	fmt.Printf("DEBUG: Complete JSON being parsed: %q\n", fullContent)
-
-	var messageList MessageList
-	if err := json.Unmarshal([]byte(fullContent), &messageList); err != nil {
+	if err := config.redisClient.RecordOutboundMessage(config.userID, time.Now(), false, true); err != nil {
 		log.Error().
 			Err(err).
-			Str("user_id", userID).
-			Str("content", fullContent).
-			Msg("Error parsing final JSON response")
-		return err
+			Str("user_id", config.userID).
+			Msg("Error recording outbound message analytics")
 	}
 
-	log.Info().
-		Str("user_id", userID).
-		Int("message_count", len(messageList.Messages)).
-		Msg("Successfully parsed JSON response")
+	return nil
+}
 
+// finalizeStreamingResponse stores the messages delivered to the user in the conversation store.
+// The messages are joined into a single bot message for chat history.
+// It runs detached from the request context so messages already sent are stored
+// even when the turn was cancelled.
+func (c *Client) finalizeStreamingResponse(
+	config streamingConfig,
+	messages []Message,
+	model string,
+) error {
+	userID := config.userID
 	allMessagesContent := []string{}
-	for i, msg := range messageList.Messages {
+	for i, msg := range messages {
 		allMessagesContent = append(allMessagesContent, msg.Content)
 		log.Debug().
 			Str("user_id", userID).
 			Int("message_index", i).
 			Str("content", msg.Content).
 			Str("type", msg.Type).
-			Msg("Processing message for Redis storage")
+			Msg("Processing message for history storage")
 	}
 	fullResponse := strings.Join(allMessagesContent, "\n\n")
 
 	log.Info().
 		Str("user_id", userID).
 		Int("final_response_length", len(fullResponse)).
-		Msg("Storing bot message in Redis")
+		Msg("Storing bot message in history")
 
-	if err := redisClient.AddBotMessage(userID, fullResponse); err != nil {
+	if c.conversationStore == nil {
+		return fmt.Errorf("no conversation store configured")
+	}
+	// The model and variant are kept with the message so routing decisions and
+	// experiments can be analyzed later
+	message := store.AssistantMessage(fullResponse)
+	message.Model = model
+	message.Variant = c.Variant(userID)
+	if err := c.conversationStore.Append(context.Background(), userID, message); err != nil {
 		log.Error().
 			Err(err).
 			Str("user_id", userID).
-			Msg("Error storing bot message in Redis")
+			Msg("Error storing bot message in history")
 		return err
 	}
 
+	if message.Variant != "" && config.redisClient != nil {
+		if err := config.redisClient.RecordVariantReply(message.Variant, userID, time.Now(), utf8.RuneCountInString(fullResponse)); err != nil {
+			log.Error().
+				Err(err).
+				Str("user_id", userID).
+				Msg("Error recording variant reply analytics")
+		}
+	}
+
 	log.Info().
 		Str("user_id", userID).
-		Msg("Successfully stored bot message in Redis")
+		Msg("Successfully stored bot message in history")
 
 	return nil
 }
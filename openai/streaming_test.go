@@ -0,0 +1,191 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/vonage"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newStreamingTest returns a client with fast retries against the fake LLM server, and a
+// config whose replies go to a fake Vonage API that records the texts sent
+func newStreamingTest(t *testing.T, server *llmtest.Server) (*Client, streamingConfig, func() []string) {
+	t.Helper()
+	redisClient, err := redis.Connect(miniredis.RunT(t).Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mutex sync.Mutex
+	var texts []string
+	vonageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message vonage.WhatsAppMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err == nil {
+			mutex.Lock()
+			texts = append(texts, message.Text)
+			mutex.Unlock()
+		}
+		w.Write([]byte(`{"message_uuid":"sent"}`))
+	}))
+	t.Cleanup(vonageServer.Close)
+	vonageClient := vonage.NewClient("jwt", vonageServer.URL, vonageServer.URL, "5511000000000", http.Client{})
+
+	client := NewClient(llm.NewOpenAICompatibleProvider(server.URL, "", http.Client{}), nil, nil, "test-model")
+	client.SetConversationStore(redis.NewHistoryStore(redisClient, 0))
+	client.SetRetryPolicy(llm.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}, nil)
+
+	config := streamingConfig{
+		userID:       "5511999999999",
+		vonageClient: &vonageClient,
+		redisClient:  &redisClient,
+		toNumber:     "5511999999999",
+	}
+	sent := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string{}, texts...)
+	}
+	return &client, config, sent
+}
+
+func TestStreamMessages_RetriesBeforeDelivery(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	broken := llmtest.OpenAIStream(`{"messages":[`, `{"content":"Oi","type":"text"}]}`)
+	broken.Events = broken.Events[:1]
+	broken.Truncate = true
+	server.Enqueue(broken, llmtest.OpenAIStream(`{"messages":[{"content":"Olá","type":"text"}]}`))
+
+	client, config, sent := newStreamingTest(t, server)
+	err := client.streamMessages(context.Background(), config, llm.Request{ResponseSchema: createSchemaParam()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(server.Requests()) != 2 {
+		t.Errorf("made %d requests, want a retry after a failure before any message", len(server.Requests()))
+	}
+	if texts := sent(); len(texts) != 1 || texts[0] != "Olá" {
+		t.Errorf("sent %q, want only the retried reply", texts)
+	}
+}
+
+func TestStreamMessages_ResumesAfterDelivery(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+
+	broken := llmtest.OpenAIStream(`{"messages":[{"content":"Oi","type":"text"},`, `{"content":"Tudo bem?"`)
+	broken.Events = broken.Events[:2]
+	broken.Truncate = true
+	server.Enqueue(broken, llmtest.OpenAIStream(`{"messages":[{"content":"Oi","type":"text"},{"content":"Como posso ajudar?","type":"text"}]}`))
+
+	client, config, sent := newStreamingTest(t, server)
+	err := client.streamMessages(context.Background(), config, llm.Request{ResponseSchema: createSchemaParam()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want a retry after the stream broke", len(requests))
+	}
+	if !strings.Contains(requests[1], `"role":"assistant"`) || !strings.Contains(requests[1], `\"content\":\"Oi\"`) {
+		t.Errorf("retry request = %s, want the message already sent passed back to the model", requests[1])
+	}
+	if texts := sent(); strings.Join(texts, "|") != "Oi|Como posso ajudar?" {
+		t.Errorf("sent %q, want the delivered message once and then the rest of the retried reply", texts)
+	}
+
+	history, err := client.conversationStore.Range(context.Background(), config.userID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Content != "Oi\n\nComo posso ajudar?" {
+		t.Errorf("history = %+v, want the whole delivered reply", history)
+	}
+}
+
+func TestStreamMessages_StoresOnlySentMessages(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.Enqueue(llmtest.OpenAIStream(`{"messages":[{"content":"Oi","type":"text"},{"content":"Falha","type":"text"},{"content":"Tchau","type":"text"}]}`))
+
+	client, config, _ := newStreamingTest(t, server)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message vonage.WhatsAppMessage
+		json.NewDecoder(r.Body).Decode(&message)
+		if message.Text == "Falha" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"message_uuid":"sent"}`))
+	}))
+	defer failing.Close()
+	vonageClient := vonage.NewClient("jwt", failing.URL, failing.URL, "5511000000000", http.Client{})
+	config.vonageClient = &vonageClient
+
+	if err := client.streamMessages(context.Background(), config, llm.Request{ResponseSchema: createSchemaParam()}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := client.conversationStore.Range(context.Background(), config.userID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Content != "Oi\n\nTchau" {
+		t.Errorf("history = %+v, want only the messages that were sent", history)
+	}
+}
+
+func TestStreamResponse_AnthropicRunsToolsBeforeSchema(t *testing.T) {
+	server := llmtest.NewServer()
+	defer server.Close()
+	server.Enqueue(
+		llmtest.AnthropicMessage("", [2]string{"get_time", `{"zone":"BRT"}`}),
+		llmtest.AnthropicToolInputStream("message_list", `{"messages":[{"content":"São 10h","type":"text"}]}`),
+	)
+
+	client, config, sent := newStreamingTest(t, server)
+	client.provider = llm.NewAnthropicProvider("test-key", server.URL, http.Client{})
+	client.tools = []Tool{{
+		Definition: llm.ToolDefinition{Name: "get_time"},
+		Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			return "10:00", nil
+		},
+	}}
+
+	if err := client.streamResponse(context.Background(), config, []llm.Message{llm.UserMessage("Que horas são?")}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("made %d requests, want the tool round and then the reply", len(requests))
+	}
+	var reply struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal([]byte(requests[1]), &reply); err != nil {
+		t.Fatal(err)
+	}
+	if len(reply.Tools) != 1 || reply.Tools[0].Name != "message_list" || !strings.Contains(requests[1], "10:00") {
+		t.Errorf("reply request = %s, want the tool result and only the schema tool", requests[1])
+	}
+	if texts := sent(); len(texts) != 1 || texts[0] != "São 10h" {
+		t.Errorf("sent %q, want the structured reply", texts)
+	}
+}
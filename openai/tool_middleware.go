@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+)
+
+// ToolMiddleware wraps a ToolHandler with cross-cutting behaviour such as
+// logging, caching or rate limiting. Middlewares are composed so that the
+// first one registered is the outermost wrapper.
+type ToolMiddleware func(ToolHandler) ToolHandler
+
+// ToolCallInfo describes the tool call currently being executed.
+// It is attached to the context passed to every tool handler so that
+// middlewares can identify the tool and the user that triggered it.
+type ToolCallInfo struct {
+	Name   string
+	CallID string
+	UserID string
+}
+
+type toolCallInfoKey struct{}
+
+// WithToolCallInfo returns a copy of ctx carrying the given tool call information.
+func WithToolCallInfo(ctx context.Context, info ToolCallInfo) context.Context {
+	return context.WithValue(ctx, toolCallInfoKey{}, info)
+}
+
+// ToolCallInfoFromContext returns the tool call information stored in ctx, if any.
+func ToolCallInfoFromContext(ctx context.Context) (ToolCallInfo, bool) {
+	info, ok := ctx.Value(toolCallInfoKey{}).(ToolCallInfo)
+	return info, ok
+}
+
+// ChainToolMiddleware composes the given middlewares into a single one.
+// The first middleware is the outermost, so it sees the call first and the result last.
+func ChainToolMiddleware(middlewares ...ToolMiddleware) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if middlewares[i] != nil {
+				next = middlewares[i](next)
+			}
+		}
+		return next
+	}
+}
+
+// ApplyToolMiddleware returns a copy of tools with the middlewares applied to every handler.
+// Global middlewares wrap the per-tool ones, which are looked up by function name.
+func ApplyToolMiddleware(tools []Tool, global []ToolMiddleware, perTool map[string][]ToolMiddleware) []Tool {
+	wrapped := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		middlewares := append([]ToolMiddleware{}, global...)
+		middlewares = append(middlewares, perTool[tool.Definition.Name]...)
+
+		if tool.Handler != nil {
+			tool.Handler = ChainToolMiddleware(middlewares...)(tool.Handler)
+		}
+		wrapped = append(wrapped, tool)
+	}
+	return wrapped
+}
@@ -0,0 +1,333 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/metrics"
+	"github.com/NextMind-AI/chatbot-go/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ToolResultCache stores tool results so repeated calls can be answered without running the tool.
+type ToolResultCache interface {
+	GetCachedToolResult(key string) (string, bool, error)
+	SetCachedToolResult(key, result string, ttl time.Duration) error
+}
+
+// ToolRateLimiter counts tool calls inside a fixed time window.
+type ToolRateLimiter interface {
+	IncrementToolCallCount(key string, window time.Duration) (int64, error)
+}
+
+// ToolUsageRecorder counts tool calls for the CRM analytics.
+type ToolUsageRecorder interface {
+	RecordToolCall(name string, at time.Time, failed bool) error
+}
+
+// Redactor masks sensitive data in tool arguments and results.
+// Keys lists argument names whose values are always replaced, and
+// Patterns lists expressions whose matches are replaced in any string.
+type Redactor struct {
+	Keys        []string
+	Patterns    []*regexp.Regexp
+	Replacement string
+}
+
+// DefaultRedactor returns a Redactor that masks CPF numbers, card numbers and e-mail addresses.
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Keys: []string{"password", "token", "secret", "cpf", "card_number"},
+		Patterns: []*regexp.Regexp{
+			regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`),
+			regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`),
+			regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+		},
+		Replacement: "[REDACTED]",
+	}
+}
+
+// RedactString replaces every pattern match in s.
+func (r *Redactor) RedactString(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, pattern := range r.Patterns {
+		s = pattern.ReplaceAllString(s, r.replacement())
+	}
+	return s
+}
+
+// RedactArgs returns a copy of args with sensitive keys and pattern matches masked.
+func (r *Redactor) RedactArgs(args map[string]any) map[string]any {
+	if r == nil {
+		return args
+	}
+	redacted := make(map[string]any, len(args))
+	for key, value := range args {
+		redacted[key] = r.redactValue(key, value)
+	}
+	return redacted
+}
+
+func (r *Redactor) redactValue(key string, value any) any {
+	for _, sensitive := range r.Keys {
+		if strings.EqualFold(key, sensitive) {
+			return r.replacement()
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return r.RedactString(v)
+	case map[string]any:
+		return r.RedactArgs(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = r.redactValue("", item)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+func (r *Redactor) replacement() string {
+	if r.Replacement == "" {
+		return "[REDACTED]"
+	}
+	return r.Replacement
+}
+
+// RecoveryToolMiddleware converts a panic inside a tool handler into an error,
+// so a misbehaving tool cannot kill the goroutine processing the conversation.
+func RecoveryToolMiddleware() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (result string, err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					info, _ := ToolCallInfoFromContext(ctx)
+					log.Error().
+						Str("user_id", info.UserID).
+						Str("tool_name", info.Name).
+						Interface("panic", recovered).
+						Str("stack", string(debug.Stack())).
+						Msg("Recovered from panic in tool handler")
+					result = ""
+					err = fmt.Errorf("tool %s panicked: %v", info.Name, recovered)
+				}
+			}()
+			return next(ctx, args)
+		}
+	}
+}
+
+type redactorKey struct{}
+
+// redactorFromContext returns the redactor set by an outer RedactionToolMiddleware, if any.
+func redactorFromContext(ctx context.Context) *Redactor {
+	redactor, _ := ctx.Value(redactorKey{}).(*Redactor)
+	return redactor
+}
+
+// LoggingToolMiddleware writes a structured audit log entry for every tool call.
+// Arguments and results are passed through redactor before being logged. A nil redactor
+// uses the one of an outer RedactionToolMiddleware, or logs them as-is without one.
+func LoggingToolMiddleware(redactor *Redactor) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+			start := time.Now()
+			logRedactor := redactor
+			if logRedactor == nil {
+				logRedactor = redactorFromContext(ctx)
+			}
+
+			result, err := next(ctx, args)
+
+			event := log.Info()
+			if err != nil {
+				event = log.Warn().Err(err)
+			}
+			event.
+				Str("audit", "tool_call").
+				Str("user_id", info.UserID).
+				Str("tool_name", info.Name).
+				Str("tool_id", info.CallID).
+				Interface("args", logRedactor.RedactArgs(args)).
+				Str("result", logRedactor.RedactString(result)).
+				Dur("duration", time.Since(start)).
+				Msg("Tool call audited")
+
+			return result, err
+		}
+	}
+}
+
+// RedactionToolMiddleware masks sensitive data in tool results before they reach the model.
+// The handler still receives the arguments unmasked, but the middlewares inside this one
+// log them masked: LoggingToolMiddleware without a redactor of its own uses this one.
+func RedactionToolMiddleware(redactor *Redactor) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			result, err := next(context.WithValue(ctx, redactorKey{}, redactor), args)
+			return redactor.RedactString(result), err
+		}
+	}
+}
+
+// CacheToolMiddleware caches successful tool results per user, tool and arguments for ttl.
+// Cache failures are logged and never prevent the tool from running.
+func CacheToolMiddleware(cache ToolResultCache, ttl time.Duration) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+
+			key, err := toolCacheKey(info, args)
+			if err != nil {
+				return next(ctx, args)
+			}
+
+			if cached, ok, err := cache.GetCachedToolResult(key); err != nil {
+				log.Warn().
+					Err(err).
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Msg("Error reading cached tool result")
+			} else if ok {
+				log.Info().
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Msg("Serving tool result from cache")
+				return cached, nil
+			}
+
+			result, err := next(ctx, args)
+			if err != nil {
+				return result, err
+			}
+
+			if err := cache.SetCachedToolResult(key, result, ttl); err != nil {
+				log.Warn().
+					Err(err).
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Msg("Error caching tool result")
+			}
+
+			return result, nil
+		}
+	}
+}
+
+// toolCacheKey builds a stable cache key from the tool name, user and arguments.
+// json.Marshal sorts map keys, so equal arguments always produce the same key.
+func toolCacheKey(info ToolCallInfo, args map[string]any) (string, error) {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(argsJSON)
+	return fmt.Sprintf("%s:%s:%s", info.Name, info.UserID, hex.EncodeToString(sum[:])), nil
+}
+
+// RateLimitToolMiddleware allows at most limit calls per user and tool in each window.
+// Calls over the limit return an error, which is reported to the model as the tool result.
+func RateLimitToolMiddleware(limiter ToolRateLimiter, limit int, window time.Duration) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+
+			windowStart := time.Now().Truncate(window).Unix()
+			key := fmt.Sprintf("%s:%s:%d", info.Name, info.UserID, windowStart)
+
+			count, err := limiter.IncrementToolCallCount(key, window)
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Msg("Error checking tool rate limit, allowing call")
+				return next(ctx, args)
+			}
+
+			if count > int64(limit) {
+				log.Warn().
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Int64("count", count).
+					Int("limit", limit).
+					Msg("Tool rate limit exceeded")
+				return "", fmt.Errorf("rate limit exceeded for tool %s, try again later", info.Name)
+			}
+
+			return next(ctx, args)
+		}
+	}
+}
+
+// UsageToolMiddleware counts every tool call and whether it returned an error.
+// Recording failures are logged and never affect the tool result.
+func UsageToolMiddleware(recorder ToolUsageRecorder) ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+
+			result, err := next(ctx, args)
+
+			if recordErr := recorder.RecordToolCall(info.Name, time.Now(), err != nil); recordErr != nil {
+				log.Warn().
+					Err(recordErr).
+					Str("user_id", info.UserID).
+					Str("tool_name", info.Name).
+					Msg("Error recording tool usage")
+			}
+
+			return result, err
+		}
+	}
+}
+
+// MetricsToolMiddleware records the duration of every tool call and counts the calls that failed.
+func MetricsToolMiddleware() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (string, error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+			start := time.Now()
+
+			result, err := next(ctx, args)
+
+			metrics.ToolCallDuration.WithLabelValues(info.Name).Observe(metrics.Since(start))
+			if err != nil {
+				metrics.ToolCallErrors.WithLabelValues(info.Name).Inc()
+			}
+			return result, err
+		}
+	}
+}
+
+// TracingToolMiddleware records every tool call as a span of the conversation turn's trace.
+func TracingToolMiddleware() ToolMiddleware {
+	return func(next ToolHandler) ToolHandler {
+		return func(ctx context.Context, args map[string]any) (result string, err error) {
+			info, _ := ToolCallInfoFromContext(ctx)
+			ctx, span := tracing.Start(ctx, "tool "+info.Name,
+				attribute.String("chatbot.tool_name", info.Name),
+				attribute.String("chatbot.tool_call_id", info.CallID),
+			)
+			defer func() { tracing.End(span, err) }()
+
+			return next(ctx, args)
+		}
+	}
+}
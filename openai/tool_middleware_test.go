@@ -0,0 +1,148 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestChainToolMiddleware_Order(t *testing.T) {
+	var calls []string
+	trace := func(name string) ToolMiddleware {
+		return func(next ToolHandler) ToolHandler {
+			return func(ctx context.Context, args map[string]any) (string, error) {
+				calls = append(calls, name+" in")
+				result, err := next(ctx, args)
+				calls = append(calls, name+" out")
+				return result, err
+			}
+		}
+	}
+	handler := func(ctx context.Context, args map[string]any) (string, error) {
+		calls = append(calls, "handler")
+		return "ok", nil
+	}
+
+	tools := ApplyToolMiddleware(
+		[]Tool{{Definition: llm.ToolDefinition{Name: "lookup"}, Handler: handler}},
+		[]ToolMiddleware{trace("first"), nil, trace("second")},
+		map[string][]ToolMiddleware{"lookup": {trace("per-tool")}, "other": {trace("other")}},
+	)
+	if _, err := tools[0].Handler(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "first in,second in,per-tool in,handler,per-tool out,second out,first out"
+	if got := strings.Join(calls, ","); got != want {
+		t.Fatalf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestRecoveryToolMiddleware(t *testing.T) {
+	handler := RecoveryToolMiddleware()(func(ctx context.Context, args map[string]any) (string, error) {
+		panic("boom")
+	})
+
+	ctx := WithToolCallInfo(context.Background(), ToolCallInfo{Name: "lookup", UserID: "5511"})
+	result, err := handler(ctx, nil)
+	if err == nil || !strings.Contains(err.Error(), "lookup panicked: boom") {
+		t.Fatalf("err = %v, want the panic as an error", err)
+	}
+	if result != "" {
+		t.Fatalf("result = %q, want empty", result)
+	}
+}
+
+type memoryToolCache map[string]string
+
+func (c memoryToolCache) GetCachedToolResult(key string) (string, bool, error) {
+	result, ok := c[key]
+	return result, ok, nil
+}
+
+func (c memoryToolCache) SetCachedToolResult(key, result string, ttl time.Duration) error {
+	c[key] = result
+	return nil
+}
+
+func TestCacheToolMiddleware(t *testing.T) {
+	runs := 0
+	handler := CacheToolMiddleware(memoryToolCache{}, time.Minute)(func(ctx context.Context, args map[string]any) (string, error) {
+		runs++
+		if args["city"] == "fail" {
+			return "", errors.New("unavailable")
+		}
+		return "sunny in " + args["city"].(string), nil
+	})
+	call := func(userID, city string) (string, error) {
+		ctx := WithToolCallInfo(context.Background(), ToolCallInfo{Name: "get_weather", UserID: userID})
+		return handler(ctx, map[string]any{"city": city})
+	}
+
+	for range 2 {
+		if result, _ := call("5511", "Recife"); result != "sunny in Recife" {
+			t.Fatalf("result = %q", result)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("tool ran %d times, want a cache hit on the second call", runs)
+	}
+
+	// Other arguments and other users miss the cache
+	call("5511", "Natal")
+	call("5522", "Recife")
+	if runs != 3 {
+		t.Fatalf("tool ran %d times, want 3", runs)
+	}
+
+	// Errors are not cached
+	call("5511", "fail")
+	if _, err := call("5511", "fail"); err == nil || runs != 5 {
+		t.Fatalf("err = %v after %d runs, want failed calls to run again", err, runs)
+	}
+}
+
+func TestRedactionToolMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&logs)
+	t.Cleanup(func() { log.Logger = logger })
+
+	var received map[string]any
+	handler := ApplyToolMiddleware(
+		[]Tool{{Definition: llm.ToolDefinition{Name: "find_customer"}, Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			received = args
+			return "Cliente ana@example.com, CPF 123.456.789-09", nil
+		}}},
+		[]ToolMiddleware{RedactionToolMiddleware(DefaultRedactor()), LoggingToolMiddleware(nil)},
+		nil,
+	)[0].Handler
+
+	result, err := handler(context.Background(), map[string]any{"cpf": "12345678909", "note": "email ana@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received["cpf"] != "12345678909" {
+		t.Errorf("handler received %v, want the arguments unmasked", received)
+	}
+	if result != "Cliente [REDACTED], CPF [REDACTED]" {
+		t.Errorf("result = %q, want it masked", result)
+	}
+	for _, secret := range []string{"12345678909", "123.456.789-09", "ana@example.com"} {
+		if strings.Contains(logs.String(), secret) {
+			t.Errorf("log contains %q: %s", secret, logs.String())
+		}
+	}
+	if !strings.Contains(logs.String(), "Tool call audited") {
+		t.Errorf("tool call not logged: %s", logs.String())
+	}
+}
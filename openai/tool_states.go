@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"github.com/rs/zerolog/log"
+)
+
+// ToolStateReader reads which registered tools are switched off at runtime.
+type ToolStateReader interface {
+	GetDisabledTools() ([]string, error)
+}
+
+// SetToolStates makes the client leave out of each turn the tools states reports as disabled.
+func (c *Client) SetToolStates(states ToolStateReader) {
+	c.toolStates = states
+}
+
+// Tools returns the registered tools, including the disabled ones.
+func (c *Client) Tools() []Tool {
+	return c.tools
+}
+
+// disabledTools returns the names of the tools switched off at runtime. When they cannot
+// be read every tool is offered, so a Redis failure doesn't take the tools away.
+func (c *Client) disabledTools() map[string]bool {
+	if c.toolStates == nil {
+		return nil
+	}
+	names, err := c.toolStates.GetDisabledTools()
+	if err != nil {
+		log.Error().Err(err).Msg("Error loading disabled tools")
+		return nil
+	}
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return disabled
+}
@@ -1,24 +1,22 @@
 package openai
 
 import (
-	"github.com/openai/openai-go"
+	"github.com/NextMind-AI/chatbot-go/llm"
 )
 
 // sleepTool defines the sleep tool that allows the AI to pause conversation for a specified duration.
 // This tool can be used when the AI needs to simulate waiting or processing time.
-var sleepTool = openai.ChatCompletionToolParam{
-	Function: openai.FunctionDefinitionParam{
-		Name:        "sleep",
-		Description: openai.String("Wait for a specified number of seconds before continuing the conversation"),
-		Parameters: openai.FunctionParameters{
-			"type": "object",
-			"properties": map[string]any{
-				"seconds": map[string]string{
-					"type":        "integer",
-					"description": "Number of seconds to wait",
-				},
+var sleepTool = llm.ToolDefinition{
+	Name:        "sleep",
+	Description: "Wait for a specified number of seconds before continuing the conversation",
+	Parameters: map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"seconds": map[string]string{
+				"type":        "integer",
+				"description": "Number of seconds to wait",
 			},
-			"required": []string{"seconds"},
 		},
+		"required": []string{"seconds"},
 	},
 }
@@ -1,8 +1,9 @@
 package openai
 
 import (
+	"github.com/NextMind-AI/chatbot-go/llm"
+
 	"github.com/invopop/jsonschema"
-	"github.com/openai/openai-go"
 )
 
 // Message represents a single message in the chat conversation.
@@ -35,16 +36,16 @@ func GenerateSchema[T any]() any {
 }
 
 // MessageListResponseSchema is the pre-generated JSON schema for MessageList.
-// This schema is used to enforce structured output from OpenAI's API.
+// This schema is used to enforce structured output from the model.
 var MessageListResponseSchema = GenerateSchema[MessageList]()
 
-// createSchemaParam creates an OpenAI schema parameter for structured output.
+// createSchemaParam creates the structured output schema for a request.
 // This ensures the AI responds with a properly formatted MessageList.
-func createSchemaParam() openai.ResponseFormatJSONSchemaJSONSchemaParam {
-	return openai.ResponseFormatJSONSchemaJSONSchemaParam{
+func createSchemaParam() *llm.JSONSchema {
+	return &llm.JSONSchema{
 		Name:        "message_list",
-		Description: openai.String("A list of messages to send to the user"),
+		Description: "A list of messages to send to the user",
 		Schema:      MessageListResponseSchema,
-		Strict:      openai.Bool(true),
+		Strict:      true,
 	}
 }
@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/llm"
+)
+
+// UsageRecorder accounts the tokens and speech characters consumed on behalf of each user.
+type UsageRecorder interface {
+	RecordTokens(userID, model string, usage llm.Usage)
+	RecordCharacters(userID, model string, characters int)
+}
+
+// SetUsageRecorder makes the client report the usage of every completion, including the
+// sleep analysis, tool rounds and summaries, and of every text converted to speech.
+func (c *Client) SetUsageRecorder(recorder UsageRecorder) {
+	c.usageRecorder = recorder
+}
+
+// recordUsage records the tokens a provider reported for a request made for the user.
+func (c *Client) recordUsage(userID string, provider llm.Provider, model string, usage llm.Usage) {
+	observeLLMUsage(provider, model, usage)
+	if c.usageRecorder != nil {
+		c.usageRecorder.RecordTokens(userID, model, usage)
+	}
+}
+
+// recordSpeechUsage records the characters converted to speech for the user.
+func (c *Client) recordSpeechUsage(userID, text string) {
+	if c.usageRecorder != nil {
+		c.usageRecorder.RecordCharacters(userID, elevenlabs.ModelID, len([]rune(text)))
+	}
+}
@@ -0,0 +1,100 @@
+package chatbot
+
+import (
+	"net/http"
+
+	"github.com/NextMind-AI/chatbot-go/aws"
+	"github.com/NextMind-AI/chatbot-go/config"
+	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/redis"
+)
+
+// Settings are the bot's settings otherwise read from the config file and the environment (using the config package type)
+type Settings = config.Config
+
+// DefaultSettings returns the settings used for whatever the config file and the environment leave out
+func DefaultSettings() *Settings {
+	return config.Default()
+}
+
+// Option customizes how New and Build create the chatbot
+type Option func(*options)
+
+type options struct {
+	configFile        string
+	settings          *Settings
+	overrides         []func(*Settings)
+	httpClient        *http.Client
+	redisClient       *redis.Client
+	provider          LLMProvider
+	conversationStore ConversationStore
+	awsClient         *aws.Client
+	elevenLabsClient  *elevenlabs.Client
+}
+
+// WithConfigFile reads the settings from a YAML file instead of the one named by CONFIG_FILE.
+// Environment variables still override the file.
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithSettings uses the given settings instead of reading the config file and the environment.
+// Start from DefaultSettings; the settings are validated like the loaded ones.
+func WithSettings(settings Settings) Option {
+	return func(o *options) {
+		o.settings = &settings
+	}
+}
+
+// WithSettingsOverride changes the settings after they are loaded and before they are validated
+func WithSettingsOverride(override func(*Settings)) Option {
+	return func(o *options) {
+		o.overrides = append(o.overrides, override)
+	}
+}
+
+// WithHTTPClient sends the requests to Vonage, ElevenLabs and the LLM provider with the client
+func WithHTTPClient(httpClient http.Client) Option {
+	return func(o *options) {
+		o.httpClient = &httpClient
+	}
+}
+
+// WithRedisClient uses the client instead of connecting to REDIS_ADDR
+func WithRedisClient(redisClient redis.Client) Option {
+	return func(o *options) {
+		o.redisClient = &redisClient
+	}
+}
+
+// WithProvider uses the LLM provider instead of the one selected by LLM_PROVIDER,
+// like Config.Provider; no LLM credentials are needed then
+func WithProvider(provider LLMProvider) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// WithConversationStore stores the chat history in the store, like Config.ConversationStore
+func WithConversationStore(conversationStore ConversationStore) Option {
+	return func(o *options) {
+		o.conversationStore = conversationStore
+	}
+}
+
+// WithAWSClient stores the generated audio with the client instead of one created from the AWS_* settings
+func WithAWSClient(awsClient *aws.Client) Option {
+	return func(o *options) {
+		o.awsClient = awsClient
+	}
+}
+
+// WithElevenLabsClient transcribes and generates audio with the client instead of one
+// created from the ELEVENLABS_* settings. Its AWSClient serves the generated audio.
+func WithElevenLabsClient(elevenLabsClient elevenlabs.Client) Option {
+	return func(o *options) {
+		o.elevenLabsClient = &elevenLabsClient
+	}
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// recordInboundMessage adds a message received from the user to the CRM analytics,
+// and to their experiment variant's.
+func (mp *MessageProcessor) recordInboundMessage(userID string, audio bool) {
+	if err := mp.redisClient.RecordInboundMessage(userID, time.Now(), audio); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error recording inbound message analytics")
+	}
+	if variant := mp.openaiClient.Variant(userID); variant != "" {
+		if err := mp.redisClient.RecordVariantMessage(variant, userID, time.Now()); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Error recording variant message analytics")
+		}
+	}
+}
+
+// recordOutboundMessage adds a message sent to the user to the CRM analytics.
+func (mp *MessageProcessor) recordOutboundMessage(userID string, fromBot bool) {
+	if err := mp.redisClient.RecordOutboundMessage(userID, time.Now(), false, fromBot); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error recording outbound message analytics")
+	}
+}
+
+// recordEscalation adds an escalation to the CRM analytics, and to the user's experiment variant's.
+func (mp *MessageProcessor) recordEscalation(userID string) {
+	if err := mp.redisClient.RecordEscalation(userID, time.Now()); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error recording escalation analytics")
+	}
+	if variant := mp.openaiClient.Variant(userID); variant != "" {
+		if err := mp.redisClient.RecordVariantEscalation(variant, userID, time.Now()); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Error recording variant escalation analytics")
+		}
+	}
+}
+
+// Analytics returns the aggregated CRM analytics of each period.
+func (mp *MessageProcessor) Analytics(periods []redis.AnalyticsPeriod) ([]redis.Analytics, error) {
+	return mp.redisClient.GetAnalytics(periods)
+}
@@ -1,13 +1,14 @@
 package processor
 
 import (
+	"context"
 	"errors"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 )
 
-func (mp *MessageProcessor) extractMessageContent(message InboundMessage) (*ProcessedMessage, error) {
+func (mp *MessageProcessor) extractMessageContent(ctx context.Context, message InboundMessage) (*ProcessedMessage, error) {
 	var messageText string
 	var err error
 
@@ -15,12 +16,16 @@ func (mp *MessageProcessor) extractMessageContent(message InboundMessage) (*Proc
 	case "text":
 		messageText = message.Text
 	case "audio":
-		messageText, err = mp.transcribeAudio(message.Audio.URL)
+		// Without ElevenLabs credentials audio messages are answered like other unsupported types
+		if !mp.elevenLabsClient.CanTranscribe() {
+			return nil, mp.handleUnsupportedMessageType(ctx, message)
+		}
+		messageText, err = mp.transcribeAudio(ctx, message.Audio.URL)
 		if err != nil {
 			return nil, err
 		}
 	default:
-		return nil, mp.handleUnsupportedMessageType(message)
+		return nil, mp.handleUnsupportedMessageType(ctx, message)
 	}
 
 	finalMessageText := strings.TrimSpace(messageText)
@@ -37,17 +42,20 @@ func (mp *MessageProcessor) extractMessageContent(message InboundMessage) (*Proc
 	}, nil
 }
 
-func (mp *MessageProcessor) transcribeAudio(audioURL string) (string, error) {
-	return mp.elevenLabsClient.TranscribeAudio(audioURL)
+// External calls are not interrupted when the turn is cancelled, the cancellation is
+// checked between steps
+func (mp *MessageProcessor) transcribeAudio(ctx context.Context, audioURL string) (string, error) {
+	return mp.elevenLabsClient.TranscribeAudio(context.WithoutCancel(ctx), audioURL)
 }
 
-func (mp *MessageProcessor) handleUnsupportedMessageType(message InboundMessage) error {
+func (mp *MessageProcessor) handleUnsupportedMessageType(ctx context.Context, message InboundMessage) error {
 	log.Warn().
 		Str("message_type", message.MessageType).
 		Str("message_uuid", message.MessageUUID).
 		Msg("Unsupported message type")
 
 	_, err := mp.vonageClient.SendWhatsAppReplyMessage(
+		context.WithoutCancel(ctx),
 		message.From,
 		"I can't process this message type for now",
 		message.MessageUUID,
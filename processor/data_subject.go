@@ -0,0 +1,261 @@
+package processor
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/rs/zerolog/log"
+)
+
+// erasureTimeout bounds an erasure. While it runs, messages from the user are dropped.
+const erasureTimeout = 5 * time.Minute
+
+// ErrErasureInProgress is returned when the user's data is already being erased.
+var ErrErasureInProgress = errors.New("an erasure of the user's data is already in progress")
+
+// AudioDeleter deletes generated audio from storage. It is implemented by *aws.Client.
+type AudioDeleter interface {
+	DeleteAudio(ctx context.Context, audioURL string) error
+}
+
+// SetAudioDeleter sets where generated audio is deleted from on erasure. Without it the
+// audio URLs are exported but the objects are not erased.
+func (mp *MessageProcessor) SetAudioDeleter(deleter AudioDeleter) {
+	mp.audioDeleter = deleter
+}
+
+// UserDataExport is everything the bot holds about a user, for data access requests.
+type UserDataExport struct {
+	UserID            string                   `json:"user_id"`
+	ExportedAt        time.Time                `json:"exported_at"`
+	Messages          []store.ChatMessage      `json:"messages"`
+	HistorySummary    string                   `json:"history_summary,omitempty"`
+	Memories          []redis.Memory           `json:"memories"`
+	Tags              []string                 `json:"tags"`
+	Notes             []redis.Note             `json:"notes"`
+	Attributes        map[string]string        `json:"attributes"`
+	DeliveryStatuses  []redis.DeliveryStatus   `json:"delivery_statuses"`
+	Escalations       []redis.Escalation       `json:"escalations"`
+	ToolJobs          []redis.ToolJob          `json:"tool_jobs"`
+	CachedToolResults []redis.CachedToolResult `json:"cached_tool_results"`
+	AudioURLs         []string                 `json:"audio_urls"`
+}
+
+// ExportUserData collects everything the bot holds about the user.
+func (mp *MessageProcessor) ExportUserData(userID string) (UserDataExport, error) {
+	export := UserDataExport{UserID: userID, ExportedAt: time.Now()}
+
+	var err error
+	if export.Messages, err = store.History(context.Background(), mp.conversationStore, userID); err != nil {
+		return UserDataExport{}, err
+	}
+	summary, ok, err := mp.openaiClient.HistorySummary(userID)
+	if err != nil {
+		return UserDataExport{}, err
+	}
+	if ok {
+		export.HistorySummary = summary.Summary
+	}
+	if export.Memories, err = mp.redisClient.GetUserMemories(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.Tags, err = mp.redisClient.GetConversationTags(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.Notes, err = mp.redisClient.GetConversationNotes(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.Attributes, err = mp.redisClient.GetConversationAttributes(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.DeliveryStatuses, err = mp.redisClient.GetDeliveryStatuses(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.Escalations, err = mp.redisClient.GetUserEscalations(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.ToolJobs, err = mp.redisClient.GetUserToolJobs(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.CachedToolResults, err = mp.redisClient.GetUserCachedToolResults(userID); err != nil {
+		return UserDataExport{}, err
+	}
+	if export.AudioURLs, err = mp.redisClient.GetUserAudio(userID); err != nil {
+		return UserDataExport{}, err
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Int("messages", len(export.Messages)).
+		Int("audio", len(export.AudioURLs)).
+		Msg("User data exported")
+
+	return export, nil
+}
+
+// WriteCSV writes the export as a single CSV table, one row per record, with the
+// columns type, timestamp, name, value and id.
+func (e UserDataExport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	rows := [][]string{{"type", "timestamp", "name", "value", "id"}}
+	for i, message := range e.Messages {
+		id := message.MessageUUID
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		rows = append(rows, []string{"message", formatTime(message.Timestamp), message.Role, message.Content, id})
+	}
+	if e.HistorySummary != "" {
+		rows = append(rows, []string{"history_summary", "", "", e.HistorySummary, ""})
+	}
+	for _, memory := range e.Memories {
+		rows = append(rows, []string{"memory", formatTime(memory.CreatedAt), memory.Source, memory.Content, memory.ID})
+	}
+	for _, tag := range e.Tags {
+		rows = append(rows, []string{"tag", "", "", tag, ""})
+	}
+	for _, note := range e.Notes {
+		rows = append(rows, []string{"note", formatTime(note.CreatedAt), note.Author, note.Content, note.ID})
+	}
+	for _, key := range slices.Sorted(maps.Keys(e.Attributes)) {
+		rows = append(rows, []string{"attribute", "", key, e.Attributes[key], ""})
+	}
+	for _, status := range e.DeliveryStatuses {
+		rows = append(rows, []string{"delivery_status", formatTime(status.Timestamp), status.Status, status.Error, status.MessageUUID})
+	}
+	for _, escalation := range e.Escalations {
+		rows = append(rows, []string{"escalation", formatTime(escalation.CreatedAt), escalation.Status, escalation.Reason, escalation.ID})
+	}
+	for _, job := range e.ToolJobs {
+		rows = append(rows, []string{"tool_call", formatTime(job.CreatedAt), job.ToolName, job.Arguments, job.ID})
+		if job.Result != "" || job.Error != "" {
+			rows = append(rows, []string{"tool_result", formatTime(job.UpdatedAt), job.Status, job.Result + job.Error, job.ID})
+		}
+	}
+	for _, cached := range e.CachedToolResults {
+		rows = append(rows, []string{"cached_tool_result", "", cached.Tool, cached.Result, ""})
+	}
+	for _, audioURL := range e.AudioURLs {
+		rows = append(rows, []string{"audio", "", "", audioURL, ""})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// ErasureReport describes what an erasure deleted.
+type ErasureReport struct {
+	UserID          string    `json:"user_id"`
+	ErasedAt        time.Time `json:"erased_at"`
+	MessagesDeleted int       `json:"messages_deleted"`
+	AudioDeleted    int       `json:"audio_deleted"`
+	// AudioFailed are the audio URLs that could not be deleted; they are kept so the erasure can be retried
+	AudioFailed []string `json:"audio_failed,omitempty"`
+}
+
+// EraseUserData deletes everything the bot holds about the user: the conversation and
+// its indexes, memories, tags, notes, attributes, delivery statuses, escalations, async
+// tool jobs, cached tool results and the generated audio. The audit log is kept. Messages
+// from the user are dropped while the erasure runs, and any reply being generated is
+// cancelled and waited for first, so nothing is stored for the user once the erasure has
+// started.
+func (mp *MessageProcessor) EraseUserData(userID string) (ErasureReport, error) {
+	report := ErasureReport{UserID: userID}
+
+	started, err := mp.redisClient.BeginErasure(userID, erasureTimeout)
+	if err != nil {
+		return ErasureReport{}, err
+	}
+	if !started {
+		return ErasureReport{}, ErrErasureInProgress
+	}
+	defer func() {
+		if err := mp.redisClient.EndErasure(userID); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Error ending erasure")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), erasureTimeout)
+	defer cancel()
+	if err := mp.executionManager.Stop(ctx, userID); err != nil {
+		return ErasureReport{}, fmt.Errorf("waiting for the reply being generated: %w", err)
+	}
+
+	messages, err := store.History(ctx, mp.conversationStore, userID)
+	if err != nil {
+		return ErasureReport{}, err
+	}
+	report.MessagesDeleted = len(messages)
+
+	audioURLs, err := mp.redisClient.GetUserAudio(userID)
+	if err != nil {
+		return ErasureReport{}, err
+	}
+	for _, audioURL := range audioURLs {
+		if mp.audioDeleter == nil {
+			report.AudioFailed = append(report.AudioFailed, audioURL)
+			continue
+		}
+		if err := mp.audioDeleter.DeleteAudio(ctx, audioURL); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Str("audio_url", audioURL).Msg("Error deleting audio")
+			report.AudioFailed = append(report.AudioFailed, audioURL)
+			continue
+		}
+		report.AudioDeleted++
+	}
+
+	if err := mp.conversationStore.Delete(ctx, userID); err != nil {
+		return ErasureReport{}, err
+	}
+	if err := mp.redisClient.EraseUserData(userID); err != nil {
+		return ErasureReport{}, err
+	}
+	for _, audioURL := range report.AudioFailed {
+		if err := mp.redisClient.AddUserAudio(userID, audioURL); err != nil {
+			return ErasureReport{}, err
+		}
+	}
+	report.ErasedAt = time.Now()
+
+	log.Warn().
+		Str("user_id", userID).
+		Int("messages_deleted", report.MessagesDeleted).
+		Int("audio_deleted", report.AudioDeleted).
+		Int("audio_failed", len(report.AudioFailed)).
+		Msg("User data erased")
+
+	return report, nil
+}
+
+// erasing reports whether the user's data is being erased, in which case nothing must be
+// stored for the user.
+func (mp *MessageProcessor) erasing(userID string) bool {
+	erasing, err := mp.redisClient.ErasureInProgress(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error checking erasure")
+		return false
+	}
+	if erasing {
+		log.Warn().Str("user_id", userID).Msg("User data is being erased, dropping message")
+	}
+	return erasing
+}
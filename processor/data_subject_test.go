@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/execution"
+	"github.com/NextMind-AI/chatbot-go/openai"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+	"github.com/NextMind-AI/chatbot-go/vonage"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+type fakeAudioDeleter struct{}
+
+func (fakeAudioDeleter) DeleteAudio(ctx context.Context, audioURL string) error { return nil }
+
+// seedUserData stores something about the user in every key family the bot writes
+func seedUserData(t *testing.T, mp *MessageProcessor, userID string) {
+	t.Helper()
+	client := mp.redisClient
+	now := time.Now()
+	ctx := context.Background()
+
+	for _, message := range []store.ChatMessage{store.UserMessage("Meu CPF é 123.456.789-09", "msg-1"), store.AssistantMessage("Anotado, obrigado")} {
+		if err := mp.conversationStore.Append(ctx, userID, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	steps := []error{
+		client.SaveHistorySummary(userID, redis.HistorySummary{Summary: "Cliente informou o CPF", MessageCount: 2, LastMessageTime: now}),
+		client.ScheduleMemoryExtraction(userID, now.Add(time.Hour)),
+		client.SetMemoryExtractedAt(userID, now),
+		client.AddConversationTags(userID, "lead"),
+		client.SetConversationAttributes(userID, map[string]string{"cidade": "Recife"}),
+		client.SaveDeliveryStatus(userID, redis.DeliveryStatus{MessageUUID: "msg-1", Status: "delivered", Timestamp: now}),
+		client.PauseBot(userID, redis.BotPause{Reason: "teste", PausedAt: now}, 0),
+		client.AddUserAudio(userID, "https://bucket.s3.amazonaws.com/"+userID+".mp3"),
+		client.RecordInboundMessage(userID, now, false),
+		client.RecordUsage("acme", userID, now.Format("2006-01-02"), redis.UsageRecord{Model: "test-model", PromptTokens: 10, CostNanos: 100}),
+		client.RecordAudit(redis.AuditEntry{Actor: "ana", Action: "export_data", UserID: userID, Timestamp: now}),
+		client.EnqueueToolJob(redis.ToolJob{ID: "job-" + userID, UserID: userID, ToolName: "consultar_cpf", Arguments: `{"cpf":"123.456.789-09"}`}),
+		client.SetCachedToolResult("consultar_cpf:"+userID+":abc123", "CPF regular", time.Hour),
+	}
+	if _, err := client.IncrementToolCallCount("consultar_cpf:"+userID+":1", time.Minute); err != nil {
+		steps = append(steps, err)
+	}
+	if _, err := client.AcquireHistorySummaryLock(userID, time.Minute); err != nil {
+		steps = append(steps, err)
+	}
+	if _, err := client.AddUserMemory(userID, "Mora em Recife", "conversation"); err != nil {
+		steps = append(steps, err)
+	}
+	if _, err := client.AddConversationNote(userID, "ana", "Ligar amanhã"); err != nil {
+		steps = append(steps, err)
+	}
+	if _, err := client.AddEscalation(userID, "pediu atendente", "high"); err != nil {
+		steps = append(steps, err)
+	}
+	for _, err := range steps {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// leftovers returns the keys whose name or content still mention the user,
+// other than the audit log, which is kept on purpose
+func leftovers(t *testing.T, server *miniredis.Miniredis, userID string) []string {
+	t.Helper()
+	var found []string
+	for _, key := range server.Keys() {
+		if strings.Contains(key, "crm_audit") {
+			continue
+		}
+		var values []string
+		switch server.Type(key) {
+		case "string":
+			value, _ := server.Get(key)
+			values = []string{value}
+		case "list":
+			values, _ = server.List(key)
+		case "set":
+			values, _ = server.Members(key)
+		case "zset":
+			values, _ = server.ZMembers(key)
+		case "hash":
+			fields, _ := server.HKeys(key)
+			for _, field := range fields {
+				values = append(values, field, server.HGet(key, field))
+			}
+		}
+		if strings.Contains(key, userID) || strings.Contains(strings.Join(values, "\n"), userID) {
+			found = append(found, key)
+		}
+	}
+	return found
+}
+
+func TestEraseUserData(t *testing.T) {
+	server := miniredis.RunT(t)
+	base, err := redis.Connect(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefix := range []string{"", "tenant:acme:"} {
+		t.Run("prefix "+prefix, func(t *testing.T) {
+			client := base.WithPrefix(prefix)
+			conversationStore := redis.NewHistoryStore(client, 0)
+			vonageClient := vonage.NewClient("jwt", "http://127.0.0.1:0", "http://127.0.0.1:0", "5511000000000", http.Client{})
+			openaiClient := openai.NewClient(nil, nil, nil, "test-model")
+			openaiClient.SetHistorySummaryStore(conversationStore)
+			mp := NewMessageProcessor(vonageClient, client, conversationStore, openaiClient, elevenlabs.Client{}, execution.NewManager())
+			mp.SetAudioDeleter(fakeAudioDeleter{})
+			t.Cleanup(mp.Stop)
+
+			userID, otherID := "5511999999999", "5522888888888"
+			seedUserData(t, mp, userID)
+			seedUserData(t, mp, otherID)
+
+			export, err := mp.ExportUserData(userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if export.HistorySummary != "Cliente informou o CPF" {
+				t.Errorf("exported summary = %q, want the user's summary", export.HistorySummary)
+			}
+			if len(export.ToolJobs) != 1 || export.ToolJobs[0].Arguments != `{"cpf":"123.456.789-09"}` {
+				t.Errorf("exported tool jobs = %+v, want the user's job", export.ToolJobs)
+			}
+			if len(export.CachedToolResults) != 1 || export.CachedToolResults[0] != (redis.CachedToolResult{Tool: "consultar_cpf", Result: "CPF regular"}) {
+				t.Errorf("exported cached tool results = %+v, want the user's result", export.CachedToolResults)
+			}
+
+			report, err := mp.EraseUserData(userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.MessagesDeleted != 2 || report.AudioDeleted != 1 {
+				t.Errorf("report = %+v, want 2 messages and 1 audio deleted", report)
+			}
+
+			if keys := leftovers(t, server, userID); len(keys) > 0 {
+				t.Errorf("data left after erasure in %v", keys)
+			}
+			if messages, _ := store.History(context.Background(), conversationStore, otherID); len(messages) != 2 {
+				t.Errorf("other user has %d messages after the erasure, want 2", len(messages))
+			}
+		})
+	}
+}
+
+func TestEraseUserData_WaitsForReplyAndDropsMessages(t *testing.T) {
+	mp, redisClient, _ := newTestProcessor(t, nil, nil, nil)
+	userID := "5511999999999"
+
+	// A reply that stores what it already sent when it is cancelled
+	turn := mp.executionManager.Start(userID)
+	go func() {
+		<-turn.Done()
+		time.Sleep(50 * time.Millisecond)
+		mp.conversationStore.Append(context.Background(), userID, store.AssistantMessage("Resposta parcial"))
+		mp.executionManager.Cleanup(userID, turn)
+	}()
+
+	if _, err := mp.EraseUserData(userID); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := store.History(context.Background(), mp.conversationStore, userID); len(messages) != 0 {
+		t.Errorf("history = %+v, want the cancelled reply erased too", messages)
+	}
+
+	// Messages arriving during an erasure are not stored
+	if started, err := redisClient.BeginErasure(userID, time.Minute); err != nil || !started {
+		t.Fatalf("BeginErasure = %v, %v", started, err)
+	}
+	if _, err := mp.EraseUserData(userID); !errors.Is(err, ErrErasureInProgress) {
+		t.Errorf("concurrent erasure returned %v, want ErrErasureInProgress", err)
+	}
+	mp.ProcessMessage(InboundMessage{From: userID, MessageType: "text", MessageUUID: "msg-2", Text: "Oi"})
+	if messages, _ := store.History(context.Background(), mp.conversationStore, userID); len(messages) != 0 {
+		t.Errorf("history = %+v, want the message dropped during the erasure", messages)
+	}
+}
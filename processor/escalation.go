@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/events"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetEscalationAcknowledgment sets the message sent to users whose conversation is escalated
+// to a human agent. An empty message sends nothing.
+func (mp *MessageProcessor) SetEscalationAcknowledgment(message string) {
+	mp.escalationAcknowledgment = message
+}
+
+// EscalateToHuman puts the conversation in the escalation queue, pauses the bot for the
+// user and lets the user know a human will take over. It implements openai.Escalator.
+// The pause does not time out: the bot stays quiet until the escalation is claimed or resolved.
+func (mp *MessageProcessor) EscalateToHuman(userID, reason, priority string) error {
+	// The escalation is queued before the pause, so a failure never leaves the bot quiet
+	// with no agent told to take over
+	escalation, err := mp.redisClient.AddEscalation(userID, reason, priority)
+	if err != nil {
+		return err
+	}
+
+	// Pausing cancels the turn that called the escalation tool, so the model says nothing else
+	pause := redis.BotPause{Reason: "escalation: " + reason, PausedAt: time.Now(), Escalated: true}
+	if _, err := mp.pauseConversation(userID, pause); err != nil {
+		return err
+	}
+
+	log.Warn().
+		Str("user_id", userID).
+		Str("escalation_id", escalation.ID).
+		Str("priority", escalation.Priority).
+		Str("reason", reason).
+		Msg("Conversation escalated to human agent")
+	mp.publishEvent(events.Escalation, userID, escalation)
+	mp.recordEscalation(userID)
+
+	if mp.escalationAcknowledgment == "" {
+		return nil
+	}
+	if _, err := mp.vonageClient.SendWhatsAppTextMessage(context.Background(), userID, mp.escalationAcknowledgment); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error sending escalation acknowledgment")
+		return nil
+	}
+	mp.recordOutboundMessage(userID, true)
+	acknowledgment := store.AssistantMessage(mp.escalationAcknowledgment)
+	acknowledgment.Variant = mp.openaiClient.Variant(userID)
+	if err := mp.conversationStore.Append(context.Background(), userID, acknowledgment); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error storing escalation acknowledgment")
+	}
+	return nil
+}
+
+// EscalationQueue returns the pending escalations, highest priority and oldest first.
+func (mp *MessageProcessor) EscalationQueue() ([]redis.Escalation, error) {
+	return mp.redisClient.GetEscalationQueue()
+}
+
+// ClaimEscalation assigns an escalation to an agent. From then on the pause behaves as a
+// takeover by the agent, and the bot resumes once the agent is idle for the takeover timeout.
+func (mp *MessageProcessor) ClaimEscalation(id, agent string) (redis.Escalation, error) {
+	escalation, err := mp.redisClient.ClaimEscalation(id, agent)
+	if err != nil {
+		return redis.Escalation{}, err
+	}
+	pause, paused, err := mp.redisClient.GetBotPause(escalation.UserID)
+	if err == nil && paused {
+		pause.Agent, pause.Escalated = agent, false
+		err = mp.redisClient.PauseBot(escalation.UserID, pause, mp.takeoverIdle())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", escalation.UserID).Msg("Error extending bot pause")
+	}
+
+	log.Info().
+		Str("user_id", escalation.UserID).
+		Str("escalation_id", id).
+		Str("agent", agent).
+		Msg("Escalation claimed")
+	mp.publishEvent(events.Escalation, escalation.UserID, escalation)
+
+	return escalation, nil
+}
+
+// ResolveEscalation removes an escalation from the queue and gives the conversation back to the bot.
+func (mp *MessageProcessor) ResolveEscalation(id string) (redis.Escalation, error) {
+	escalation, err := mp.redisClient.ResolveEscalation(id)
+	if err != nil {
+		return redis.Escalation{}, err
+	}
+	if _, err := mp.ResumeConversation(escalation.UserID); err != nil {
+		log.Error().Err(err).Str("user_id", escalation.UserID).Msg("Error resuming bot after escalation")
+	}
+
+	log.Info().
+		Str("user_id", escalation.UserID).
+		Str("escalation_id", id).
+		Msg("Escalation resolved")
+	mp.publishEvent(events.Escalation, escalation.UserID, escalation)
+
+	return escalation, nil
+}
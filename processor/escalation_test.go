@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalateToHuman_PausedUntilClaimed(t *testing.T) {
+	mp, _, server := newTestProcessor(t, nil, nil, nil)
+	userID := "5511999999999"
+
+	if err := mp.EscalateToHuman(userID, "ameaça de processo", "urgent"); err != nil {
+		t.Fatal(err)
+	}
+	// A manual pause before the claim does not give the escalation a timeout
+	if _, err := mp.PauseConversation(userID, "ana", "lendo a conversa"); err != nil {
+		t.Fatal(err)
+	}
+
+	server.FastForward(24 * time.Hour)
+	pause, paused, err := mp.ConversationPause(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused || !pause.Escalated {
+		t.Fatalf("pause = %+v, paused = %v, want the unclaimed escalation to keep the bot paused", pause, paused)
+	}
+
+	queue, err := mp.EscalationQueue()
+	if err != nil || len(queue) != 1 {
+		t.Fatalf("queue = %+v, err = %v", queue, err)
+	}
+	if _, err := mp.ClaimEscalation(queue[0].ID, "ana"); err != nil {
+		t.Fatal(err)
+	}
+	if pause, paused, _ := mp.ConversationPause(userID); !paused || pause.Escalated || pause.Agent != "ana" {
+		t.Fatalf("pause = %+v after the claim, want a takeover by the agent", pause)
+	}
+
+	// Once claimed, the bot resumes after the agent is idle for the takeover timeout
+	server.FastForward(mp.takeoverIdle() + time.Second)
+	if _, paused, _ := mp.ConversationPause(userID); paused {
+		t.Error("bot still paused after the claimed escalation went idle")
+	}
+}
+
+func TestEscalateToHuman_QueueFailureKeepsBotAnswering(t *testing.T) {
+	mp, _, server := newTestProcessor(t, nil, nil, nil)
+	userID := "5511999999999"
+
+	// A queue key of the wrong type makes AddEscalation fail
+	server.Set("escalations:queue", "not a sorted set")
+
+	if err := mp.EscalateToHuman(userID, "pediu atendente", "high"); err == nil {
+		t.Fatal("expected the escalation to fail")
+	}
+	if _, paused, err := mp.ConversationPause(userID); err != nil || paused {
+		t.Errorf("paused = %v, err = %v, want the bot to keep answering when nothing was queued", paused, err)
+	}
+}
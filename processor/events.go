@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/events"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetEventBus sets the bus on which delivery statuses and escalations are published.
+// Without a bus no events are published and SubscribeEvents fails.
+func (mp *MessageProcessor) SetEventBus(bus *events.Bus) {
+	mp.eventBus = bus
+}
+
+// SubscribeEvents starts receiving the events published by every server instance.
+func (mp *MessageProcessor) SubscribeEvents() (*events.Subscription, error) {
+	if mp.eventBus == nil {
+		return nil, fmt.Errorf("event bus not configured")
+	}
+	return mp.eventBus.Subscribe()
+}
+
+// publishEvent publishes an event when a bus is configured.
+func (mp *MessageProcessor) publishEvent(eventType events.Type, userID string, data any) {
+	if mp.eventBus == nil {
+		return
+	}
+	events.Emit(mp.eventBus, eventType, userID, data)
+}
+
+// HandleMessageStatus records the delivery status of a message sent to the user
+// and publishes it to the CRM.
+func (mp *MessageProcessor) HandleMessageStatus(status MessageStatus) error {
+	userID := status.To
+
+	timestamp, err := time.Parse(time.RFC3339, status.Timestamp)
+	if err != nil {
+		timestamp = time.Now()
+	}
+
+	deliveryStatus := redis.DeliveryStatus{
+		MessageUUID: status.MessageUUID,
+		Status:      status.Status,
+		Timestamp:   timestamp,
+	}
+	if status.Error != nil {
+		deliveryStatus.Error = fmt.Sprintf("%v: %s", status.Error.Title, status.Error.Detail)
+	}
+
+	if err := mp.redisClient.SaveDeliveryStatus(userID, deliveryStatus); err != nil {
+		return err
+	}
+
+	if deliveryStatus.Error != "" {
+		log.Warn().
+			Str("user_id", userID).
+			Str("message_uuid", status.MessageUUID).
+			Str("status", status.Status).
+			Str("error", deliveryStatus.Error).
+			Msg("Message delivery failed")
+	}
+
+	mp.publishEvent(events.DeliveryStatus, userID, deliveryStatus)
+	return nil
+}
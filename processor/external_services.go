@@ -6,8 +6,8 @@ import (
 	"github.com/NextMind-AI/chatbot-go/redis"
 )
 
-func (mp *MessageProcessor) markMessageAsRead(messageUUID string) error {
-	return mp.vonageClient.MarkMessageAsRead(messageUUID)
+func (mp *MessageProcessor) markMessageAsRead(ctx context.Context, messageUUID string) error {
+	return mp.vonageClient.MarkMessageAsRead(context.WithoutCancel(ctx), messageUUID)
 }
 
 func (mp *MessageProcessor) processWithAI(ctx context.Context, userID string, userName string, chatHistory []redis.ChatMessage) error {
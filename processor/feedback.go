@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// handleReaction records a thumbs up or down reaction to one of the bot's messages as
+// feedback on the user's experiment variant. Other reactions, and reactions from users
+// outside an experiment, are ignored; no reaction is answered.
+func (mp *MessageProcessor) handleReaction(message InboundMessage) {
+	if message.Reaction == nil || message.Reaction.Action != "react" {
+		return
+	}
+
+	var positive bool
+	switch {
+	case strings.HasPrefix(message.Reaction.Emoji, "👍"):
+		positive = true
+	case strings.HasPrefix(message.Reaction.Emoji, "👎"):
+		positive = false
+	default:
+		return
+	}
+
+	userID := message.From
+	variant := mp.openaiClient.Variant(userID)
+	if variant == "" {
+		return
+	}
+	if err := mp.redisClient.RecordFeedback(variant, userID, time.Now(), positive); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error recording feedback analytics")
+		return
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("variant", variant).
+		Bool("positive", positive).
+		Msg("Feedback recorded")
+}
@@ -0,0 +1,118 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	memoryExtractionInterval = time.Minute
+	memoryExtractionBatch    = 50
+	memoryExtractionTimeout  = 2 * time.Minute
+)
+
+// StartMemoryExtraction launches a background loop that extracts long-term facts from
+// conversations once they have been idle for idleTimeout, which is treated as the end
+// of the conversation. Must be called before the server starts receiving messages.
+func (mp *MessageProcessor) StartMemoryExtraction(idleTimeout time.Duration) {
+	mp.memoryIdleTimeout = idleTimeout
+
+	go func() {
+		log.Info().Dur("idle_timeout", idleTimeout).Msg("Memory extraction started")
+
+		ticker := time.NewTicker(memoryExtractionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-mp.stop:
+				log.Info().Msg("Memory extraction stopped")
+				return
+			}
+			userIDs, err := mp.redisClient.ClaimMemoryExtractions(time.Now(), memoryExtractionBatch)
+			if err != nil {
+				log.Error().Err(err).Msg("Error claiming memory extractions")
+				continue
+			}
+			for _, userID := range userIDs {
+				mp.extractUserMemories(userID)
+			}
+		}
+	}()
+}
+
+// scheduleMemoryExtraction postpones the user's memory extraction until the conversation goes idle.
+func (mp *MessageProcessor) scheduleMemoryExtraction(userID string) {
+	if mp.memoryIdleTimeout <= 0 {
+		return
+	}
+	if err := mp.redisClient.ScheduleMemoryExtraction(userID, time.Now().Add(mp.memoryIdleTimeout)); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Msg("Error scheduling memory extraction")
+	}
+}
+
+// extractUserMemories stores the facts found in the messages not processed by a previous extraction.
+func (mp *MessageProcessor) extractUserMemories(userID string) {
+	chatHistory, err := mp.getChatHistory(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error retrieving chat history for memory extraction")
+		return
+	}
+
+	extractedAt, err := mp.redisClient.GetMemoryExtractedAt(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error retrieving last memory extraction")
+		return
+	}
+
+	var messages []redis.ChatMessage
+	for _, msg := range chatHistory {
+		if msg.Timestamp.After(extractedAt) {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	known, err := mp.redisClient.GetUserMemories(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error retrieving user memories")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), memoryExtractionTimeout)
+	defer cancel()
+
+	facts, err := mp.openaiClient.ExtractMemories(ctx, userID, messages, known)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error extracting user memories")
+		// Try again on the next round
+		mp.scheduleMemoryExtraction(userID)
+		return
+	}
+
+	for _, fact := range facts {
+		if _, err := mp.redisClient.AddUserMemory(userID, fact, redis.MemorySourceExtraction); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Error storing user memory")
+		}
+	}
+
+	if err := mp.redisClient.SetMemoryExtractedAt(userID, messages[len(messages)-1].Timestamp); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error storing last memory extraction")
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Int("messages", len(messages)).
+		Int("facts", len(facts)).
+		Msg("Extracted user memories from conversation")
+}
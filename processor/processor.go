@@ -3,49 +3,113 @@ package processor
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/events"
 	"github.com/NextMind-AI/chatbot-go/execution"
 	"github.com/NextMind-AI/chatbot-go/openai"
 	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+	"github.com/NextMind-AI/chatbot-go/tracing"
+	"github.com/NextMind-AI/chatbot-go/usage"
 	"github.com/NextMind-AI/chatbot-go/vonage"
 
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type MessageProcessor struct {
-	vonageClient     vonage.Client
-	redisClient      redis.Client
-	openaiClient     openai.Client
-	elevenLabsClient elevenlabs.Client
-	executionManager *execution.Manager
+	vonageClient vonage.Client
+	redisClient  redis.Client
+	// conversationStore holds the chat history; redisClient keeps the rest of the bot's state
+	conversationStore store.ConversationStore
+	openaiClient      openai.Client
+	elevenLabsClient  elevenlabs.Client
+	executionManager  *execution.Manager
+	// memoryIdleTimeout is how long a conversation must be idle before facts are extracted; zero disables extraction
+	memoryIdleTimeout time.Duration
+	// takeoverIdleTimeout is how long the bot stays paused without agent activity
+	takeoverIdleTimeout time.Duration
+	// escalationAcknowledgment is sent to users escalated to a human agent
+	escalationAcknowledgment string
+	// eventBus publishes events to the CRM; nil disables them
+	eventBus *events.Bus
+	// audioDeleter deletes generated audio on erasure; nil keeps the objects
+	audioDeleter AudioDeleter
+	// usageMeter checks the users' daily budget; nil answers every message
+	usageMeter *usage.Meter
+	// budgetExceededMessage is sent instead of a response to users over their daily budget
+	budgetExceededMessage string
+	// stop is closed by Stop to end the background workers
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
-func NewMessageProcessor(vonageClient vonage.Client, redisClient redis.Client, openaiClient openai.Client, elevenLabsClient elevenlabs.Client, execManager *execution.Manager) *MessageProcessor {
+func NewMessageProcessor(vonageClient vonage.Client, redisClient redis.Client, conversationStore store.ConversationStore, openaiClient openai.Client, elevenLabsClient elevenlabs.Client, execManager *execution.Manager) *MessageProcessor {
 	return &MessageProcessor{
-		vonageClient:     vonageClient,
-		redisClient:      redisClient,
-		openaiClient:     openaiClient,
-		elevenLabsClient: elevenLabsClient,
-		executionManager: execManager,
+		vonageClient:      vonageClient,
+		redisClient:       redisClient,
+		conversationStore: conversationStore,
+		openaiClient:      openaiClient,
+		elevenLabsClient:  elevenLabsClient,
+		executionManager:  execManager,
+		stop:              make(chan struct{}),
+	}
+}
+
+// Stop ends the tool job workers and the memory extraction loop, for processors replaced
+// at runtime such as a reconfigured tenant's. A job being executed is finished first.
+func (mp *MessageProcessor) Stop() {
+	mp.stopOnce.Do(func() {
+		close(mp.stop)
+	})
+}
+
+func (mp *MessageProcessor) stopped() bool {
+	select {
+	case <-mp.stop:
+		return true
+	default:
+		return false
 	}
 }
 
 func (mp *MessageProcessor) ProcessMessage(message InboundMessage) {
 	log.Info().Str("message_uuid", message.MessageUUID).Msg("Processing message")
 
+	// Reactions are feedback and must not cancel the turn being answered
+	if message.MessageType == "reaction" {
+		mp.handleReaction(message)
+		return
+	}
+
 	userID := message.From
-	ctx := mp.executionManager.Start(userID)
-	defer mp.executionManager.Cleanup(userID, ctx)
+	executionCtx := mp.executionManager.Start(userID)
+	defer mp.executionManager.Cleanup(userID, executionCtx)
+
+	// Checked after Start, so an erasure that begins later stops this execution instead
+	if mp.erasing(userID) {
+		return
+	}
 
-	if err := mp.markMessageAsRead(message.MessageUUID); err != nil {
+	// Each inbound message starts a trace; cancelling the execution still cancels the turn
+	ctx, span := tracing.Start(executionCtx, "ProcessMessage",
+		attribute.String("chatbot.message_uuid", message.MessageUUID),
+		attribute.String("chatbot.message_type", message.MessageType),
+	)
+	defer span.End()
+
+	if err := mp.markMessageAsRead(ctx, message.MessageUUID); err != nil {
 		log.Error().
 			Err(err).
 			Str("message_uuid", message.MessageUUID).
 			Msg("Error marking message as read")
 	}
 
-	processedMsg, err := mp.extractMessageContent(message)
+	processedMsg, err := mp.extractMessageContent(ctx, message)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -53,6 +117,7 @@ func (mp *MessageProcessor) ProcessMessage(message InboundMessage) {
 			Msg("Error processing message content")
 		return
 	}
+	mp.recordInboundMessage(userID, message.MessageType == "audio")
 
 	if mp.cancelled(ctx, userID, "after content extraction") {
 		return
@@ -64,6 +129,16 @@ func (mp *MessageProcessor) ProcessMessage(message InboundMessage) {
 			Str("user_id", userID).
 			Msg("Error storing user message")
 	}
+	mp.scheduleMemoryExtraction(userID)
+
+	if mp.botPaused(userID) {
+		return
+	}
+
+	if mp.overBudget(userID) {
+		mp.sendBudgetExceededMessage(ctx, userID)
+		return
+	}
 
 	chatHistory, err := mp.getChatHistory(userID)
 	if err != nil {
@@ -104,12 +179,23 @@ func (mp *MessageProcessor) cancelled(ctx context.Context, userID, stage string)
 		log.Info().
 			Str("user_id", userID).
 			Msg("Message processing cancelled " + stage)
+		trace.SpanFromContext(ctx).AddEvent("cancelled " + stage)
 		return true
 	}
 	return false
 }
 
+// GetConversationStore returns the chat history store for external access
+func (mp *MessageProcessor) GetConversationStore() store.ConversationStore {
+	return mp.conversationStore
+}
+
 // GetRedisClient returns the Redis client for external access
 func (mp *MessageProcessor) GetRedisClient() *redis.Client {
 	return &mp.redisClient
 }
+
+// GetTools returns the tools offered to the model, including those disabled at runtime
+func (mp *MessageProcessor) GetTools() []openai.Tool {
+	return mp.openaiClient.Tools()
+}
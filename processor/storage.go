@@ -1,13 +1,18 @@
 package processor
 
 import (
+	"context"
+
 	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
 )
 
 func (mp *MessageProcessor) storeUserMessage(userID string, processedMsg *ProcessedMessage) error {
-	return mp.redisClient.AddUserMessage(userID, processedMsg.Text, processedMsg.UUID)
+	message := store.UserMessage(processedMsg.Text, processedMsg.UUID)
+	message.Variant = mp.openaiClient.Variant(userID)
+	return mp.conversationStore.Append(context.Background(), userID, message)
 }
 
 func (mp *MessageProcessor) getChatHistory(userID string) ([]redis.ChatMessage, error) {
-	return mp.redisClient.GetChatHistory(userID)
+	return store.History(context.Background(), mp.conversationStore, userID)
 }
@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultTakeoverIdleTimeout is how long a paused conversation waits for agent activity before the bot resumes.
+const defaultTakeoverIdleTimeout = 30 * time.Minute
+
+// SetTakeoverIdleTimeout sets how long the bot stays paused without agent activity.
+func (mp *MessageProcessor) SetTakeoverIdleTimeout(idleTimeout time.Duration) {
+	mp.takeoverIdleTimeout = idleTimeout
+}
+
+func (mp *MessageProcessor) takeoverIdle() time.Duration {
+	if mp.takeoverIdleTimeout <= 0 {
+		return defaultTakeoverIdleTimeout
+	}
+	return mp.takeoverIdleTimeout
+}
+
+// PauseConversation hands the conversation over to a human agent: the bot stops answering
+// the user and any reply being generated is cancelled.
+func (mp *MessageProcessor) PauseConversation(userID, agent, reason string) (redis.BotPause, error) {
+	current, paused, err := mp.redisClient.GetBotPause(userID)
+	if err != nil {
+		return redis.BotPause{}, err
+	}
+	return mp.pauseConversation(userID, redis.BotPause{
+		Agent:     agent,
+		Reason:    reason,
+		PausedAt:  time.Now(),
+		Escalated: paused && current.Escalated,
+	})
+}
+
+// pauseConversation stores the pause and cancels any reply being generated. An escalated
+// pause has no idle timeout, so the bot stays quiet until an agent claims the escalation.
+func (mp *MessageProcessor) pauseConversation(userID string, pause redis.BotPause) (redis.BotPause, error) {
+	idleTimeout := mp.takeoverIdle()
+	if pause.Escalated {
+		idleTimeout = 0
+	}
+	if err := mp.redisClient.PauseBot(userID, pause, idleTimeout); err != nil {
+		return redis.BotPause{}, err
+	}
+
+	// Starting an execution cancels the one in progress
+	ctx := mp.executionManager.Start(userID)
+	mp.executionManager.Cleanup(userID, ctx)
+
+	log.Info().
+		Str("user_id", userID).
+		Str("agent", pause.Agent).
+		Str("reason", pause.Reason).
+		Dur("idle_timeout", idleTimeout).
+		Msg("Bot paused for conversation")
+
+	return pause, nil
+}
+
+// ResumeConversation gives the conversation back to the bot. It reports whether it was paused.
+func (mp *MessageProcessor) ResumeConversation(userID string) (bool, error) {
+	resumed, err := mp.redisClient.ResumeBot(userID)
+	if err != nil {
+		return false, err
+	}
+	if resumed {
+		log.Info().Str("user_id", userID).Msg("Bot resumed for conversation")
+	}
+	return resumed, nil
+}
+
+// ConversationPause returns the pause of the user's conversation, if the bot is paused.
+func (mp *MessageProcessor) ConversationPause(userID string) (redis.BotPause, bool, error) {
+	return mp.redisClient.GetBotPause(userID)
+}
+
+// SendAgentMessage sends a message from a human agent to the user and stores it in history.
+// The conversation is paused first if it was not, so the bot does not talk over the agent,
+// and every agent message postpones the automatic resume.
+func (mp *MessageProcessor) SendAgentMessage(userID, agent, text string) (store.ChatMessage, error) {
+	pause, paused, err := mp.redisClient.GetBotPause(userID)
+	if err != nil {
+		return store.ChatMessage{}, err
+	}
+	if paused {
+		// An escalated pause stays without a timeout until the escalation is claimed
+		if !pause.Escalated {
+			err = mp.redisClient.TouchBotPause(userID, mp.takeoverIdle())
+		}
+	} else {
+		_, err = mp.PauseConversation(userID, agent, "agent message")
+	}
+	if err != nil {
+		return store.ChatMessage{}, err
+	}
+
+	response, err := mp.vonageClient.SendWhatsAppTextMessage(context.Background(), userID, text)
+	if err != nil {
+		return store.ChatMessage{}, fmt.Errorf("sending agent message: %w", err)
+	}
+	mp.recordOutboundMessage(userID, false)
+
+	message := store.AgentMessage(agent, text)
+	if response != nil {
+		message.MessageUUID = response.MessageUUID
+	}
+	if err := mp.conversationStore.Append(context.Background(), userID, message); err != nil {
+		return store.ChatMessage{}, err
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("agent", agent).
+		Int("message_length", len(text)).
+		Msg("Agent message sent")
+
+	return message, nil
+}
+
+// botPaused reports whether a human agent took over the conversation.
+// On errors the bot keeps answering.
+func (mp *MessageProcessor) botPaused(userID string) bool {
+	pause, paused, err := mp.redisClient.GetBotPause(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error checking bot pause")
+		return false
+	}
+	if paused {
+		log.Info().
+			Str("user_id", userID).
+			Str("agent", pause.Agent).
+			Msg("Bot paused for conversation, skipping AI response")
+	}
+	return paused
+}
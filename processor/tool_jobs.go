@@ -0,0 +1,258 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/openai"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	toolJobClaimTimeout = 5 * time.Second
+	// toolJobLease is how long a claimed job stays with its worker without being renewed;
+	// jobs of stopped instances are requeued once it expires
+	toolJobLease = time.Minute
+	// toolJobMaintenanceInterval is how often due retries are queued and expired leases reclaimed
+	toolJobMaintenanceInterval = 5 * time.Second
+	// toolJobRetryDelay is the first wait before delivering again a result the user was busy for;
+	// it doubles on each attempt up to toolJobMaxRetryDelay
+	toolJobRetryDelay    = 10 * time.Second
+	toolJobMaxRetryDelay = 5 * time.Minute
+)
+
+// toolJobTimeout bounds how long an async tool runs
+var toolJobTimeout = 15 * time.Minute
+
+// errUserBusy is returned when a job result is not delivered because the user is being answered
+var errUserBusy = errors.New("a turn is in progress for the user")
+
+// errBotPaused is returned when a job result is not delivered because a human agent has the conversation
+var errBotPaused = errors.New("the bot is paused for the user")
+
+// StartToolJobWorkers launches workers that execute async tool jobs stored in Redis, and
+// the maintenance loop that queues due retries and requeues the jobs of stopped instances.
+func (mp *MessageProcessor) StartToolJobWorkers(workers int) {
+	go mp.runToolJobMaintenance()
+
+	for i := 0; i < workers; i++ {
+		go mp.runToolJobWorker(i)
+	}
+}
+
+func (mp *MessageProcessor) runToolJobWorker(workerID int) {
+	log.Info().Int("worker_id", workerID).Msg("Tool job worker started")
+
+	for !mp.stopped() {
+		job, err := mp.redisClient.ClaimToolJob(toolJobClaimTimeout, toolJobLease)
+		if err != nil {
+			log.Error().Err(err).Int("worker_id", workerID).Msg("Error claiming tool job")
+			time.Sleep(toolJobClaimTimeout)
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		mp.processToolJob(*job)
+	}
+	log.Info().Int("worker_id", workerID).Msg("Tool job worker stopped")
+}
+
+func (mp *MessageProcessor) runToolJobMaintenance() {
+	ticker := time.NewTicker(toolJobMaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		queued, err := mp.redisClient.QueueDueToolJobs(time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("Error queueing tool job retries")
+		} else if queued > 0 {
+			log.Info().Int("queued", queued).Msg("Queued tool job retries")
+		}
+
+		requeued, err := mp.redisClient.RequeueExpiredToolJobs(time.Now(), toolJobLease)
+		if err != nil {
+			log.Error().Err(err).Msg("Error requeueing interrupted tool jobs")
+		} else if requeued > 0 {
+			log.Info().Int("requeued", requeued).Msg("Requeued interrupted tool jobs")
+		}
+
+		select {
+		case <-mp.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// keepToolJobLease renews the lease of a job until the returned function is called.
+func (mp *MessageProcessor) keepToolJobLease(jobID string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(toolJobLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := mp.redisClient.RenewToolJobLease(jobID, toolJobLease); err != nil {
+					log.Error().Err(err).Str("job_id", jobID).Msg("Error renewing tool job lease")
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// processToolJob runs the job's tool unless it already finished, then delivers the result.
+func (mp *MessageProcessor) processToolJob(job redis.ToolJob) {
+	defer mp.keepToolJobLease(job.ID)()
+
+	if job.Status != redis.ToolJobCompleted && job.Status != redis.ToolJobFailed {
+		job = mp.executeToolJob(job)
+	}
+
+	if err := mp.deliverToolJobResult(job); err != nil {
+		if errors.Is(err, errUserBusy) || errors.Is(err, errBotPaused) || errors.Is(err, context.Canceled) {
+			// The user's own turn, or the agent, goes first; deliver once it is over
+			mp.retryToolJobDelivery(job, err)
+			return
+		}
+		log.Error().
+			Err(err).
+			Str("user_id", job.UserID).
+			Str("job_id", job.ID).
+			Msg("Error delivering tool job result")
+	}
+
+	if err := mp.redisClient.FinishToolJob(job.ID); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Error finishing tool job")
+	}
+}
+
+// retryToolJobDelivery queues the job again after a delay that doubles on each attempt, so
+// a user in a long exchange with the bot is not competed with for every turn.
+func (mp *MessageProcessor) retryToolJobDelivery(job redis.ToolJob, reason error) {
+	delay := toolJobRetryDelay << min(job.DeliveryAttempts, 5)
+	if delay > toolJobMaxRetryDelay {
+		delay = toolJobMaxRetryDelay
+	}
+	job.DeliveryAttempts++
+	job.UpdatedAt = time.Now()
+	if err := mp.redisClient.SaveToolJob(job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Error saving tool job state")
+	}
+
+	log.Info().
+		Err(reason).
+		Str("user_id", job.UserID).
+		Str("job_id", job.ID).
+		Int("attempt", job.DeliveryAttempts).
+		Dur("delay", delay).
+		Msg("Tool job delivery postponed")
+	if err := mp.redisClient.RetryToolJobAt(job.ID, time.Now().Add(delay)); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Error scheduling tool job retry")
+	}
+}
+
+func (mp *MessageProcessor) executeToolJob(job redis.ToolJob) redis.ToolJob {
+	log.Info().
+		Str("user_id", job.UserID).
+		Str("tool_name", job.ToolName).
+		Str("job_id", job.ID).
+		Msg("Executing async tool job")
+
+	job.Status = redis.ToolJobRunning
+	job.UpdatedAt = time.Now()
+	if err := mp.redisClient.SaveToolJob(job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Error saving tool job state")
+	}
+
+	result, err := mp.runToolJobHandler(job)
+	if err != nil {
+		job.Status = redis.ToolJobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = redis.ToolJobCompleted
+		job.Result = result
+	}
+	job.UpdatedAt = time.Now()
+
+	if err := mp.redisClient.SaveToolJob(job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Error saving tool job result")
+	}
+
+	log.Info().
+		Str("user_id", job.UserID).
+		Str("tool_name", job.ToolName).
+		Str("job_id", job.ID).
+		Str("status", job.Status).
+		Msg("Async tool job finished")
+
+	return job
+}
+
+func (mp *MessageProcessor) runToolJobHandler(job redis.ToolJob) (string, error) {
+	handler, ok := mp.openaiClient.ToolHandler(job.ToolName)
+	if !ok {
+		return "", errors.New("no handler registered for tool " + job.ToolName)
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal([]byte(job.Arguments), &args); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), toolJobTimeout)
+	defer cancel()
+
+	ctx = openai.WithToolCallInfo(ctx, openai.ToolCallInfo{
+		Name:   job.ToolName,
+		CallID: job.ToolCallID,
+		UserID: job.UserID,
+	})
+
+	return handler(ctx, args)
+}
+
+// deliverToolJobResult runs a new generation turn for the user with the job result. It only
+// starts when no turn is in progress for the user, returning errUserBusy otherwise, and a
+// message from the user cancels it like any other turn. While the bot is paused it returns
+// errBotPaused, so the result is delivered once the conversation is back with the bot.
+func (mp *MessageProcessor) deliverToolJobResult(job redis.ToolJob) error {
+	if mp.botPaused(job.UserID) {
+		// A human agent is handling the conversation; the bot must not talk over them
+		return errBotPaused
+	}
+
+	ctx, started := mp.executionManager.TryStart(job.UserID)
+	if !started {
+		return errUserBusy
+	}
+	defer mp.executionManager.Cleanup(job.UserID, ctx)
+
+	chatHistory, err := mp.getChatHistory(job.UserID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", job.UserID).
+			Msg("Error retrieving chat history")
+		chatHistory = []redis.ChatMessage{}
+	}
+
+	return mp.openaiClient.ProcessToolJobResult(
+		ctx,
+		job,
+		chatHistory,
+		&mp.vonageClient,
+		&mp.redisClient,
+		&mp.elevenLabsClient,
+		job.UserID,
+	)
+}
@@ -0,0 +1,204 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/elevenlabs"
+	"github.com/NextMind-AI/chatbot-go/execution"
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/llm/llmtest"
+	"github.com/NextMind-AI/chatbot-go/openai"
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/vonage"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// vonageRecorder is a fake Vonage Messages API that records the texts sent
+type vonageRecorder struct {
+	*httptest.Server
+	mutex sync.Mutex
+	texts []string
+}
+
+func newVonageRecorder(t *testing.T) *vonageRecorder {
+	recorder := &vonageRecorder{}
+	recorder.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message vonage.WhatsAppMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err == nil {
+			recorder.mutex.Lock()
+			recorder.texts = append(recorder.texts, message.Text)
+			recorder.mutex.Unlock()
+		}
+		w.Write([]byte(`{"message_uuid":"sent"}`))
+	}))
+	t.Cleanup(recorder.Close)
+	return recorder
+}
+
+func (r *vonageRecorder) sent() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.texts...)
+}
+
+// newTestProcessor returns a processor backed by an in-memory Redis, the fake LLM server
+// and the fake Vonage API
+func newTestProcessor(t *testing.T, tools []openai.Tool, llmServer *llmtest.Server, vonageServer *vonageRecorder) (*MessageProcessor, redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	redisClient, err := redis.Connect(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var provider llm.Provider
+	if llmServer != nil {
+		provider = llm.NewOpenAICompatibleProvider(llmServer.URL, "", http.Client{})
+	}
+	openaiClient := openai.NewClient(provider, nil, tools, "test-model")
+	conversationStore := redis.NewHistoryStore(redisClient, 0)
+	openaiClient.SetConversationStore(conversationStore)
+
+	vonageURL := "http://127.0.0.1:0"
+	if vonageServer != nil {
+		vonageURL = vonageServer.URL
+	}
+	vonageClient := vonage.NewClient("jwt", vonageURL, vonageURL, "5511000000000", http.Client{})
+
+	mp := NewMessageProcessor(vonageClient, redisClient, conversationStore, openaiClient, elevenlabs.Client{}, execution.NewManager())
+	t.Cleanup(mp.Stop)
+	return mp, redisClient, server
+}
+
+func TestProcessToolJob_UserBusy(t *testing.T) {
+	mp, redisClient, _ := newTestProcessor(t, nil, nil, nil)
+	job := redis.ToolJob{ID: "job-1", UserID: "5511999999999", ToolName: "generate_quote", Status: redis.ToolJobCompleted, Result: "R$ 100"}
+	if err := redisClient.EnqueueToolJob(job); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := redisClient.ClaimToolJob(time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed.Status, claimed.Result = job.Status, job.Result
+
+	// The user is being answered
+	turn := mp.executionManager.Start(job.UserID)
+	mp.processToolJob(*claimed)
+
+	if turn.Err() != nil {
+		t.Fatal("delivering the job result cancelled the user's turn")
+	}
+	if next, _ := redisClient.ClaimToolJob(time.Second, time.Minute); next != nil {
+		t.Fatal("postponed job was queued again right away")
+	}
+	stored, err := redisClient.GetToolJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DeliveryAttempts != 1 {
+		t.Fatalf("DeliveryAttempts = %d, want 1", stored.DeliveryAttempts)
+	}
+	if queued, _ := redisClient.QueueDueToolJobs(time.Now().Add(toolJobRetryDelay)); queued != 1 {
+		t.Fatalf("queued %d jobs once the retry was due, want 1", queued)
+	}
+}
+
+func TestProcessToolJob_BotPaused(t *testing.T) {
+	vonageServer := newVonageRecorder(t)
+	mp, redisClient, _ := newTestProcessor(t, nil, nil, vonageServer)
+	job := redis.ToolJob{ID: "job-1", UserID: "5511999999999", ToolName: "generate_quote", Status: redis.ToolJobCompleted, Result: "R$ 100"}
+	if err := redisClient.EnqueueToolJob(job); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := redisClient.ClaimToolJob(time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed.Status, claimed.Result = job.Status, job.Result
+
+	// A human agent has the conversation
+	if _, err := mp.PauseConversation(job.UserID, "ana", "atendimento"); err != nil {
+		t.Fatal(err)
+	}
+	mp.processToolJob(*claimed)
+
+	if sent := vonageServer.sent(); len(sent) != 0 {
+		t.Fatalf("sent %q while the bot was paused", sent)
+	}
+	stored, err := redisClient.GetToolJob(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.DeliveryAttempts != 1 || stored.Result != "R$ 100" {
+		t.Fatalf("job = %+v, want the result kept for a later delivery", stored)
+	}
+	if queued, _ := redisClient.QueueDueToolJobs(time.Now().Add(toolJobRetryDelay)); queued != 1 {
+		t.Fatalf("queued %d jobs once the retry was due, want 1", queued)
+	}
+}
+
+func TestExecuteToolJob_Timeout(t *testing.T) {
+	timeout := toolJobTimeout
+	toolJobTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { toolJobTimeout = timeout })
+
+	slowTool := openai.Tool{
+		Definition: llm.ToolDefinition{Name: "generate_quote"},
+		Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+		Async: true,
+	}
+	mp, _, _ := newTestProcessor(t, []openai.Tool{slowTool}, nil, nil)
+
+	job := mp.executeToolJob(redis.ToolJob{ID: "job-1", UserID: "5511999999999", ToolName: "generate_quote", Arguments: "{}"})
+	if job.Status != redis.ToolJobFailed || !strings.Contains(job.Error, "deadline exceeded") {
+		t.Fatalf("job = %+v, want it failed by the timeout", job)
+	}
+}
+
+func TestProcessToolJob_Delivers(t *testing.T) {
+	llmServer := llmtest.NewServer()
+	defer llmServer.Close()
+	// The tool round, where the model calls no other tool, then the streamed reply
+	llmServer.Enqueue(llmtest.OpenAICompletion(""), llmtest.OpenAIStream(`{"messages":[`, `{"content":"Sua cotação ficou em R$ 100","type":"text"}]}`))
+	vonageServer := newVonageRecorder(t)
+
+	quoteTool := openai.Tool{
+		Definition: llm.ToolDefinition{Name: "generate_quote"},
+		Handler: func(ctx context.Context, args map[string]any) (string, error) {
+			return "R$ 100", nil
+		},
+		Async: true,
+	}
+	mp, redisClient, _ := newTestProcessor(t, []openai.Tool{quoteTool}, llmServer, vonageServer)
+
+	if err := redisClient.EnqueueToolJob(redis.ToolJob{ID: "job-1", UserID: "5511999999999", ToolName: "generate_quote", ToolCallID: "call-1", Arguments: "{}"}); err != nil {
+		t.Fatal(err)
+	}
+	job, err := redisClient.ClaimToolJob(time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mp.processToolJob(*job)
+
+	if sent := vonageServer.sent(); len(sent) != 1 || sent[0] != "Sua cotação ficou em R$ 100" {
+		t.Fatalf("sent %q, want the follow-up reply", sent)
+	}
+	if requests := llmServer.Requests(); len(requests) != 2 || !strings.Contains(requests[1], "R$ 100") {
+		t.Fatalf("LLM requests = %q, want the job result in the prompt", requests)
+	}
+	if requeued, _ := redisClient.RequeueExpiredToolJobs(time.Now().Add(time.Hour), time.Minute); requeued != 0 {
+		t.Fatal("delivered job was still claimed")
+	}
+}
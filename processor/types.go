@@ -11,6 +11,8 @@ type InboundMessage struct {
 	Timestamp     string  `json:"timestamp"`
 	To            string  `json:"to"`
 	Audio         *Audio  `json:"audio,omitempty"`
+	// Reaction is set on messages of type reaction, sent when the user reacts to a message
+	Reaction *Reaction `json:"reaction,omitempty"`
 }
 
 type Profile struct {
@@ -21,7 +23,31 @@ type Audio struct {
 	URL string `json:"url"`
 }
 
+type Reaction struct {
+	// Action is react, or unreact when the user removes a reaction
+	Action string `json:"action"`
+	Emoji  string `json:"emoji"`
+}
+
 type ProcessedMessage struct {
 	Text string
 	UUID string
 }
+
+// MessageStatus is a delivery status update for a message sent to the user,
+// posted by Vonage to the status webhook.
+type MessageStatus struct {
+	MessageUUID string              `json:"message_uuid"`
+	To          string              `json:"to"`
+	From        string              `json:"from"`
+	Timestamp   string              `json:"timestamp"`
+	Status      string              `json:"status"`
+	Channel     string              `json:"channel"`
+	Error       *MessageStatusError `json:"error,omitempty"`
+}
+
+type MessageStatusError struct {
+	Type   string `json:"type"`
+	Title  any    `json:"title"`
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+	"github.com/NextMind-AI/chatbot-go/usage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SetUsageMeter sets the meter whose daily budget is checked before each response.
+// Users over budget receive budgetExceededMessage instead of a response from the model.
+func (mp *MessageProcessor) SetUsageMeter(meter *usage.Meter, budgetExceededMessage string) {
+	mp.usageMeter = meter
+	mp.budgetExceededMessage = budgetExceededMessage
+}
+
+// UsageMeter returns the meter usage is accounted with, or nil when there is none.
+func (mp *MessageProcessor) UsageMeter() *usage.Meter {
+	return mp.usageMeter
+}
+
+// overBudget reports whether the user reached their daily budget. A failed check lets the bot answer.
+func (mp *MessageProcessor) overBudget(userID string) bool {
+	if mp.usageMeter == nil {
+		return false
+	}
+	over, err := mp.usageMeter.OverBudget(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error checking daily budget")
+		return false
+	}
+	return over
+}
+
+// sendBudgetExceededMessage answers a user over their daily budget with the configured message.
+func (mp *MessageProcessor) sendBudgetExceededMessage(ctx context.Context, userID string) {
+	log.Warn().Str("user_id", userID).Msg("Daily budget exceeded, not calling the model")
+	if mp.budgetExceededMessage == "" {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if _, err := mp.vonageClient.SendWhatsAppTextMessage(ctx, userID, mp.budgetExceededMessage); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error sending budget exceeded message")
+		return
+	}
+	mp.recordOutboundMessage(userID, true)
+	message := store.AssistantMessage(mp.budgetExceededMessage)
+	message.Variant = mp.openaiClient.Variant(userID)
+	if err := mp.conversationStore.Append(ctx, userID, message); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error storing budget exceeded message")
+	}
+}
@@ -0,0 +1,177 @@
+// Package prompt renders system prompts from Go templates kept in a file or in Redis.
+// Templates are checked for changes while the bot runs and swapped atomically, so a
+// prompt can be edited without recompiling or restarting the bot.
+//
+// A template receives Data, as in:
+//
+//	Você é o assistente da Acme. Agora são {{.Time.Format "15:04"}}.
+//	{{if .Name}}O nome do cliente é {{.Name}}.{{end}}
+//	{{if eq .Attributes.plan "premium"}}Ofereça o atendimento prioritário.{{end}}
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Data is what a template knows about the user being answered.
+type Data struct {
+	Name  string
+	Phone string
+	// Time is the current time in the bot's time zone
+	Time time.Time
+	// Locale is the user's locale attribute, or the bot's locale, such as "pt-BR"
+	Locale string
+	// Attributes are the user's custom attributes; missing ones are empty
+	Attributes map[string]string
+}
+
+// ErrNotFound is returned by loaders whose source has no template.
+var ErrNotFound = errors.New("prompt template not found")
+
+// Loader reads the current text of a template.
+type Loader interface {
+	Load() (string, error)
+}
+
+type fileLoader string
+
+// File loads the template from a file.
+func File(path string) Loader {
+	return fileLoader(path)
+}
+
+func (l fileLoader) Load() (string, error) {
+	text, err := os.ReadFile(string(l))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, string(l))
+	}
+	return string(text), err
+}
+
+type redisLoader []*redis.Client
+
+// Redis loads the template saved with SavePromptTemplate through the first client that
+// has one, so a tenant's prefixed client can fall back to the bot's template.
+func Redis(clients ...*redis.Client) Loader {
+	return redisLoader(clients)
+}
+
+func (l redisLoader) Load() (string, error) {
+	for _, client := range l {
+		text, ok, err := client.GetPromptTemplate()
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return text, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+var funcs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// default returns value, or fallback when value is empty: {{default "cliente" .Name}}
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// Parse parses a template and checks that it renders, so templates referring to unknown
+// fields or functions are rejected before they are used.
+func Parse(text string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Funcs(funcs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	sample := Data{Name: "Maria", Phone: "5511999990000", Time: time.Now(), Locale: "pt-BR", Attributes: map[string]string{}}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// version is a loaded template with the text it was parsed from.
+type version struct {
+	text     string
+	template *template.Template
+}
+
+// Template is a prompt template that follows the changes to its source.
+type Template struct {
+	loader   Loader
+	interval time.Duration
+	current  atomic.Pointer[version]
+	// checked is when the source was last checked, in Unix nanoseconds
+	checked atomic.Int64
+}
+
+// New loads a template, which is then checked for changes at most once per interval when
+// rendered; a zero interval never checks again.
+func New(loader Loader, interval time.Duration) (*Template, error) {
+	t := &Template{loader: loader, interval: interval}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload loads the template from its source and swaps it in when it changed. A template
+// that fails to load or parse is returned as an error and the current one is kept.
+func (t *Template) Reload() error {
+	t.checked.Store(time.Now().UnixNano())
+
+	text, err := t.loader.Load()
+	if err != nil {
+		return err
+	}
+	if current := t.current.Load(); current != nil && current.text == text {
+		return nil
+	}
+	tmpl, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	t.current.Store(&version{text: text, template: tmpl})
+	log.Info().Int("length", len(text)).Msg("Prompt template loaded")
+	return nil
+}
+
+// Text returns the text of the template in use.
+func (t *Template) Text() string {
+	return t.current.Load().text
+}
+
+// Render executes the template, first reloading it if the interval passed since the
+// source was last checked. Only one caller reloads; the others use the current template.
+func (t *Template) Render(data Data) (string, error) {
+	if t.interval > 0 {
+		checked := t.checked.Load()
+		if time.Since(time.Unix(0, checked)) >= t.interval && t.checked.CompareAndSwap(checked, time.Now().UnixNano()) {
+			if err := t.Reload(); err != nil {
+				log.Error().Err(err).Msg("Error reloading prompt template, keeping the current one")
+			}
+		}
+	}
+
+	if data.Attributes == nil {
+		data.Attributes = map[string]string{}
+	}
+	var prompt strings.Builder
+	err := t.current.Load().template.Execute(&prompt, data)
+	return prompt.String(), err
+}
@@ -0,0 +1,75 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	tmpl, err := New(textLoader(`Olá {{default "cliente" .Name}} ({{.Phone}}), {{.Locale}} às {{.Time.Format "15:04"}}.{{if eq .Attributes.plan "premium"}} Premium.{{end}}`), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	got, err := tmpl.Render(Data{Phone: "5511", Time: at, Locale: "pt-BR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Olá cliente (5511), pt-BR às 09:30."; got != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+
+	got, _ = tmpl.Render(Data{Name: "Ana", Time: at, Locale: "en-US", Attributes: map[string]string{"plan": "premium"}})
+	if want := "Olá Ana (), en-US às 09:30. Premium."; got != want {
+		t.Fatalf("Render = %q, want %q", got, want)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, text := range []string{"{{.Name", "{{.Email}}", "{{capitalize .Name}}"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) accepted", text)
+		}
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	write := func(text string) {
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v1 {{.Name}}")
+
+	tmpl, err := New(File(path), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write("v2 {{.Name}}")
+	time.Sleep(2 * time.Millisecond)
+	if got, _ := tmpl.Render(Data{Name: "Ana"}); got != "v2 Ana" {
+		t.Fatalf("Render after change = %q", got)
+	}
+
+	// An invalid template is ignored and the current one kept
+	write("v3 {{.Name")
+	time.Sleep(2 * time.Millisecond)
+	if got, _ := tmpl.Render(Data{Name: "Ana"}); got != "v2 Ana" {
+		t.Fatalf("Render after invalid change = %q", got)
+	}
+
+	if _, err := New(File(filepath.Join(t.TempDir(), "missing.tmpl")), 0); err == nil {
+		t.Fatal("missing file accepted")
+	}
+}
+
+type textLoader string
+
+func (l textLoader) Load() (string, error) {
+	return string(l), nil
+}
@@ -0,0 +1,471 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Analytics are aggregated incrementally into one bucket per UTC hour as messages flow,
+// so reports never have to read the raw history. Days and other periods are built by
+// merging the hours they cover.
+
+// analyticsTTL is how long hourly buckets are kept.
+const analyticsTTL = 400 * 24 * time.Hour
+
+// awaitingReplyTTL bounds how long a user message waits for the bot's first reply
+// before it stops counting towards the response latency.
+const awaitingReplyTTL = 24 * time.Hour
+
+// replyLatencyBounds are the upper bounds, in seconds, of the response latency histogram.
+// Replies slower than the last bound fall into an overflow bucket.
+var replyLatencyBounds = []float64{
+	1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 14, 16, 18, 20, 25, 30, 35, 40, 50, 60,
+	90, 120, 180, 300, 600, 1800, 3600,
+}
+
+const (
+	analyticsInbound         = "inbound"
+	analyticsInboundAudio    = "inbound_audio"
+	analyticsOutbound        = "outbound"
+	analyticsOutboundAudio   = "outbound_audio"
+	analyticsEscalations     = "escalations"
+	analyticsReplies         = "replies"
+	analyticsToolPrefix      = "tool:"
+	analyticsToolErrorPrefix = "tool_error:"
+	analyticsLatencyPrefix   = "reply_le_"
+	analyticsLatencyOverflow = "reply_le_inf"
+	// Experiment variants are counted in fields named variant:<name>:<metric>
+	analyticsVariantPrefix     = "variant:"
+	analyticsVariantMessages   = "messages"
+	analyticsVariantReplies    = "replies"
+	analyticsVariantReplyChars = "reply_chars"
+	analyticsVariantEscalation = "escalations"
+	analyticsVariantThumbsUp   = "thumbs_up"
+	analyticsVariantThumbsDown = "thumbs_down"
+)
+
+// AnalyticsPeriod is a time range [Start, End) to aggregate. Bounds are rounded down to the hour.
+type AnalyticsPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Analytics are the aggregated conversation metrics of a period.
+type Analytics struct {
+	Start                  time.Time
+	End                    time.Time
+	Conversations          int64
+	InboundMessages        int64
+	InboundAudio           int64
+	OutboundMessages       int64
+	OutboundAudio          int64
+	Replies                int64
+	ReplyLatencyMedian     time.Duration
+	ReplyLatencyP95        time.Duration
+	ToolCalls              map[string]int64
+	ToolErrors             map[string]int64
+	Escalations            int64
+	EscalatedConversations int64
+	// Variants are the metrics of each experiment variant seen in the period
+	Variants map[string]VariantAnalytics
+}
+
+// VariantAnalytics are the metrics of an experiment variant. Messages count the user's
+// messages and the bot's replies, so Messages/Conversations is the conversation length.
+type VariantAnalytics struct {
+	Conversations          int64
+	Messages               int64
+	Replies                int64
+	ReplyCharacters        int64
+	Escalations            int64
+	EscalatedConversations int64
+	ThumbsUp               int64
+	ThumbsDown             int64
+}
+
+func (c *Client) analyticsHourKey(at time.Time) string {
+	return c.key(fmt.Sprintf("analytics:hour:%s", at.UTC().Format("2006-01-02T15")))
+}
+
+func (c *Client) analyticsConversationsKey(at time.Time) string {
+	return c.analyticsHourKey(at) + ":conversations"
+}
+
+func (c *Client) analyticsEscalatedKey(at time.Time) string {
+	return c.analyticsHourKey(at) + ":escalated"
+}
+
+func (c *Client) analyticsVariantKey(at time.Time, variant, name string) string {
+	return c.analyticsHourKey(at) + ":" + analyticsVariantPrefix + variant + ":" + name
+}
+
+func variantField(variant, metric string) string {
+	return analyticsVariantPrefix + variant + ":" + metric
+}
+
+func (c *Client) awaitingReplyKey(userID string) string {
+	return c.key(fmt.Sprintf("analytics_awaiting_reply:%s", userID))
+}
+
+// latencyField returns the histogram field counting a reply that took latency.
+func latencyField(latency time.Duration) string {
+	seconds := latency.Seconds()
+	for _, bound := range replyLatencyBounds {
+		if seconds <= bound {
+			return analyticsLatencyPrefix + strconv.FormatFloat(bound, 'f', -1, 64)
+		}
+	}
+	return analyticsLatencyOverflow
+}
+
+// RecordInboundMessage counts a message received from the user and starts waiting
+// for the bot's reply, unless an earlier message is still unanswered.
+func (c *Client) RecordInboundMessage(userID string, at time.Time, audio bool) error {
+	key := c.analyticsHourKey(at)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.HIncrBy(c.ctx, key, analyticsInbound, 1)
+	if audio {
+		pipe.HIncrBy(c.ctx, key, analyticsInboundAudio, 1)
+	}
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	pipe.PFAdd(c.ctx, c.analyticsConversationsKey(at), userID)
+	pipe.Expire(c.ctx, c.analyticsConversationsKey(at), analyticsTTL)
+	pipe.SetNX(c.ctx, c.awaitingReplyKey(userID), at.UnixMilli(), awaitingReplyTTL)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RecordOutboundMessage counts a message sent to the user. The first message from the bot
+// after a user message records the response latency in the hour the user wrote; a message
+// from a human agent answers the user without counting as a bot reply.
+func (c *Client) RecordOutboundMessage(userID string, at time.Time, audio, fromBot bool) error {
+	key := c.analyticsHourKey(at)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.HIncrBy(c.ctx, key, analyticsOutbound, 1)
+	if audio {
+		pipe.HIncrBy(c.ctx, key, analyticsOutboundAudio, 1)
+	}
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	pipe.PFAdd(c.ctx, c.analyticsConversationsKey(at), userID)
+	pipe.Expire(c.ctx, c.analyticsConversationsKey(at), analyticsTTL)
+	awaiting := pipe.GetDel(c.ctx, c.awaitingReplyKey(userID))
+	if _, err := pipe.Exec(c.ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	if !fromBot {
+		return nil
+	}
+	millis, err := awaiting.Int64()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	receivedAt := time.UnixMilli(millis)
+	latency := at.Sub(receivedAt)
+	if latency < 0 {
+		latency = 0
+	}
+	receivedKey := c.analyticsHourKey(receivedAt)
+
+	pipe = c.rdb.TxPipeline()
+	pipe.HIncrBy(c.ctx, receivedKey, analyticsReplies, 1)
+	pipe.HIncrBy(c.ctx, receivedKey, latencyField(latency), 1)
+	pipe.Expire(c.ctx, receivedKey, analyticsTTL)
+	_, err = pipe.Exec(c.ctx)
+	return err
+}
+
+// RecordToolCall counts a tool call and whether it failed.
+func (c *Client) RecordToolCall(name string, at time.Time, failed bool) error {
+	key := c.analyticsHourKey(at)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.HIncrBy(c.ctx, key, analyticsToolPrefix+name, 1)
+	if failed {
+		pipe.HIncrBy(c.ctx, key, analyticsToolErrorPrefix+name, 1)
+	}
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RecordEscalation counts a conversation escalated to a human agent.
+func (c *Client) RecordEscalation(userID string, at time.Time) error {
+	key := c.analyticsHourKey(at)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.HIncrBy(c.ctx, key, analyticsEscalations, 1)
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	pipe.PFAdd(c.ctx, c.analyticsEscalatedKey(at), userID)
+	pipe.Expire(c.ctx, c.analyticsEscalatedKey(at), analyticsTTL)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RecordVariantMessage counts a message from a user in an experiment variant.
+func (c *Client) RecordVariantMessage(variant, userID string, at time.Time) error {
+	return c.recordVariant(variant, userID, at, map[string]int64{analyticsVariantMessages: 1})
+}
+
+// RecordVariantReply counts a reply of the bot, of length characters, to a user in an
+// experiment variant.
+func (c *Client) RecordVariantReply(variant, userID string, at time.Time, length int) error {
+	return c.recordVariant(variant, userID, at, map[string]int64{
+		analyticsVariantMessages:   1,
+		analyticsVariantReplies:    1,
+		analyticsVariantReplyChars: int64(length),
+	})
+}
+
+// RecordVariantEscalation counts an escalation of a user in an experiment variant.
+func (c *Client) RecordVariantEscalation(variant, userID string, at time.Time) error {
+	if err := c.recordVariant(variant, userID, at, map[string]int64{analyticsVariantEscalation: 1}); err != nil {
+		return err
+	}
+	key := c.analyticsVariantKey(at, variant, "escalated")
+	pipe := c.rdb.TxPipeline()
+	pipe.PFAdd(c.ctx, key, userID)
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RecordFeedback counts a thumbs up or down given by a user in an experiment variant.
+func (c *Client) RecordFeedback(variant, userID string, at time.Time, positive bool) error {
+	metric := analyticsVariantThumbsDown
+	if positive {
+		metric = analyticsVariantThumbsUp
+	}
+	return c.recordVariant(variant, userID, at, map[string]int64{metric: 1})
+}
+
+// recordVariant adds the counts to the variant's fields and the user to its conversations.
+func (c *Client) recordVariant(variant, userID string, at time.Time, counts map[string]int64) error {
+	key := c.analyticsHourKey(at)
+	conversationsKey := c.analyticsVariantKey(at, variant, "conversations")
+
+	pipe := c.rdb.TxPipeline()
+	for metric, count := range counts {
+		pipe.HIncrBy(c.ctx, key, variantField(variant, metric), count)
+	}
+	pipe.Expire(c.ctx, key, analyticsTTL)
+	pipe.PFAdd(c.ctx, conversationsKey, userID)
+	pipe.Expire(c.ctx, conversationsKey, analyticsTTL)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// periodHours returns the start of every hour in the period.
+func periodHours(period AnalyticsPeriod) []time.Time {
+	var hours []time.Time
+	for hour := period.Start.UTC().Truncate(time.Hour); hour.Before(period.End); hour = hour.Add(time.Hour) {
+		hours = append(hours, hour)
+	}
+	return hours
+}
+
+// GetAnalytics aggregates the hourly buckets covered by each period. Distinct conversations
+// are counted with HyperLogLogs, so they are exact for small numbers and approximate
+// (around 1% error) for large ones. The conversations of the experiment variants found in
+// the buckets are counted in a second round trip.
+func (c *Client) GetAnalytics(periods []AnalyticsPeriod) ([]Analytics, error) {
+	pipe := c.rdb.Pipeline()
+
+	hourCounters := map[string]*redis.MapStringStringCmd{}
+	conversations := make([]*redis.IntCmd, len(periods))
+	escalated := make([]*redis.IntCmd, len(periods))
+	for i, period := range periods {
+		hours := periodHours(period)
+		if len(hours) == 0 {
+			continue
+		}
+
+		conversationKeys := make([]string, len(hours))
+		escalatedKeys := make([]string, len(hours))
+		for j, hour := range hours {
+			key := c.analyticsHourKey(hour)
+			if _, ok := hourCounters[key]; !ok {
+				hourCounters[key] = pipe.HGetAll(c.ctx, key)
+			}
+			conversationKeys[j] = c.analyticsConversationsKey(hour)
+			escalatedKeys[j] = c.analyticsEscalatedKey(hour)
+		}
+		conversations[i] = pipe.PFCount(c.ctx, conversationKeys...)
+		escalated[i] = pipe.PFCount(c.ctx, escalatedKeys...)
+	}
+
+	if len(hourCounters) > 0 {
+		if _, err := pipe.Exec(c.ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]Analytics, len(periods))
+	for i, period := range periods {
+		analytics := Analytics{
+			Start:      period.Start,
+			End:        period.End,
+			ToolCalls:  map[string]int64{},
+			ToolErrors: map[string]int64{},
+			Variants:   map[string]VariantAnalytics{},
+		}
+		if conversations[i] == nil {
+			results[i] = analytics
+			continue
+		}
+		analytics.Conversations = conversations[i].Val()
+		analytics.EscalatedConversations = escalated[i].Val()
+
+		histogram := map[string]int64{}
+		for _, hour := range periodHours(period) {
+			for field, value := range hourCounters[c.analyticsHourKey(hour)].Val() {
+				count, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					continue
+				}
+				switch {
+				case field == analyticsInbound:
+					analytics.InboundMessages += count
+				case field == analyticsInboundAudio:
+					analytics.InboundAudio += count
+				case field == analyticsOutbound:
+					analytics.OutboundMessages += count
+				case field == analyticsOutboundAudio:
+					analytics.OutboundAudio += count
+				case field == analyticsEscalations:
+					analytics.Escalations += count
+				case field == analyticsReplies:
+					analytics.Replies += count
+				case strings.HasPrefix(field, analyticsToolPrefix):
+					analytics.ToolCalls[strings.TrimPrefix(field, analyticsToolPrefix)] += count
+				case strings.HasPrefix(field, analyticsToolErrorPrefix):
+					analytics.ToolErrors[strings.TrimPrefix(field, analyticsToolErrorPrefix)] += count
+				case strings.HasPrefix(field, analyticsLatencyPrefix):
+					histogram[field] += count
+				case strings.HasPrefix(field, analyticsVariantPrefix):
+					addVariantCount(analytics.Variants, strings.TrimPrefix(field, analyticsVariantPrefix), count)
+				}
+			}
+		}
+		analytics.ReplyLatencyMedian = latencyQuantile(histogram, 0.5)
+		analytics.ReplyLatencyP95 = latencyQuantile(histogram, 0.95)
+
+		results[i] = analytics
+	}
+
+	if err := c.countVariantConversations(periods, results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// addVariantCount adds the count of a variant:<name>:<metric> field, given without its prefix.
+func addVariantCount(variants map[string]VariantAnalytics, field string, count int64) {
+	separator := strings.LastIndex(field, ":")
+	if separator < 0 {
+		return
+	}
+	name := field[:separator]
+	variant := variants[name]
+	switch field[separator+1:] {
+	case analyticsVariantMessages:
+		variant.Messages += count
+	case analyticsVariantReplies:
+		variant.Replies += count
+	case analyticsVariantReplyChars:
+		variant.ReplyCharacters += count
+	case analyticsVariantEscalation:
+		variant.Escalations += count
+	case analyticsVariantThumbsUp:
+		variant.ThumbsUp += count
+	case analyticsVariantThumbsDown:
+		variant.ThumbsDown += count
+	}
+	variants[name] = variant
+}
+
+// countVariantConversations counts the distinct and escalated conversations of the
+// variants found in each period.
+func (c *Client) countVariantConversations(periods []AnalyticsPeriod, results []Analytics) error {
+	type variantCounts struct {
+		conversations *redis.IntCmd
+		escalated     *redis.IntCmd
+	}
+
+	pipe := c.rdb.Pipeline()
+	counts := make([]map[string]variantCounts, len(periods))
+	queued := false
+	for i, period := range periods {
+		counts[i] = map[string]variantCounts{}
+		hours := periodHours(period)
+		for name := range results[i].Variants {
+			conversationKeys := make([]string, len(hours))
+			escalatedKeys := make([]string, len(hours))
+			for j, hour := range hours {
+				conversationKeys[j] = c.analyticsVariantKey(hour, name, "conversations")
+				escalatedKeys[j] = c.analyticsVariantKey(hour, name, "escalated")
+			}
+			counts[i][name] = variantCounts{
+				conversations: pipe.PFCount(c.ctx, conversationKeys...),
+				escalated:     pipe.PFCount(c.ctx, escalatedKeys...),
+			}
+			queued = true
+		}
+	}
+	if !queued {
+		return nil
+	}
+	if _, err := pipe.Exec(c.ctx); err != nil {
+		return err
+	}
+
+	for i := range periods {
+		for name, count := range counts[i] {
+			variant := results[i].Variants[name]
+			variant.Conversations = count.conversations.Val()
+			variant.EscalatedConversations = count.escalated.Val()
+			results[i].Variants[name] = variant
+		}
+	}
+	return nil
+}
+
+// latencyQuantile estimates a quantile of the response latency histogram, interpolating
+// linearly inside the bucket that holds it. Quantiles in the overflow bucket are reported
+// as the last bound.
+func latencyQuantile(histogram map[string]int64, quantile float64) time.Duration {
+	var total int64
+	for _, count := range histogram {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := quantile * float64(total)
+	var cumulative int64
+	lower := 0.0
+	for _, bound := range replyLatencyBounds {
+		count := histogram[analyticsLatencyPrefix+strconv.FormatFloat(bound, 'f', -1, 64)]
+		if count > 0 && float64(cumulative+count) >= rank {
+			fraction := (rank - float64(cumulative)) / float64(count)
+			seconds := lower + (bound-lower)*math.Max(fraction, 0)
+			return time.Duration(seconds * float64(time.Second))
+		}
+		cumulative += count
+		lower = bound
+	}
+	return time.Duration(lower * float64(time.Second))
+}
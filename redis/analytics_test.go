@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyField(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                       "reply_le_1",
+		1500 * time.Millisecond: "reply_le_2",
+		10 * time.Second:        "reply_le_10",
+		11 * time.Second:        "reply_le_12",
+		2 * time.Hour:           "reply_le_inf",
+	}
+	for latency, want := range cases {
+		if got := latencyField(latency); got != want {
+			t.Errorf("latencyField(%s) = %q, want %q", latency, got, want)
+		}
+	}
+}
+
+func TestLatencyQuantile(t *testing.T) {
+	histogram := map[string]int64{}
+	for _, latency := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second} {
+		histogram[latencyField(latency)]++
+	}
+
+	if got := latencyQuantile(histogram, 0.5); got != 2*time.Second {
+		t.Errorf("median = %s, want 2s", got)
+	}
+	if got := latencyQuantile(histogram, 0.95); got <= 3*time.Second || got > 4*time.Second {
+		t.Errorf("p95 = %s, want between 3s and 4s", got)
+	}
+	if got := latencyQuantile(map[string]int64{"reply_le_inf": 3}, 0.5); got != time.Hour {
+		t.Errorf("overflow median = %s, want the last bound", got)
+	}
+	if got := latencyQuantile(map[string]int64{}, 0.5); got != 0 {
+		t.Errorf("empty median = %s, want 0", got)
+	}
+}
+
+func TestAddVariantCount(t *testing.T) {
+	variants := map[string]VariantAnalytics{}
+	addVariantCount(variants, "short-prompt:replies", 2)
+	addVariantCount(variants, "short-prompt:reply_chars", 120)
+	addVariantCount(variants, "short-prompt:thumbs_up", 1)
+	addVariantCount(variants, "control:escalations", 1)
+	addVariantCount(variants, "malformed", 5)
+
+	want := VariantAnalytics{Replies: 2, ReplyCharacters: 120, ThumbsUp: 1}
+	if got := variants["short-prompt"]; got != want {
+		t.Errorf("short-prompt = %+v, want %+v", got, want)
+	}
+	if got := variants["control"]; got.Escalations != 1 {
+		t.Errorf("control = %+v, want one escalation", got)
+	}
+	if len(variants) != 2 {
+		t.Errorf("variants = %v, want only short-prompt and control", variants)
+	}
+}
@@ -0,0 +1,28 @@
+package redis
+
+import "fmt"
+
+// Conversation attributes are custom key-value data about a user, such as a plan or
+// a customer ID, set by agents or by the model. They are stored without TTL.
+func (c *Client) conversationAttributesKey(userID string) string {
+	return c.key(fmt.Sprintf("conversation_attributes:%s", userID))
+}
+
+// GetConversationAttributes returns the attributes of the user.
+func (c *Client) GetConversationAttributes(userID string) (map[string]string, error) {
+	return c.rdb.HGetAll(c.ctx, c.conversationAttributesKey(userID)).Result()
+}
+
+// SetConversationAttributes sets the attributes of the user, keeping the others.
+func (c *Client) SetConversationAttributes(userID string, attributes map[string]string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	return c.rdb.HSet(c.ctx, c.conversationAttributesKey(userID), attributes).Err()
+}
+
+// DeleteConversationAttribute removes an attribute. It returns false if it was not set.
+func (c *Client) DeleteConversationAttribute(userID, key string) (bool, error) {
+	deleted, err := c.rdb.HDel(c.ctx, c.conversationAttributesKey(userID), key).Result()
+	return deleted > 0, err
+}
@@ -0,0 +1,19 @@
+package redis
+
+import "fmt"
+
+// userAudioKey lists the URLs of the audio generated for the user. The objects stay in S3
+// until erased, so the list is stored without TTL.
+func (c *Client) userAudioKey(userID string) string {
+	return c.key(fmt.Sprintf("user_audio:%s", userID))
+}
+
+// AddUserAudio records the URL of an audio message generated for the user.
+func (c *Client) AddUserAudio(userID, url string) error {
+	return c.rdb.RPush(c.ctx, c.userAudioKey(userID), url).Err()
+}
+
+// GetUserAudio returns the URLs of the audio generated for the user, oldest first.
+func (c *Client) GetUserAudio(userID string) ([]string, error) {
+	return c.rdb.LRange(c.ctx, c.userAudioKey(userID), 0, -1).Result()
+}
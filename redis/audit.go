@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntry records an access to customer data through the CRM API.
+type AuditEntry struct {
+	// Actor is the API key name or token subject of the client
+	Actor  string `json:"actor"`
+	Role   string `json:"role"`
+	Action string `json:"action"`
+	// UserID is the customer whose data was accessed, empty for actions across customers
+	UserID    string    `json:"user_id,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+const (
+	auditLogKey = "crm_audit"
+	// auditLogMaxEntries and auditUserLogMaxEntries cap the global and per-user audit logs
+	auditLogMaxEntries     = 100000
+	auditUserLogMaxEntries = 1000
+)
+
+func (c *Client) auditUserLogKey(userID string) string {
+	return c.key(fmt.Sprintf("crm_audit:%s", userID))
+}
+
+// RecordAudit appends the entry to the audit log, and to the customer's own log when it has a user.
+func (c *Client) RecordAudit(entry AuditEntry) error {
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.LPush(c.ctx, c.key(auditLogKey), entryJSON)
+	pipe.LTrim(c.ctx, c.key(auditLogKey), 0, auditLogMaxEntries-1)
+	if entry.UserID != "" {
+		pipe.LPush(c.ctx, c.auditUserLogKey(entry.UserID), entryJSON)
+		pipe.LTrim(c.ctx, c.auditUserLogKey(entry.UserID), 0, auditUserLogMaxEntries-1)
+	}
+	_, err = pipe.Exec(c.ctx)
+	return err
+}
+
+// GetAuditLog returns up to limit entries, newest first. With a user ID only the
+// accesses to that customer's data are returned.
+func (c *Client) GetAuditLog(userID string, limit int) ([]AuditEntry, error) {
+	key := c.key(auditLogKey)
+	if userID != "" {
+		key = c.auditUserLogKey(userID)
+	}
+
+	entriesJSON, err := c.rdb.LRange(c.ctx, key, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]AuditEntry, 0, len(entriesJSON))
+	for _, entryJSON := range entriesJSON {
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(entryJSON), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
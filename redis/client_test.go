@@ -0,0 +1,19 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestClient returns a client of an in-memory Redis server that lives as long as the test
+func newTestClient(t *testing.T) (Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client, err := Connect(server.Addr(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.rdb.Close() })
+	return client, server
+}
@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const conversationIndexKey = "chat_conversations"
+
+func (c *Client) conversationMetaKey(userID string) string {
+	return c.key(fmt.Sprintf("chat_conversation:%s", userID))
+}
+
+// Summaries returns a page of conversations from the index, ordered by last message time.
+func (s *HistoryStore) Summaries(ctx context.Context, query store.ConversationQuery) (store.ConversationList, error) {
+	rdb := s.client.rdb
+
+	// Index entries are not expired with their conversations; drop those past retention first
+	if s.retention > 0 {
+		expired := strconv.FormatInt(time.Now().Add(-s.retention).UnixMilli(), 10)
+		if err := rdb.ZRemRangeByScore(ctx, s.client.key(conversationIndexKey), "-inf", "("+expired).Err(); err != nil {
+			return store.ConversationList{}, err
+		}
+	}
+
+	minScore, maxScore := "-inf", "+inf"
+	if !query.Since.IsZero() {
+		minScore = strconv.FormatInt(query.Since.UnixMilli(), 10)
+	}
+	if !query.Until.IsZero() {
+		maxScore = "(" + strconv.FormatInt(query.Until.UnixMilli(), 10)
+	}
+
+	if query.UserIDs != nil && len(query.UserIDs) == 0 {
+		return store.ConversationList{}, nil
+	}
+
+	newestFirst := query.Order != store.OldestFirst
+	var (
+		cursorScore  float64
+		cursorMember string
+		ties         int64
+	)
+	if query.Cursor != "" {
+		cursorTime, userID, err := store.DecodeCursor(query.Cursor)
+		if err != nil {
+			return store.ConversationList{}, err
+		}
+		cursorScore, cursorMember = float64(cursorTime.UnixMilli()), userID
+		score := strconv.FormatInt(cursorTime.UnixMilli(), 10)
+		if newestFirst {
+			maxScore = score
+		} else {
+			minScore = score
+		}
+		// The bound is inclusive to resume within conversations sharing the cursor's score,
+		// so fetch enough to skip the ones already returned
+		ties, err = rdb.ZCount(ctx, s.client.key(conversationIndexKey), score, score).Result()
+		if err != nil {
+			return store.ConversationList{}, err
+		}
+	}
+
+	rangeBy := &redis.ZRangeBy{Min: minScore, Max: maxScore}
+	if query.Limit > 0 {
+		rangeBy.Count = int64(query.Limit) + 1 + ties
+	}
+	var entries []redis.Z
+	var err error
+	if query.UserIDs != nil {
+		entries, err = s.userEntries(ctx, query, newestFirst)
+	} else if newestFirst {
+		entries, err = rdb.ZRevRangeByScoreWithScores(ctx, s.client.key(conversationIndexKey), rangeBy).Result()
+	} else {
+		entries, err = rdb.ZRangeByScoreWithScores(ctx, s.client.key(conversationIndexKey), rangeBy).Result()
+	}
+	if err != nil {
+		return store.ConversationList{}, err
+	}
+
+	// Members with equal scores come in lexicographic order, reversed when newest first
+	var page []redis.Z
+	for _, entry := range entries {
+		member := entry.Member.(string)
+		if query.Cursor != "" {
+			// Entries past the cursor's score only come up in listings restricted to some users
+			if (newestFirst && entry.Score > cursorScore) || (!newestFirst && entry.Score < cursorScore) {
+				continue
+			}
+			if entry.Score == cursorScore && ((newestFirst && member >= cursorMember) || (!newestFirst && member <= cursorMember)) {
+				continue
+			}
+		}
+		page = append(page, entry)
+	}
+
+	list := store.ConversationList{}
+	if query.Limit > 0 && len(page) > query.Limit {
+		page = page[:query.Limit]
+		last := page[len(page)-1]
+		list.NextCursor = store.EncodeCursor(time.UnixMilli(int64(last.Score)), last.Member.(string))
+	}
+
+	pipe := rdb.Pipeline()
+	metas := make([]*redis.MapStringStringCmd, len(page))
+	for i, entry := range page {
+		metas[i] = pipe.HGetAll(ctx, s.client.conversationMetaKey(entry.Member.(string)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return store.ConversationList{}, err
+	}
+
+	for i, entry := range page {
+		userID := entry.Member.(string)
+		meta := metas[i].Val()
+		if len(meta) == 0 {
+			// The conversation expired or was deleted without going through the store
+			rdb.ZRem(ctx, s.client.key(conversationIndexKey), userID)
+			continue
+		}
+		lastMessageTime, _ := time.Parse(time.RFC3339Nano, meta["last_message_time"])
+		count, _ := strconv.Atoi(meta["message_count"])
+		list.Conversations = append(list.Conversations, store.ConversationSummary{
+			UserID:             userID,
+			LastMessageTime:    lastMessageTime,
+			LastMessagePreview: meta["preview"],
+			MessageCount:       count,
+		})
+	}
+
+	return list, nil
+}
+
+// userEntries returns the index entries of the query's users within its time bounds, in
+// listing order, for listings restricted to a few conversations. The cursor is applied by the caller.
+func (s *HistoryStore) userEntries(ctx context.Context, query store.ConversationQuery, newestFirst bool) ([]redis.Z, error) {
+	userIDs := query.UserIDs
+	pipe := s.client.rdb.Pipeline()
+	scores := make([]*redis.FloatCmd, len(userIDs))
+	for i, userID := range userIDs {
+		scores[i] = pipe.ZScore(ctx, s.client.key(conversationIndexKey), userID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	var entries []redis.Z
+	for i, userID := range userIDs {
+		score, err := scores[i].Result()
+		if err != nil {
+			continue
+		}
+		if (!query.Since.IsZero() && score < float64(query.Since.UnixMilli())) ||
+			(!query.Until.IsZero() && score >= float64(query.Until.UnixMilli())) {
+			continue
+		}
+		entries = append(entries, redis.Z{Score: score, Member: userID})
+	}
+
+	slices.SortFunc(entries, func(a, b redis.Z) int {
+		order := cmp.Or(cmp.Compare(a.Score, b.Score), strings.Compare(a.Member.(string), b.Member.(string)))
+		if newestFirst {
+			return -order
+		}
+		return order
+	})
+	return entries, nil
+}
+
+// RebuildIndex indexes the conversations stored before the index existed. It walks the
+// keyspace with SCAN, so it does not block Redis, and only runs when the index is empty.
+func (s *HistoryStore) RebuildIndex(ctx context.Context) error {
+	rdb := s.client.rdb
+
+	indexed, err := rdb.ZCard(ctx, s.client.key(conversationIndexKey)).Result()
+	if err != nil || indexed > 0 {
+		return err
+	}
+
+	rebuilt := 0
+	iter := rdb.Scan(ctx, 0, escapeGlob(s.client.chatHistoryKey(""))+"*", 500).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		userID := strings.TrimPrefix(key, s.client.chatHistoryKey(""))
+
+		count, err := rdb.LLen(ctx, key).Result()
+		if err != nil || count == 0 {
+			continue
+		}
+		lastMessageJSON, err := rdb.LIndex(ctx, key, -1).Result()
+		if err != nil {
+			continue
+		}
+		var lastMessage store.ChatMessage
+		if err := json.Unmarshal([]byte(lastMessageJSON), &lastMessage); err != nil {
+			log.Error().Err(err).Str("key", key).Msg("Error unmarshaling last message")
+			continue
+		}
+
+		pipe := rdb.TxPipeline()
+		pipe.ZAdd(ctx, s.client.key(conversationIndexKey), redis.Z{
+			Score:  float64(lastMessage.Timestamp.UnixMilli()),
+			Member: userID,
+		})
+		pipe.HSet(ctx, s.client.conversationMetaKey(userID),
+			"last_message_time", lastMessage.Timestamp.Format(time.RFC3339Nano),
+			"preview", store.Preview(lastMessage.Content),
+			"message_count", count,
+		)
+		if ttl, err := rdb.TTL(ctx, key).Result(); err == nil && ttl > 0 {
+			pipe.Expire(ctx, s.client.conversationMetaKey(userID), ttl)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		rebuilt++
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if rebuilt > 0 {
+		log.Info().Int("conversations", rebuilt).Msg("Rebuilt conversation index")
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	messageSearchIndexName = "chat_messages_idx"
+	messageDocPrefix       = "chat_message:"
+	// searchBatchSize is how many entries of a word index are checked per round trip
+	searchBatchSize = 100
+)
+
+// messageDocKey is the hash holding a searchable copy of the message at position in the conversation.
+func (c *Client) messageDocKey(userID string, position int) string {
+	return c.key(fmt.Sprintf("%s%s:%d", messageDocPrefix, userID, position))
+}
+
+// searchTermKey is the sorted set of the messages containing term, scored by timestamp.
+func (c *Client) searchTermKey(term string) string {
+	return c.key(fmt.Sprintf("chat_search:%s", term))
+}
+
+// userSearchTermKey is the sorted set of the user's messages containing term, scored by timestamp.
+func (c *Client) userSearchTermKey(userID, term string) string {
+	return c.key(fmt.Sprintf("chat_search_user:%s:%s", userID, term))
+}
+
+// userSearchTermsKey is the set of the words indexed for the user's messages.
+func (c *Client) userSearchTermsKey(userID string) string {
+	return c.key(fmt.Sprintf("chat_search_terms:%s", userID))
+}
+
+// EnsureSearchIndex creates the RediSearch index over messages. Without the search
+// module (plain Redis), the store keeps its own inverted index of words instead.
+func (s *HistoryStore) EnsureSearchIndex(ctx context.Context) error {
+	err := s.client.rdb.FTCreate(ctx, s.client.key(messageSearchIndexName),
+		&redis.FTCreateOptions{OnHash: true, Prefix: []any{s.client.key(messageDocPrefix)}, DefaultLanguage: "portuguese"},
+		&redis.FieldSchema{FieldName: "search_text", FieldType: redis.SearchFieldTypeText},
+		&redis.FieldSchema{FieldName: "user_id", FieldType: redis.SearchFieldTypeTag},
+		&redis.FieldSchema{FieldName: "timestamp", FieldType: redis.SearchFieldTypeNumeric, Sortable: true},
+	).Err()
+	if err != nil && !strings.Contains(err.Error(), "Index already exists") {
+		if strings.Contains(strings.ToLower(err.Error()), "unknown command") {
+			log.Info().Msg("RediSearch not available, using inverted index for conversation search")
+			return nil
+		}
+		return err
+	}
+
+	s.rediSearch = true
+	log.Info().Msg("Using RediSearch for conversation search")
+	return nil
+}
+
+// indexMessage stores the searchable copy of a message. Copies expire after the retention
+// window from when they were sent, so old messages of long conversations stop being found.
+func (s *HistoryStore) indexMessage(ctx context.Context, userID string, position int, message store.ChatMessage) error {
+	docKey := s.client.messageDocKey(userID, position)
+
+	pipe := s.client.rdb.Pipeline()
+	pipe.HSet(ctx, docKey,
+		"user_id", userID,
+		"position", position,
+		"role", message.Role,
+		"content", message.Content,
+		"search_text", store.Fold(message.Content),
+		"message_uuid", message.MessageUUID,
+		"agent", message.Agent,
+		"model", message.Model,
+		"timestamp", message.Timestamp.UnixMilli(),
+		"sent_at", message.Timestamp.Format(time.RFC3339Nano),
+	)
+	if s.retention > 0 {
+		pipe.Expire(ctx, docKey, s.retention)
+	}
+	if terms := store.Terms(message.Content); !s.rediSearch && len(terms) > 0 {
+		entry := redis.Z{Score: float64(message.Timestamp.UnixMilli()), Member: docKey}
+		cutoff := "(" + strconv.FormatInt(time.Now().Add(-s.retention).UnixMilli(), 10)
+		termsKey := s.client.userSearchTermsKey(userID)
+		for _, term := range terms {
+			pipe.SAdd(ctx, termsKey, term)
+			for _, key := range []string{s.client.searchTermKey(term), s.client.userSearchTermKey(userID, term)} {
+				pipe.ZAdd(ctx, key, entry)
+				if s.retention > 0 {
+					// Drop the entries of messages past retention so common words stay bounded
+					pipe.ZRemRangeByScore(ctx, key, "-inf", cutoff)
+					pipe.Expire(ctx, key, s.retention)
+				}
+			}
+		}
+		if s.retention > 0 {
+			pipe.Expire(ctx, termsKey, s.retention)
+		}
+	}
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// unindexConversation removes the user's messages from the inverted index, using the
+// words recorded for the user to find the entries in the shared word indexes.
+func (s *HistoryStore) unindexConversation(ctx context.Context, userID string) error {
+	rdb := s.client.rdb
+	termsKey := s.client.userSearchTermsKey(userID)
+
+	terms, err := rdb.SMembers(ctx, termsKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := rdb.Pipeline()
+	entries := make([]*redis.StringSliceCmd, len(terms))
+	for i, term := range terms {
+		entries[i] = pipe.ZRange(ctx, s.client.userSearchTermKey(userID, term), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	pipe = rdb.TxPipeline()
+	for i, term := range terms {
+		if docKeys := entries[i].Val(); len(docKeys) > 0 {
+			members := make([]any, len(docKeys))
+			for j, docKey := range docKeys {
+				members[j] = docKey
+			}
+			pipe.ZRem(ctx, s.client.searchTermKey(term), members...)
+		}
+		pipe.Del(ctx, s.client.userSearchTermKey(userID, term))
+	}
+	pipe.Del(ctx, termsKey)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// escapeGlob escapes the characters with a meaning in Redis MATCH patterns.
+func escapeGlob(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+// Search returns the most recent messages containing every word of the query.
+// With RediSearch words are stemmed, so "boletos" also finds "boleto".
+func (s *HistoryStore) Search(ctx context.Context, query store.SearchQuery) ([]store.SearchResult, error) {
+	terms := store.Terms(query.Text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = store.DefaultSearchLimit
+	}
+
+	if s.rediSearch {
+		return s.searchIndex(ctx, terms, query.UserID, limit)
+	}
+	return s.searchInverted(ctx, terms, query.UserID, limit)
+}
+
+func (s *HistoryStore) searchIndex(ctx context.Context, terms []string, userID string, limit int) ([]store.SearchResult, error) {
+	q := "@search_text:(" + strings.Join(terms, " ") + ")"
+	if userID != "" {
+		q += " @user_id:{" + escapeTag(userID) + "}"
+	}
+
+	result, err := s.client.rdb.FTSearchWithArgs(ctx, s.client.key(messageSearchIndexName), q,
+		&redis.FTSearchOptions{
+			SortBy:         []redis.FTSearchSortBy{{FieldName: "timestamp", Desc: true}},
+			Limit:          limit,
+			DialectVersion: 2,
+		},
+	).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]store.SearchResult, 0, len(result.Docs))
+	for _, doc := range result.Docs {
+		results = append(results, searchResult(doc.Fields))
+	}
+	return results, nil
+}
+
+// searchInverted walks the index of the rarest query word from the newest message,
+// keeping the messages that contain every other word, and fetches only those until
+// limit results are found. A user's searches use the user's own word indexes.
+func (s *HistoryStore) searchInverted(ctx context.Context, terms []string, userID string, limit int) ([]store.SearchResult, error) {
+	rdb := s.client.rdb
+
+	keys := make([]string, len(terms))
+	for i, term := range terms {
+		if userID != "" {
+			keys[i] = s.client.userSearchTermKey(userID, term)
+		} else {
+			keys[i] = s.client.searchTermKey(term)
+		}
+	}
+
+	pipe := rdb.Pipeline()
+	sizes := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		sizes[i] = pipe.ZCard(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	rarest := 0
+	for i, size := range sizes {
+		if size.Val() == 0 {
+			return nil, nil
+		}
+		if size.Val() < sizes[rarest].Val() {
+			rarest = i
+		}
+	}
+	others := append(append([]string{}, keys[:rarest]...), keys[rarest+1:]...)
+
+	var results []store.SearchResult
+	for offset := int64(0); len(results) < limit; offset += searchBatchSize {
+		docKeys, err := rdb.ZRevRange(ctx, keys[rarest], offset, offset+searchBatchSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(docKeys) == 0 {
+			break
+		}
+
+		matches, err := s.containedInAll(ctx, others, docKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		pipe := rdb.Pipeline()
+		docs := make([]*redis.MapStringStringCmd, len(matches))
+		for i, docKey := range matches {
+			docs[i] = pipe.HGetAll(ctx, docKey)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+
+		for i, doc := range docs {
+			fields := doc.Val()
+			if len(fields) == 0 {
+				// The message expired; drop it from the word indexes that still point to it
+				for _, key := range keys {
+					rdb.ZRem(ctx, key, matches[i])
+				}
+				continue
+			}
+			results = append(results, searchResult(fields))
+			if len(results) == limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// containedInAll returns the docKeys present in every one of the word indexes in keys.
+func (s *HistoryStore) containedInAll(ctx context.Context, keys []string, docKeys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return docKeys, nil
+	}
+
+	pipe := s.client.rdb.Pipeline()
+	scores := make([]*redis.FloatSliceCmd, len(keys))
+	for i, key := range keys {
+		scores[i] = pipe.ZMScore(ctx, key, docKeys...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for j, docKey := range docKeys {
+		found := true
+		for i := range keys {
+			// Missing members have no score; timestamps are never zero
+			if scores[i].Val()[j] == 0 {
+				found = false
+				break
+			}
+		}
+		if found {
+			matches = append(matches, docKey)
+		}
+	}
+	return matches, nil
+}
+
+// searchResult converts the fields of a message copy into a search result.
+func searchResult(fields map[string]string) store.SearchResult {
+	position, _ := strconv.Atoi(fields["position"])
+	sentAt, _ := time.Parse(time.RFC3339Nano, fields["sent_at"])
+	return store.SearchResult{
+		UserID:   fields["user_id"],
+		Position: position,
+		Message: store.ChatMessage{
+			Role:        fields["role"],
+			Content:     fields["content"],
+			Timestamp:   sentAt,
+			MessageUUID: fields["message_uuid"],
+			Agent:       fields["agent"],
+			Model:       fields["model"],
+		},
+	}
+}
+
+// escapeTag escapes the characters with a meaning in RediSearch tag queries.
+func escapeTag(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+)
+
+func TestSearchInverted(t *testing.T) {
+	client, server := newTestClient(t)
+	history := NewHistoryStore(client, time.Hour)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Minute)
+	messages := []struct{ userID, content string }{
+		{"5511", "Qual o valor do boleto?"},
+		{"5522", "Meu boleto venceu"},
+		{"5511", "Boleto pago, obrigado"},
+		{"5522", "Segunda via do boleto pago"},
+	}
+	for i, message := range messages {
+		msg := store.ChatMessage{Role: "user", Content: message.content, Timestamp: start.Add(time.Duration(i) * time.Second)}
+		if err := history.Append(ctx, message.userID, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(text, userID string, limit int) []string {
+		t.Helper()
+		results, err := history.Search(ctx, store.SearchQuery{Text: text, UserID: userID, Limit: limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var contents []string
+		for _, result := range results {
+			contents = append(contents, result.Message.Content)
+		}
+		return contents
+	}
+
+	if got := strings.Join(search("boleto pago", "", 0), "|"); got != "Segunda via do boleto pago|Boleto pago, obrigado" {
+		t.Errorf("search = %q, want the messages with both words, newest first", got)
+	}
+	if got := strings.Join(search("boleto", "", 2), "|"); got != "Segunda via do boleto pago|Boleto pago, obrigado" {
+		t.Errorf("search = %q, want the 2 newest matches", got)
+	}
+	if got := strings.Join(search("boleto", "5511", 0), "|"); got != "Boleto pago, obrigado|Qual o valor do boleto?" {
+		t.Errorf("search = %q, want only the user's messages", got)
+	}
+
+	// Deleting a conversation removes its entries without touching the others
+	if err := history.Delete(ctx, "5511"); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(search("boleto", "", 0), "|"); got != "Segunda via do boleto pago|Meu boleto venceu" {
+		t.Errorf("search = %q after deletion, want only the other user's messages", got)
+	}
+	for _, key := range server.Keys() {
+		if strings.Contains(key, "5511") {
+			t.Errorf("key %s left after deletion", key)
+		}
+	}
+	members, err := server.ZMembers("chat_search:boleto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range members {
+		if strings.Contains(member, "5511") {
+			t.Errorf("word index still points to %s", member)
+		}
+	}
+}
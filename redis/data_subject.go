@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+func (c *Client) erasureKey(userID string) string {
+	return c.key(fmt.Sprintf("erasing:%s", userID))
+}
+
+// BeginErasure marks the user's data as being erased until EndErasure is called or ttl
+// passes. It reports false when an erasure of the user is already in progress.
+func (c *Client) BeginErasure(userID string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(c.ctx, c.erasureKey(userID), time.Now().Format(time.RFC3339), ttl).Result()
+}
+
+// EndErasure clears the mark set by BeginErasure.
+func (c *Client) EndErasure(userID string) error {
+	return c.rdb.Del(c.ctx, c.erasureKey(userID)).Err()
+}
+
+// ErasureInProgress reports whether the user's data is being erased.
+func (c *Client) ErasureInProgress(userID string) (bool, error) {
+	count, err := c.rdb.Exists(c.ctx, c.erasureKey(userID)).Result()
+	return count > 0, err
+}
+
+// GetUserEscalations returns every escalation of the user still stored, including the
+// resolved ones kept for reference, oldest first. It scans the escalations, so it is
+// meant for data subject requests rather than frequent use.
+func (c *Client) GetUserEscalations(userID string) ([]Escalation, error) {
+	var escalations []Escalation
+	iter := c.rdb.Scan(c.ctx, 0, c.escalationKey("*"), 500).Iterator()
+	for iter.Next(c.ctx) {
+		key := iter.Val()
+		if strings.HasPrefix(key, c.userEscalationKey("")) {
+			continue
+		}
+		escalationJSON, err := c.rdb.Get(c.ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		var escalation Escalation
+		if err := json.Unmarshal([]byte(escalationJSON), &escalation); err != nil || escalation.UserID != userID {
+			continue
+		}
+		escalations = append(escalations, escalation)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(escalations, func(i, j int) bool {
+		return escalations[i].CreatedAt.Before(escalations[j].CreatedAt)
+	})
+	return escalations, nil
+}
+
+// EraseUserData deletes everything the bot keeps about the user outside the conversation
+// store: memories, tags, notes, attributes, delivery statuses, summaries, pauses,
+// escalations, async tool jobs, cached tool results, scheduled work, usage and the list of
+// generated audio. The audit log of the user is kept, as a record of who accessed the data
+// and of the erasure itself.
+func (c *Client) EraseUserData(userID string) error {
+	tags, err := c.GetConversationTags(userID)
+	if err != nil {
+		return err
+	}
+	escalations, err := c.GetUserEscalations(userID)
+	if err != nil {
+		return err
+	}
+	jobIDs, err := c.rdb.SMembers(c.ctx, c.userToolJobsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	toolKeys, err := c.userToolKeys(userID)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		c.userMemoryKey(userID),
+		c.key(fmt.Sprintf("user_memory_extracted:%s", userID)),
+		c.conversationTagsKey(userID),
+		c.conversationNotesKey(userID),
+		c.conversationAttributesKey(userID),
+		c.deliveryStatusKey(userID),
+		c.historySummaryKey(userID),
+		c.key(fmt.Sprintf("chat_summary_lock:%s", userID)),
+		c.botPauseKey(userID),
+		c.userEscalationKey(userID),
+		c.userAudioKey(userID),
+		c.awaitingReplyKey(userID),
+		c.userToolJobsKey(userID),
+	}
+	for _, escalation := range escalations {
+		keys = append(keys, c.escalationKey(escalation.ID))
+	}
+	for _, jobID := range jobIDs {
+		keys = append(keys, c.toolJobKey(jobID))
+	}
+	keys = append(keys, toolKeys...)
+
+	pipe := c.rdb.TxPipeline()
+	pipe.Del(c.ctx, keys...)
+	pipe.ZRem(c.ctx, c.key(memoryExtractionKey), userID)
+	for _, escalation := range escalations {
+		pipe.ZRem(c.ctx, c.key(escalationQueueKey), escalation.ID)
+	}
+	for _, tag := range tags {
+		pipe.SRem(c.ctx, c.taggedConversationsKey(tag), userID)
+	}
+	// Pending jobs are dropped too, so no worker runs them after the erasure
+	for _, jobID := range jobIDs {
+		pipe.LRem(c.ctx, c.key(toolJobQueueKey), 0, jobID)
+		pipe.LRem(c.ctx, c.key(toolJobProcessingKey), 0, jobID)
+		pipe.ZRem(c.ctx, c.key(toolJobLeasesKey), jobID)
+		pipe.ZRem(c.ctx, c.key(toolJobDelayedKey), jobID)
+	}
+	if _, err := pipe.Exec(c.ctx); err != nil {
+		return err
+	}
+
+	if err := c.deleteUserUsage(userID); err != nil {
+		return err
+	}
+
+	// Tags no longer used by any conversation are dropped from the list of tags
+	for _, tag := range tags {
+		if count, err := c.rdb.SCard(c.ctx, c.taggedConversationsKey(tag)).Result(); err == nil && count == 0 {
+			c.rdb.SRem(c.ctx, c.key(conversationTagsIndexKey), tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DeliveryStatus is the last status reported by the channel for a message sent to a user.
+type DeliveryStatus struct {
+	MessageUUID string    `json:"message_uuid"`
+	Status      string    `json:"status"`
+	Timestamp   time.Time `json:"timestamp"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// deliveryStatusTTL is how long delivery statuses are kept after the last status of the user.
+const deliveryStatusTTL = 30 * 24 * time.Hour
+
+func (c *Client) deliveryStatusKey(userID string) string {
+	return c.key(fmt.Sprintf("delivery_status:%s", userID))
+}
+
+// SaveDeliveryStatus records the latest status of a message sent to the user.
+// Statuses can arrive out of order, so one older than the stored status is ignored.
+func (c *Client) SaveDeliveryStatus(userID string, status DeliveryStatus) error {
+	existingJSON, err := c.rdb.HGet(c.ctx, c.deliveryStatusKey(userID), status.MessageUUID).Result()
+	if err == nil {
+		var existing DeliveryStatus
+		if json.Unmarshal([]byte(existingJSON), &existing) == nil && existing.Timestamp.After(status.Timestamp) {
+			return nil
+		}
+	}
+
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.HSet(c.ctx, c.deliveryStatusKey(userID), status.MessageUUID, statusJSON)
+	pipe.Expire(c.ctx, c.deliveryStatusKey(userID), deliveryStatusTTL)
+	_, err = pipe.Exec(c.ctx)
+	return err
+}
+
+// GetDeliveryStatuses returns the latest status of each message sent to the user, oldest first.
+func (c *Client) GetDeliveryStatuses(userID string) ([]DeliveryStatus, error) {
+	values, err := c.rdb.HGetAll(c.ctx, c.deliveryStatusKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]DeliveryStatus, 0, len(values))
+	for _, value := range values {
+		var status DeliveryStatus
+		if err := json.Unmarshal([]byte(value), &status); err != nil {
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Timestamp.Before(statuses[j].Timestamp)
+	})
+	return statuses, nil
+}
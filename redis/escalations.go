@@ -0,0 +1,225 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Escalation priorities, highest first in the queue.
+const (
+	EscalationUrgent = "urgent"
+	EscalationHigh   = "high"
+	EscalationNormal = "normal"
+	EscalationLow    = "low"
+)
+
+// Escalation statuses.
+const (
+	EscalationOpen     = "open"
+	EscalationClaimed  = "claimed"
+	EscalationResolved = "resolved"
+)
+
+const (
+	escalationQueueKey = "escalations:queue"
+	// resolvedEscalationTTL keeps resolved escalations around for reference
+	resolvedEscalationTTL = 7 * 24 * time.Hour
+)
+
+// Escalation is a conversation the model handed over to a human agent.
+type Escalation struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	Reason     string    `json:"reason"`
+	Priority   string    `json:"priority"`
+	Status     string    `json:"status"`
+	ClaimedBy  string    `json:"claimed_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	ResolvedAt time.Time `json:"resolved_at,omitzero"`
+}
+
+// ErrEscalationNotFound is returned for unknown escalation IDs.
+var ErrEscalationNotFound = errors.New("escalation not found")
+
+func (c *Client) escalationKey(id string) string {
+	return c.key(fmt.Sprintf("escalation:%s", id))
+}
+
+func (c *Client) userEscalationKey(userID string) string {
+	return c.key(fmt.Sprintf("escalation:user:%s", userID))
+}
+
+// escalationPriorityRank orders priorities, unknown ones counting as normal.
+func escalationPriorityRank(priority string) int {
+	switch priority {
+	case EscalationUrgent:
+		return 3
+	case EscalationHigh:
+		return 2
+	case EscalationLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// escalationScore sorts the queue by priority, then oldest first.
+func escalationScore(escalation Escalation) float64 {
+	return float64(int64(3-escalationPriorityRank(escalation.Priority))*1e13 + escalation.CreatedAt.UnixMilli())
+}
+
+// AddEscalation puts the user's conversation in the escalation queue. If the user already
+// has a pending escalation it is updated with the new reason and the higher priority.
+func (c *Client) AddEscalation(userID, reason, priority string) (Escalation, error) {
+	now := time.Now()
+	if escalationPriorityRank(priority) == 1 {
+		priority = EscalationNormal
+	}
+
+	escalation, found, err := c.pendingEscalation(userID)
+	if err != nil {
+		return Escalation{}, err
+	}
+	if found {
+		escalation.Reason = reason
+		if escalationPriorityRank(priority) > escalationPriorityRank(escalation.Priority) {
+			escalation.Priority = priority
+		}
+	} else {
+		escalation = Escalation{
+			ID:        strings.ToLower(rand.Text()[:10]),
+			UserID:    userID,
+			Reason:    reason,
+			Priority:  priority,
+			Status:    EscalationOpen,
+			CreatedAt: now,
+		}
+	}
+	escalation.UpdatedAt = now
+
+	escalationJSON, err := json.Marshal(escalation)
+	if err != nil {
+		return Escalation{}, err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(c.ctx, c.escalationKey(escalation.ID), escalationJSON, 0)
+	pipe.Set(c.ctx, c.userEscalationKey(userID), escalation.ID, 0)
+	pipe.ZAdd(c.ctx, c.key(escalationQueueKey), redis.Z{Score: escalationScore(escalation), Member: escalation.ID})
+	if _, err := pipe.Exec(c.ctx); err != nil {
+		return Escalation{}, err
+	}
+	return escalation, nil
+}
+
+// pendingEscalation returns the open or claimed escalation of the user, if any.
+func (c *Client) pendingEscalation(userID string) (Escalation, bool, error) {
+	id, err := c.rdb.Get(c.ctx, c.userEscalationKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return Escalation{}, false, nil
+	}
+	if err != nil {
+		return Escalation{}, false, err
+	}
+
+	escalation, err := c.GetEscalation(id)
+	if errors.Is(err, ErrEscalationNotFound) {
+		return Escalation{}, false, nil
+	}
+	if err != nil {
+		return Escalation{}, false, err
+	}
+	return escalation, escalation.Status != EscalationResolved, nil
+}
+
+// GetEscalation returns the escalation with the given ID.
+func (c *Client) GetEscalation(id string) (Escalation, error) {
+	escalationJSON, err := c.rdb.Get(c.ctx, c.escalationKey(id)).Result()
+	if errors.Is(err, redis.Nil) {
+		return Escalation{}, ErrEscalationNotFound
+	}
+	if err != nil {
+		return Escalation{}, err
+	}
+
+	var escalation Escalation
+	if err := json.Unmarshal([]byte(escalationJSON), &escalation); err != nil {
+		return Escalation{}, err
+	}
+	return escalation, nil
+}
+
+// GetEscalationQueue returns the pending escalations, highest priority and oldest first.
+func (c *Client) GetEscalationQueue() ([]Escalation, error) {
+	ids, err := c.rdb.ZRange(c.ctx, c.key(escalationQueueKey), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	escalations := []Escalation{}
+	for _, id := range ids {
+		escalation, err := c.GetEscalation(id)
+		if errors.Is(err, ErrEscalationNotFound) {
+			c.rdb.ZRem(c.ctx, c.key(escalationQueueKey), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		escalations = append(escalations, escalation)
+	}
+	return escalations, nil
+}
+
+// ClaimEscalation assigns a pending escalation to an agent.
+func (c *Client) ClaimEscalation(id, agent string) (Escalation, error) {
+	escalation, err := c.GetEscalation(id)
+	if err != nil {
+		return Escalation{}, err
+	}
+	if escalation.Status == EscalationResolved {
+		return Escalation{}, ErrEscalationNotFound
+	}
+
+	escalation.Status = EscalationClaimed
+	escalation.ClaimedBy = agent
+	escalation.UpdatedAt = time.Now()
+	return escalation, c.saveEscalation(escalation, 0)
+}
+
+// ResolveEscalation removes an escalation from the queue. It is kept for a week for reference.
+func (c *Client) ResolveEscalation(id string) (Escalation, error) {
+	escalation, err := c.GetEscalation(id)
+	if err != nil {
+		return Escalation{}, err
+	}
+
+	now := time.Now()
+	escalation.Status = EscalationResolved
+	escalation.UpdatedAt = now
+	escalation.ResolvedAt = now
+	if err := c.saveEscalation(escalation, resolvedEscalationTTL); err != nil {
+		return Escalation{}, err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.ZRem(c.ctx, c.key(escalationQueueKey), id)
+	pipe.Del(c.ctx, c.userEscalationKey(escalation.UserID))
+	_, err = pipe.Exec(c.ctx)
+	return escalation, err
+}
+
+func (c *Client) saveEscalation(escalation Escalation, ttl time.Duration) error {
+	escalationJSON, err := json.Marshal(escalation)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(c.ctx, c.escalationKey(escalation.ID), escalationJSON, ttl).Err()
+}
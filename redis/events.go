@@ -0,0 +1,49 @@
+package redis
+
+import "sync"
+
+const eventsChannel = "crm_events"
+
+// PublishEvent broadcasts an event payload to the subscribers of every server instance.
+func (c *Client) PublishEvent(payload []byte) error {
+	return c.rdb.Publish(c.ctx, c.key(eventsChannel), payload).Err()
+}
+
+// SubscribeEvents returns the event payloads published from now on and a function that
+// ends the subscription.
+func (c *Client) SubscribeEvents() (<-chan string, func(), error) {
+	return c.subscribe(c.key(eventsChannel))
+}
+
+// subscribe returns the payloads published on channel from now on and a function that
+// ends the subscription.
+func (c *Client) subscribe(channel string) (<-chan string, func(), error) {
+	pubsub := c.rdb.Subscribe(c.ctx, channel)
+	// Wait for the subscription so no event published after this returns is missed
+	if _, err := pubsub.Receive(c.ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, err
+	}
+
+	payloads := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		defer close(payloads)
+		for message := range pubsub.Channel() {
+			select {
+			case payloads <- message.Payload:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			close(done)
+			pubsub.Close()
+		})
+	}
+	return payloads, unsubscribe, nil
+}
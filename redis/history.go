@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultHistoryRetention is how long a conversation is kept after its last message
+// when no retention is configured.
+const DefaultHistoryRetention = 24 * time.Hour
+
+// HistoryStore is the Redis implementation of store.ConversationStore. Each conversation
+// is a list of JSON-encoded messages under chat_history:{userID} that expires after
+// the retention window without new messages. Conversations are indexed by last message
+// time in the chat_conversations sorted set, with their preview and message count in
+// chat_conversation:{userID}, so listings never scan the keyspace.
+type HistoryStore struct {
+	client    Client
+	retention time.Duration
+	// rediSearch is set by EnsureSearchIndex when the search module is available
+	rediSearch bool
+}
+
+// NewHistoryStore returns a conversation store backed by the client. A zero or
+// negative retention keeps conversations until they are deleted.
+func NewHistoryStore(client Client, retention time.Duration) *HistoryStore {
+	return &HistoryStore{client: client, retention: retention}
+}
+
+func (c *Client) chatHistoryKey(userID string) string {
+	return c.key(fmt.Sprintf("chat_history:%s", userID))
+}
+
+// Append adds a message to the user's conversation, updates the conversation index
+// and refreshes the expiry, all in one round trip.
+func (s *HistoryStore) Append(ctx context.Context, userID string, message store.ChatMessage) error {
+	messageJSON, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	pipe := s.client.rdb.TxPipeline()
+	length := pipe.RPush(ctx, s.client.chatHistoryKey(userID), messageJSON)
+	pipe.ZAdd(ctx, s.client.key(conversationIndexKey), redis.Z{
+		Score:  float64(message.Timestamp.UnixMilli()),
+		Member: userID,
+	})
+	pipe.HSet(ctx, s.client.conversationMetaKey(userID),
+		"last_message_time", message.Timestamp.Format(time.RFC3339Nano),
+		"preview", store.Preview(message.Content),
+	)
+	pipe.HIncrBy(ctx, s.client.conversationMetaKey(userID), "message_count", 1)
+	for _, key := range []string{s.client.chatHistoryKey(userID), s.client.historySummaryKey(userID), s.client.conversationMetaKey(userID)} {
+		if s.retention > 0 {
+			pipe.Expire(ctx, key, s.retention)
+		} else {
+			pipe.Persist(ctx, key)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return s.indexMessage(ctx, userID, int(length.Val())-1, message)
+}
+
+// Range returns up to limit messages of the conversation starting at offset.
+func (s *HistoryStore) Range(ctx context.Context, userID string, offset, limit int) ([]store.ChatMessage, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(offset + limit - 1)
+	}
+
+	messages, err := s.client.rdb.LRange(ctx, s.client.chatHistoryKey(userID), int64(offset), stop).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var chatHistory []store.ChatMessage
+	for _, message := range messages {
+		var msg store.ChatMessage
+		if err := json.Unmarshal([]byte(message), &msg); err != nil {
+			log.Error().Err(err).Str("user_id", userID).Msg("Error unmarshaling message")
+			continue
+		}
+		chatHistory = append(chatHistory, msg)
+	}
+
+	return chatHistory, nil
+}
+
+// Paginate returns a page of the user's conversation.
+func (s *HistoryStore) Paginate(ctx context.Context, userID string, page, pageSize int) (store.PaginatedMessages, error) {
+	totalCount, err := s.client.rdb.LLen(ctx, s.client.chatHistoryKey(userID)).Result()
+	if err != nil {
+		return store.PaginatedMessages{}, err
+	}
+
+	page, totalPages, offset := store.PageBounds(int(totalCount), page, pageSize)
+	if totalCount == 0 {
+		return store.PaginatedMessages{
+			Messages: []store.ChatMessage{},
+			Page:     page,
+		}, nil
+	}
+
+	messages, err := s.Range(ctx, userID, offset, pageSize)
+	if err != nil {
+		return store.PaginatedMessages{}, err
+	}
+
+	return store.PaginatedMessages{
+		Messages:      messages,
+		TotalMessages: int(totalCount),
+		Page:          page,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// Delete removes the user's conversation, its rolling summary, its index entry and
+// the searchable copies of its messages.
+func (s *HistoryStore) Delete(ctx context.Context, userID string) error {
+	length, err := s.client.rdb.LLen(ctx, s.client.chatHistoryKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{s.client.chatHistoryKey(userID), s.client.historySummaryKey(userID), s.client.conversationMetaKey(userID)}
+	for position := range int(length) {
+		keys = append(keys, s.client.messageDocKey(userID, position))
+	}
+
+	pipe := s.client.rdb.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.ZRem(ctx, s.client.key(conversationIndexKey), userID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if s.rediSearch {
+		return nil
+	}
+	return s.unindexConversation(ctx, userID)
+}
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// HistorySummary is the rolling summary of the oldest part of a conversation.
+type HistorySummary = store.HistorySummary
+
+func (c *Client) historySummaryKey(userID string) string {
+	return c.key(fmt.Sprintf("chat_summary:%s", userID))
+}
+
+// GetHistorySummary returns the rolling summary of the user's conversation, if any.
+func (c *Client) GetHistorySummary(userID string) (HistorySummary, bool, error) {
+	summaryJSON, err := c.rdb.Get(c.ctx, c.historySummaryKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return HistorySummary{}, false, nil
+	}
+	if err != nil {
+		return HistorySummary{}, false, err
+	}
+
+	var summary HistorySummary
+	if err := json.Unmarshal([]byte(summaryJSON), &summary); err != nil {
+		return HistorySummary{}, false, err
+	}
+	return summary, true, nil
+}
+
+// SaveHistorySummary stores the rolling summary. It keeps the key's current expiry,
+// which HistoryStore refreshes along with the chat history on every new message.
+func (c *Client) SaveHistorySummary(userID string, summary HistorySummary) error {
+	summary.UpdatedAt = time.Now()
+
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Set(c.ctx, c.historySummaryKey(userID), summaryJSON, redis.KeepTTL).Err()
+}
+
+// GetHistorySummary returns the rolling summary of the user's conversation, if any.
+func (s *HistoryStore) GetHistorySummary(ctx context.Context, userID string) (HistorySummary, bool, error) {
+	return s.client.GetHistorySummary(userID)
+}
+
+// SaveHistorySummary stores the rolling summary next to the conversation, which Delete
+// removes together with it.
+func (s *HistoryStore) SaveHistorySummary(ctx context.Context, userID string, summary HistorySummary) error {
+	return s.client.SaveHistorySummary(userID, summary)
+}
+
+// AcquireHistorySummaryLock makes sure only one summarization runs per user at a time.
+// It returns false when another one is already in progress.
+func (c *Client) AcquireHistorySummaryLock(userID string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(c.ctx, c.key(fmt.Sprintf("chat_summary_lock:%s", userID)), "1", ttl).Result()
+}
+
+// ReleaseHistorySummaryLock releases the lock taken by AcquireHistorySummaryLock.
+func (c *Client) ReleaseHistorySummaryLock(userID string) error {
+	return c.rdb.Del(c.ctx, c.key(fmt.Sprintf("chat_summary_lock:%s", userID))).Err()
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	knowledgeIndexName   = "knowledge_idx"
+	knowledgeChunkPrefix = "knowledge:chunk:"
+)
+
+// KnowledgeChunk is a piece of a knowledge base document with its embedding.
+type KnowledgeChunk struct {
+	ID        string
+	Source    string
+	Index     int
+	Text      string
+	Embedding []float32
+}
+
+// KnowledgeMatch is a chunk returned by a vector search, with its cosine similarity to the query.
+type KnowledgeMatch struct {
+	ID         string
+	Source     string
+	Index      int
+	Text       string
+	Similarity float64
+}
+
+// EnsureKnowledgeIndex creates the RediSearch vector index for knowledge chunks if it does not exist.
+// It requires Redis Stack or Redis 8 with the search module.
+func (c *Client) EnsureKnowledgeIndex(dimensions int) error {
+	err := c.rdb.FTCreate(c.ctx, c.key(knowledgeIndexName),
+		&redis.FTCreateOptions{OnHash: true, Prefix: []any{c.key(knowledgeChunkPrefix)}},
+		&redis.FieldSchema{FieldName: "text", FieldType: redis.SearchFieldTypeText},
+		&redis.FieldSchema{FieldName: "source", FieldType: redis.SearchFieldTypeTag},
+		&redis.FieldSchema{FieldName: "embedding", FieldType: redis.SearchFieldTypeVector, VectorArgs: &redis.FTVectorArgs{
+			HNSWOptions: &redis.FTHNSWOptions{Type: "FLOAT32", Dim: dimensions, DistanceMetric: "COSINE"},
+		}},
+	).Err()
+	if err != nil && strings.Contains(err.Error(), "Index already exists") {
+		return nil
+	}
+	return err
+}
+
+// AddKnowledgeChunks stores chunks in the vector index. Chunks are kept without TTL and
+// tracked per source so a document can be replaced or removed as a whole.
+func (c *Client) AddKnowledgeChunks(chunks []KnowledgeChunk) error {
+	pipe := c.rdb.TxPipeline()
+	for _, chunk := range chunks {
+		key := c.key(knowledgeChunkPrefix) + chunk.ID
+		pipe.HSet(c.ctx, key,
+			"source", chunk.Source,
+			"index", chunk.Index,
+			"text", chunk.Text,
+			"embedding", encodeVector(chunk.Embedding),
+		)
+		pipe.SAdd(c.ctx, c.knowledgeSourceKey(chunk.Source), key)
+	}
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// SearchKnowledge returns the k chunks closest to the query vector, most similar first.
+func (c *Client) SearchKnowledge(vector []float32, k int) ([]KnowledgeMatch, error) {
+	result, err := c.rdb.FTSearchWithArgs(c.ctx, c.key(knowledgeIndexName),
+		fmt.Sprintf("*=>[KNN %d @embedding $vector AS distance]", k),
+		&redis.FTSearchOptions{
+			Params:         map[string]any{"vector": encodeVector(vector)},
+			SortBy:         []redis.FTSearchSortBy{{FieldName: "distance", Asc: true}},
+			Return:         []redis.FTSearchReturn{{FieldName: "source"}, {FieldName: "index"}, {FieldName: "text"}, {FieldName: "distance"}},
+			Limit:          k,
+			DialectVersion: 2,
+		},
+	).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]KnowledgeMatch, 0, len(result.Docs))
+	for _, doc := range result.Docs {
+		index, _ := strconv.Atoi(doc.Fields["index"])
+		distance, _ := strconv.ParseFloat(doc.Fields["distance"], 64)
+		matches = append(matches, KnowledgeMatch{
+			ID:         strings.TrimPrefix(doc.ID, c.key(knowledgeChunkPrefix)),
+			Source:     doc.Fields["source"],
+			Index:      index,
+			Text:       doc.Fields["text"],
+			Similarity: 1 - distance,
+		})
+	}
+	return matches, nil
+}
+
+// DeleteKnowledgeSource removes all chunks ingested from source.
+func (c *Client) DeleteKnowledgeSource(source string) error {
+	sourceKey := c.knowledgeSourceKey(source)
+	keys, err := c.rdb.SMembers(c.ctx, sourceKey).Result()
+	if err != nil {
+		return err
+	}
+	return c.rdb.Del(c.ctx, append(keys, sourceKey)...).Err()
+}
+
+func (c *Client) knowledgeSourceKey(source string) string {
+	return c.key(fmt.Sprintf("knowledge:source:%s", source))
+}
+
+// encodeVector encodes a vector as little-endian float32 bytes, the format RediSearch expects.
+func encodeVector(vector []float32) []byte {
+	encoded := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(encoded[i*4:], math.Float32bits(value))
+	}
+	return encoded
+}
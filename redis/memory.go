@@ -0,0 +1,153 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Memory sources
+const (
+	MemorySourceTool       = "tool"
+	MemorySourceExtraction = "extraction"
+)
+
+const memoryExtractionKey = "user_memory:pending_extraction"
+
+// Memory is a long-term fact about a user, such as their name, preferences or past orders.
+// Memories are stored without TTL so they survive the 24-hour chat history expiration.
+type Memory struct {
+	ID        string    `json:"id"`
+	Content   string    `json:"content"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *Client) userMemoryKey(userID string) string {
+	return c.key(fmt.Sprintf("user_memory:%s", userID))
+}
+
+// AddUserMemory stores a new fact about the user. A fact identical to an existing one,
+// ignoring case and surrounding spaces, is not stored again and the existing one is returned.
+func (c *Client) AddUserMemory(userID, content, source string) (Memory, error) {
+	content = strings.TrimSpace(content)
+
+	memories, err := c.GetUserMemories(userID)
+	if err != nil {
+		return Memory{}, err
+	}
+	for _, memory := range memories {
+		if strings.EqualFold(memory.Content, content) {
+			return memory, nil
+		}
+	}
+
+	memory := Memory{
+		ID:        strings.ToLower(rand.Text()[:10]),
+		Content:   content,
+		Source:    source,
+		CreatedAt: time.Now(),
+	}
+
+	memoryJSON, err := json.Marshal(memory)
+	if err != nil {
+		return Memory{}, err
+	}
+
+	if err := c.rdb.HSet(c.ctx, c.userMemoryKey(userID), memory.ID, memoryJSON).Err(); err != nil {
+		return Memory{}, err
+	}
+	return memory, nil
+}
+
+// GetUserMemories returns all memories of the user, oldest first.
+func (c *Client) GetUserMemories(userID string) ([]Memory, error) {
+	values, err := c.rdb.HGetAll(c.ctx, c.userMemoryKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	memories := make([]Memory, 0, len(values))
+	for _, value := range values {
+		var memory Memory
+		if err := json.Unmarshal([]byte(value), &memory); err != nil {
+			continue
+		}
+		memories = append(memories, memory)
+	}
+
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].CreatedAt.Before(memories[j].CreatedAt)
+	})
+	return memories, nil
+}
+
+// DeleteUserMemory removes a single memory. It returns false if the memory did not exist.
+func (c *Client) DeleteUserMemory(userID, memoryID string) (bool, error) {
+	deleted, err := c.rdb.HDel(c.ctx, c.userMemoryKey(userID), memoryID).Result()
+	return deleted > 0, err
+}
+
+// ClearUserMemories removes every memory of the user.
+func (c *Client) ClearUserMemories(userID string) error {
+	return c.rdb.Del(c.ctx, c.userMemoryKey(userID)).Err()
+}
+
+// ScheduleMemoryExtraction marks the user's conversation for fact extraction once it has
+// been idle. Each new message pushes the extraction further into the future.
+func (c *Client) ScheduleMemoryExtraction(userID string, at time.Time) error {
+	return c.rdb.ZAdd(c.ctx, c.key(memoryExtractionKey), redis.Z{
+		Score:  float64(at.Unix()),
+		Member: userID,
+	}).Err()
+}
+
+// ClaimMemoryExtractions returns the users whose extraction is due and removes them from
+// the schedule. Removal is checked per user, so with several instances each user is
+// claimed by exactly one of them.
+func (c *Client) ClaimMemoryExtractions(now time.Time, limit int64) ([]string, error) {
+	userIDs, err := c.rdb.ZRangeByScore(c.ctx, c.key(memoryExtractionKey), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", now.Unix()),
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []string
+	for _, userID := range userIDs {
+		removed, err := c.rdb.ZRem(c.ctx, c.key(memoryExtractionKey), userID).Result()
+		if err != nil {
+			return claimed, err
+		}
+		if removed > 0 {
+			claimed = append(claimed, userID)
+		}
+	}
+	return claimed, nil
+}
+
+// GetMemoryExtractedAt returns the timestamp of the last message already processed
+// by memory extraction, or the zero time if none was.
+func (c *Client) GetMemoryExtractedAt(userID string) (time.Time, error) {
+	value, err := c.rdb.Get(c.ctx, c.key(fmt.Sprintf("user_memory_extracted:%s", userID))).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// SetMemoryExtractedAt records the timestamp of the last message processed by memory extraction.
+func (c *Client) SetMemoryExtractedAt(userID string, at time.Time) error {
+	return c.rdb.Set(c.ctx, c.key(fmt.Sprintf("user_memory_extracted:%s", userID)), at.Format(time.RFC3339Nano), 0).Err()
+}
@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Note is an internal note left by an agent on a conversation. Notes are never shown
+// to the user or the model and are stored without TTL.
+type Note struct {
+	ID        string    `json:"id"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ErrNoteNotFound is returned for unknown note IDs.
+var ErrNoteNotFound = errors.New("note not found")
+
+func (c *Client) conversationNotesKey(userID string) string {
+	return c.key(fmt.Sprintf("conversation_notes:%s", userID))
+}
+
+// AddConversationNote stores a new note on the user's conversation.
+func (c *Client) AddConversationNote(userID, author, content string) (Note, error) {
+	now := time.Now()
+	note := Note{
+		ID:        strings.ToLower(rand.Text()[:10]),
+		Author:    author,
+		Content:   strings.TrimSpace(content),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := c.saveConversationNote(userID, note); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// GetConversationNote returns a single note of the user's conversation.
+func (c *Client) GetConversationNote(userID, noteID string) (Note, error) {
+	noteJSON, err := c.rdb.HGet(c.ctx, c.conversationNotesKey(userID), noteID).Result()
+	if errors.Is(err, redis.Nil) {
+		return Note{}, ErrNoteNotFound
+	}
+	if err != nil {
+		return Note{}, err
+	}
+
+	var note Note
+	if err := json.Unmarshal([]byte(noteJSON), &note); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// GetConversationNotes returns the notes of the user's conversation, oldest first.
+func (c *Client) GetConversationNotes(userID string) ([]Note, error) {
+	values, err := c.rdb.HGetAll(c.ctx, c.conversationNotesKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]Note, 0, len(values))
+	for _, value := range values {
+		var note Note
+		if err := json.Unmarshal([]byte(value), &note); err != nil {
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].CreatedAt.Before(notes[j].CreatedAt)
+	})
+	return notes, nil
+}
+
+// UpdateConversationNote replaces the content of a note.
+func (c *Client) UpdateConversationNote(userID, noteID, content string) (Note, error) {
+	note, err := c.GetConversationNote(userID, noteID)
+	if err != nil {
+		return Note{}, err
+	}
+	note.Content = strings.TrimSpace(content)
+	note.UpdatedAt = time.Now()
+	if err := c.saveConversationNote(userID, note); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// DeleteConversationNote removes a note. It returns false if the note did not exist.
+func (c *Client) DeleteConversationNote(userID, noteID string) (bool, error) {
+	deleted, err := c.rdb.HDel(c.ctx, c.conversationNotesKey(userID), noteID).Result()
+	return deleted > 0, err
+}
+
+func (c *Client) saveConversationNote(userID string, note Note) error {
+	noteJSON, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	return c.rdb.HSet(c.ctx, c.conversationNotesKey(userID), note.ID, noteJSON).Err()
+}
@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// promptTemplateKey holds the Go template of the system prompt
+	promptTemplateKey = "prompt_template"
+	// disabledToolsKey is the set of registered tools switched off at runtime
+	disabledToolsKey = "disabled_tools"
+)
+
+// GetPromptTemplate returns the system prompt template, if one is stored.
+func (c *Client) GetPromptTemplate() (string, bool, error) {
+	text, err := c.rdb.Get(c.ctx, c.key(promptTemplateKey)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return text, true, nil
+}
+
+// SavePromptTemplate stores the system prompt template, replacing any previous one.
+func (c *Client) SavePromptTemplate(text string) error {
+	return c.rdb.Set(c.ctx, c.key(promptTemplateKey), text, 0).Err()
+}
+
+// GetDisabledTools returns the names of the tools switched off at runtime.
+func (c *Client) GetDisabledTools() ([]string, error) {
+	return c.rdb.SMembers(c.ctx, c.key(disabledToolsKey)).Result()
+}
+
+// SetToolEnabled switches a tool on or off for every server instance.
+func (c *Client) SetToolEnabled(name string, enabled bool) error {
+	if enabled {
+		return c.rdb.SRem(c.ctx, c.key(disabledToolsKey), name).Err()
+	}
+	return c.rdb.SAdd(c.ctx, c.key(disabledToolsKey), name).Err()
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Conversation tags are stored without TTL, like memories, so they outlive the chat history.
+const conversationTagsIndexKey = "conversation_tag_names"
+
+func (c *Client) conversationTagsKey(userID string) string {
+	return c.key(fmt.Sprintf("conversation_tags:%s", userID))
+}
+
+func (c *Client) taggedConversationsKey(tag string) string {
+	return c.key(fmt.Sprintf("tagged_conversations:%s", tag))
+}
+
+// NormalizeTag lowercases a tag and trims its spaces, so "VIP " and "vip" are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// AddConversationTags labels the user's conversation with the tags.
+func (c *Client) AddConversationTags(userID string, tags ...string) error {
+	pipe := c.rdb.TxPipeline()
+	for _, tag := range tags {
+		if tag = NormalizeTag(tag); tag == "" {
+			continue
+		}
+		pipe.SAdd(c.ctx, c.conversationTagsKey(userID), tag)
+		pipe.SAdd(c.ctx, c.taggedConversationsKey(tag), userID)
+		pipe.SAdd(c.ctx, c.key(conversationTagsIndexKey), tag)
+	}
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RemoveConversationTag removes a tag from the user's conversation. It returns false if
+// the conversation did not have the tag.
+func (c *Client) RemoveConversationTag(userID, tag string) (bool, error) {
+	tag = NormalizeTag(tag)
+	removed, err := c.rdb.SRem(c.ctx, c.conversationTagsKey(userID), tag).Result()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	if err := c.rdb.SRem(c.ctx, c.taggedConversationsKey(tag), userID).Err(); err != nil {
+		return true, err
+	}
+	// The tag is dropped from the list of tags once no conversation uses it
+	if count, err := c.rdb.SCard(c.ctx, c.taggedConversationsKey(tag)).Result(); err == nil && count == 0 {
+		c.rdb.SRem(c.ctx, c.key(conversationTagsIndexKey), tag)
+	}
+	return true, nil
+}
+
+// GetConversationTags returns the tags of the user's conversation, sorted.
+func (c *Client) GetConversationTags(userID string) ([]string, error) {
+	tags, err := c.rdb.SMembers(c.ctx, c.conversationTagsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// GetTaggedConversations returns the users whose conversation has the tag.
+func (c *Client) GetTaggedConversations(tag string) ([]string, error) {
+	return c.rdb.SMembers(c.ctx, c.taggedConversationsKey(NormalizeTag(tag))).Result()
+}
+
+// GetAllTags returns every tag in use, sorted.
+func (c *Client) GetAllTags() ([]string, error) {
+	tags, err := c.rdb.SMembers(c.ctx, c.key(conversationTagsIndexKey)).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// BotPause records that a human agent took over a conversation. The key expires after
+// the idle timeout without agent activity, which resumes the bot.
+type BotPause struct {
+	Agent    string    `json:"agent,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	PausedAt time.Time `json:"paused_at"`
+	// Escalated is set while an escalation waits for an agent; such a pause has no idle timeout
+	Escalated bool `json:"escalated,omitempty"`
+}
+
+func (c *Client) botPauseKey(userID string) string {
+	return c.key(fmt.Sprintf("bot_paused:%s", userID))
+}
+
+// PauseBot stops the bot from answering the user until ResumeBot is called or idleTimeout
+// passes without TouchBotPause. A zero idleTimeout keeps the bot paused until ResumeBot.
+func (c *Client) PauseBot(userID string, pause BotPause, idleTimeout time.Duration) error {
+	pauseJSON, err := json.Marshal(pause)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(c.ctx, c.botPauseKey(userID), pauseJSON, idleTimeout).Err()
+}
+
+// GetBotPause returns the pause of the user's conversation, if the bot is paused.
+func (c *Client) GetBotPause(userID string) (BotPause, bool, error) {
+	pauseJSON, err := c.rdb.Get(c.ctx, c.botPauseKey(userID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return BotPause{}, false, nil
+	}
+	if err != nil {
+		return BotPause{}, false, err
+	}
+
+	var pause BotPause
+	if err := json.Unmarshal([]byte(pauseJSON), &pause); err != nil {
+		return BotPause{}, false, err
+	}
+	return pause, true, nil
+}
+
+// TouchBotPause postpones the automatic resume of a paused conversation.
+func (c *Client) TouchBotPause(userID string, idleTimeout time.Duration) error {
+	return c.rdb.Expire(c.ctx, c.botPauseKey(userID), idleTimeout).Err()
+}
+
+// ResumeBot lets the bot answer the user again. It reports whether the bot was paused.
+func (c *Client) ResumeBot(userID string) (bool, error) {
+	deleted, err := c.rdb.Del(c.ctx, c.botPauseKey(userID)).Result()
+	return deleted > 0, err
+}
@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// tenantsKey maps each tenant ID to its JSON definition. Like the update channel it is
+	// never prefixed, since it describes the tenants rather than belonging to one.
+	tenantsKey          = "tenants"
+	tenantUpdateChannel = "tenant_updates"
+)
+
+// SaveTenant stores the JSON definition of a tenant, replacing any previous one.
+func (c *Client) SaveTenant(id string, definition []byte) error {
+	return c.rdb.HSet(c.ctx, tenantsKey, id, definition).Err()
+}
+
+// GetTenant returns the JSON definition of a tenant, or nil when it doesn't exist.
+func (c *Client) GetTenant(id string) ([]byte, error) {
+	definition, err := c.rdb.HGet(c.ctx, tenantsKey, id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return definition, err
+}
+
+// GetTenants returns the JSON definition of every tenant by ID.
+func (c *Client) GetTenants() (map[string]string, error) {
+	return c.rdb.HGetAll(c.ctx, tenantsKey).Result()
+}
+
+// DeleteTenant removes a tenant's definition. The tenant's data under its key prefix is kept.
+func (c *Client) DeleteTenant(id string) error {
+	return c.rdb.HDel(c.ctx, tenantsKey, id).Err()
+}
+
+// PublishTenantUpdate tells every server instance that the tenant was created, changed or deleted.
+func (c *Client) PublishTenantUpdate(id string) error {
+	return c.rdb.Publish(c.ctx, tenantUpdateChannel, id).Err()
+}
+
+// SubscribeTenantUpdates returns the IDs of the tenants updated from now on and a function
+// that ends the subscription.
+func (c *Client) SubscribeTenantUpdates() (<-chan string, func(), error) {
+	return c.subscribe(tenantUpdateChannel)
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetCachedToolResult returns the cached result stored under key, if present.
+func (c *Client) GetCachedToolResult(key string) (string, bool, error) {
+	result, err := c.rdb.Get(c.ctx, c.key(fmt.Sprintf("tool_cache:%s", key))).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return result, true, nil
+}
+
+// SetCachedToolResult stores a tool result under key for the given ttl.
+func (c *Client) SetCachedToolResult(key, result string, ttl time.Duration) error {
+	return c.rdb.Set(c.ctx, c.key(fmt.Sprintf("tool_cache:%s", key)), result, ttl).Err()
+}
+
+// CachedToolResult is a tool result cached for a user.
+type CachedToolResult struct {
+	Tool   string `json:"tool"`
+	Result string `json:"result"`
+}
+
+// userToolKeysPattern matches the keys under prefix built for the user by the tool
+// middlewares, whose keys have the form {tool}:{user}:{suffix}.
+func (c *Client) userToolKeysPattern(prefix, userID string) string {
+	return escapeGlob(c.key(prefix+":")) + "*:" + escapeGlob(userID) + ":*"
+}
+
+// GetUserCachedToolResults returns the tool results cached for the user. It scans the
+// cache, so it is meant for data subject requests rather than frequent use.
+func (c *Client) GetUserCachedToolResults(userID string) ([]CachedToolResult, error) {
+	var results []CachedToolResult
+	iter := c.rdb.Scan(c.ctx, 0, c.userToolKeysPattern("tool_cache", userID), 500).Iterator()
+	for iter.Next(c.ctx) {
+		key := iter.Val()
+		result, err := c.rdb.Get(c.ctx, key).Result()
+		if err != nil {
+			continue
+		}
+		tool, _, _ := strings.Cut(strings.TrimPrefix(key, c.key("tool_cache:")), ":")
+		results = append(results, CachedToolResult{Tool: tool, Result: result})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// userToolKeys returns the cached results and call counters of the user.
+func (c *Client) userToolKeys(userID string) ([]string, error) {
+	var keys []string
+	for _, prefix := range []string{"tool_cache", "tool_rate"} {
+		iter := c.rdb.Scan(c.ctx, 0, c.userToolKeysPattern(prefix, userID), 500).Iterator()
+		for iter.Next(c.ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// IncrementToolCallCount increments the call counter for key and returns the new value.
+// The counter expires after window, so each window starts from zero.
+func (c *Client) IncrementToolCallCount(key string, window time.Duration) (int64, error) {
+	redisKey := c.key(fmt.Sprintf("tool_rate:%s", key))
+
+	pipe := c.rdb.TxPipeline()
+	incr := pipe.Incr(c.ctx, redisKey)
+	pipe.Expire(c.ctx, redisKey, window)
+	if _, err := pipe.Exec(c.ctx); err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
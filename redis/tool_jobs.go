@@ -0,0 +1,257 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Tool job statuses
+const (
+	ToolJobPending   = "pending"
+	ToolJobRunning   = "running"
+	ToolJobCompleted = "completed"
+	ToolJobFailed    = "failed"
+)
+
+const (
+	toolJobQueueKey      = "tool_jobs:queue"
+	toolJobProcessingKey = "tool_jobs:processing"
+	// toolJobLeasesKey scores each claimed job by when its lease expires, in Unix milliseconds
+	toolJobLeasesKey = "tool_jobs:leases"
+	// toolJobDelayedKey scores each job waiting to be retried by when it is due, in Unix milliseconds
+	toolJobDelayedKey = "tool_jobs:delayed"
+	toolJobTTL        = 7 * 24 * time.Hour
+)
+
+// ToolJob represents an asynchronous tool call executed by a background worker.
+// It keeps everything needed to run the tool and to replay the call to the model afterwards.
+type ToolJob struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	UserName   string `json:"user_name,omitempty"`
+	ToolName   string `json:"tool_name"`
+	ToolCallID string `json:"tool_call_id"`
+	Arguments  string `json:"arguments"`
+	Status     string `json:"status"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// DeliveryAttempts counts the deliveries of the result postponed because the user was busy
+	DeliveryAttempts int       `json:"delivery_attempts,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// EnqueueToolJob stores the job and pushes it onto the work queue.
+func (c *Client) EnqueueToolJob(job ToolJob) error {
+	job.Status = ToolJobPending
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+
+	if err := c.SaveToolJob(job); err != nil {
+		return err
+	}
+
+	return c.rdb.LPush(c.ctx, c.key(toolJobQueueKey), job.ID).Err()
+}
+
+// SaveToolJob persists the current state of a job and indexes it under its user, so the
+// jobs of a user can be exported and erased.
+func (c *Client) SaveToolJob(job ToolJob) error {
+	jobJSON, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(c.ctx, c.toolJobKey(job.ID), jobJSON, toolJobTTL)
+	pipe.SAdd(c.ctx, c.userToolJobsKey(job.UserID), job.ID)
+	pipe.Expire(c.ctx, c.userToolJobsKey(job.UserID), toolJobTTL)
+	_, err = pipe.Exec(c.ctx)
+	return err
+}
+
+// GetUserToolJobs returns the stored jobs of the user, oldest first.
+func (c *Client) GetUserToolJobs(userID string) ([]ToolJob, error) {
+	jobIDs, err := c.rdb.SMembers(c.ctx, c.userToolJobsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []ToolJob
+	for _, jobID := range jobIDs {
+		job, err := c.GetToolJob(jobID)
+		if errors.Is(err, redis.Nil) {
+			// The job expired
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// GetToolJob returns the job with the given ID.
+func (c *Client) GetToolJob(jobID string) (*ToolJob, error) {
+	jobJSON, err := c.rdb.Get(c.ctx, c.toolJobKey(jobID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var job ToolJob
+	if err := json.Unmarshal([]byte(jobJSON), &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ClaimToolJob waits up to timeout for a queued job, moves it to the processing list and
+// leases it for lease. The lease must be renewed while the job is processed, or another
+// instance requeues the job. It returns nil without error when no job became available.
+func (c *Client) ClaimToolJob(timeout, lease time.Duration) (*ToolJob, error) {
+	jobID, err := c.rdb.BLMove(c.ctx, c.key(toolJobQueueKey), c.key(toolJobProcessingKey), "RIGHT", "LEFT", timeout).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.rdb.ZAdd(c.ctx, c.key(toolJobLeasesKey), redis.Z{
+		Score:  float64(time.Now().Add(lease).UnixMilli()),
+		Member: jobID,
+	}).Err(); err != nil {
+		return nil, err
+	}
+
+	job, err := c.GetToolJob(jobID)
+	if err != nil {
+		c.FinishToolJob(jobID)
+		return nil, fmt.Errorf("failed to load tool job %s: %w", jobID, err)
+	}
+
+	return job, nil
+}
+
+// RenewToolJobLease extends the lease of a claimed job, unless it was already requeued.
+func (c *Client) RenewToolJobLease(jobID string, lease time.Duration) error {
+	return c.rdb.ZAddXX(c.ctx, c.key(toolJobLeasesKey), redis.Z{
+		Score:  float64(time.Now().Add(lease).UnixMilli()),
+		Member: jobID,
+	}).Err()
+}
+
+// FinishToolJob removes the job from the processing list once its result was delivered.
+func (c *Client) FinishToolJob(jobID string) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.LRem(c.ctx, c.key(toolJobProcessingKey), 0, jobID)
+	pipe.ZRem(c.ctx, c.key(toolJobLeasesKey), jobID)
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// RetryToolJobAt releases a claimed job and queues it again once at has passed.
+func (c *Client) RetryToolJobAt(jobID string, at time.Time) error {
+	pipe := c.rdb.TxPipeline()
+	pipe.LRem(c.ctx, c.key(toolJobProcessingKey), 0, jobID)
+	pipe.ZRem(c.ctx, c.key(toolJobLeasesKey), jobID)
+	pipe.ZAdd(c.ctx, c.key(toolJobDelayedKey), redis.Z{Score: float64(at.UnixMilli()), Member: jobID})
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// QueueDueToolJobs moves the jobs whose retry is due back to the work queue. Removal is
+// checked per job, so with several instances each job is queued by exactly one of them.
+func (c *Client) QueueDueToolJobs(now time.Time) (int, error) {
+	jobIDs, err := c.rdb.ZRangeByScore(c.ctx, c.key(toolJobDelayedKey), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now.UnixMilli()),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, jobID := range jobIDs {
+		removed, err := c.rdb.ZRem(c.ctx, c.key(toolJobDelayedKey), jobID).Result()
+		if err != nil {
+			return queued, err
+		}
+		if removed == 0 {
+			continue
+		}
+		if err := c.rdb.LPush(c.ctx, c.key(toolJobQueueKey), jobID).Err(); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// RequeueExpiredToolJobs moves the claimed jobs whose lease expired back to the work queue,
+// recovering the jobs of instances that stopped while processing them. Jobs other instances
+// are processing keep their lease and are left alone. A job claimed without a recorded lease
+// is given one of grace first, since its worker may be recording it right now.
+func (c *Client) RequeueExpiredToolJobs(now time.Time, grace time.Duration) (int, error) {
+	jobIDs, err := c.rdb.LRange(c.ctx, c.key(toolJobProcessingKey), 0, -1).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	requeued := 0
+	for _, jobID := range jobIDs {
+		expiresAt, err := c.rdb.ZScore(c.ctx, c.key(toolJobLeasesKey), jobID).Result()
+		if errors.Is(err, redis.Nil) {
+			err = c.rdb.ZAddNX(c.ctx, c.key(toolJobLeasesKey), redis.Z{
+				Score:  float64(now.Add(grace).UnixMilli()),
+				Member: jobID,
+			}).Err()
+			if err != nil {
+				return requeued, err
+			}
+			continue
+		}
+		if err != nil {
+			return requeued, err
+		}
+		if expiresAt > float64(now.UnixMilli()) {
+			continue
+		}
+
+		// Only the instance that removes the job from the processing list requeues it
+		removed, err := c.rdb.LRem(c.ctx, c.key(toolJobProcessingKey), 1, jobID).Result()
+		if err != nil {
+			return requeued, err
+		}
+		pipe := c.rdb.TxPipeline()
+		pipe.ZRem(c.ctx, c.key(toolJobLeasesKey), jobID)
+		if removed > 0 {
+			pipe.LPush(c.ctx, c.key(toolJobQueueKey), jobID)
+		}
+		if _, err := pipe.Exec(c.ctx); err != nil {
+			return requeued, err
+		}
+		if removed > 0 {
+			requeued++
+		}
+	}
+	return requeued, nil
+}
+
+func (c *Client) toolJobKey(jobID string) string {
+	return c.key(fmt.Sprintf("tool_job:%s", jobID))
+}
+
+func (c *Client) userToolJobsKey(userID string) string {
+	return c.key(fmt.Sprintf("tool_jobs_user:%s", userID))
+}
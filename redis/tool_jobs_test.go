@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+func enqueueTestJob(t *testing.T, c *Client, id string) {
+	t.Helper()
+	if err := c.EnqueueToolJob(ToolJob{ID: id, UserID: "5511", ToolName: "generate_quote"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClaimToolJob(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	if job, err := c.ClaimToolJob(time.Second, time.Minute); err != nil || job != nil {
+		t.Fatalf("ClaimToolJob on an empty queue = %v, %v", job, err)
+	}
+
+	enqueueTestJob(t, &c, "job-1")
+	job, err := c.ClaimToolJob(time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job == nil || job.ID != "job-1" || job.Status != ToolJobPending {
+		t.Fatalf("claimed %+v", job)
+	}
+	if _, err := c.rdb.ZScore(c.ctx, c.key(toolJobLeasesKey), "job-1").Result(); err != nil {
+		t.Fatalf("claimed job has no lease: %v", err)
+	}
+
+	if err := c.FinishToolJob("job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := c.rdb.LLen(c.ctx, c.key(toolJobProcessingKey)).Val() + c.rdb.ZCard(c.ctx, c.key(toolJobLeasesKey)).Val(); n != 0 {
+		t.Fatalf("finished job still claimed (%d entries)", n)
+	}
+}
+
+func TestRequeueExpiredToolJobs(t *testing.T) {
+	c, _ := newTestClient(t)
+	enqueueTestJob(t, &c, "live")
+	enqueueTestJob(t, &c, "stale")
+	for range 2 {
+		if _, err := c.ClaimToolJob(time.Second, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The instance running "live" keeps renewing its lease; the one running "stale" stopped
+	later := time.Now().Add(2 * time.Minute)
+	if err := c.RenewToolJobLease("live", 3*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	requeued, err := c.RequeueExpiredToolJobs(later, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued != 1 {
+		t.Fatalf("requeued %d jobs, want only the expired one", requeued)
+	}
+	if queue := c.rdb.LRange(c.ctx, c.key(toolJobQueueKey), 0, -1).Val(); len(queue) != 1 || queue[0] != "stale" {
+		t.Fatalf("queue = %v, want [stale]", queue)
+	}
+	if processing := c.rdb.LRange(c.ctx, c.key(toolJobProcessingKey), 0, -1).Val(); len(processing) != 1 || processing[0] != "live" {
+		t.Fatalf("processing = %v, want [live]", processing)
+	}
+
+	// A renewal after the job was requeued doesn't bring its lease back
+	if err := c.RenewToolJobLease("stale", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.rdb.ZScore(c.ctx, c.key(toolJobLeasesKey), "stale").Err(); err == nil {
+		t.Fatal("requeued job got its lease back")
+	}
+}
+
+func TestRequeueExpiredToolJobs_WithoutLease(t *testing.T) {
+	c, _ := newTestClient(t)
+	// A job moved to the processing list by a worker that has not recorded its lease yet
+	c.rdb.LPush(c.ctx, c.key(toolJobProcessingKey), "claiming")
+
+	now := time.Now()
+	if requeued, err := c.RequeueExpiredToolJobs(now, time.Minute); err != nil || requeued != 0 {
+		t.Fatalf("RequeueExpiredToolJobs = %d, %v, want the job given a grace lease", requeued, err)
+	}
+	if requeued, _ := c.RequeueExpiredToolJobs(now.Add(2*time.Minute), time.Minute); requeued != 1 {
+		t.Fatalf("requeued %d jobs after the grace lease expired, want 1", requeued)
+	}
+}
+
+func TestRetryToolJobAt(t *testing.T) {
+	c, _ := newTestClient(t)
+	enqueueTestJob(t, &c, "job-1")
+	if _, err := c.ClaimToolJob(time.Second, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	due := time.Now().Add(30 * time.Second)
+	if err := c.RetryToolJobAt("job-1", due); err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := c.ClaimToolJob(time.Second, time.Minute); job != nil {
+		t.Fatal("job claimed again before its retry was due")
+	}
+	if requeued, _ := c.RequeueExpiredToolJobs(due.Add(time.Hour), time.Minute); requeued != 0 {
+		t.Fatal("job waiting for its retry was reclaimed")
+	}
+
+	if queued, err := c.QueueDueToolJobs(due.Add(-time.Second)); err != nil || queued != 0 {
+		t.Fatalf("QueueDueToolJobs before due = %d, %v", queued, err)
+	}
+	if queued, err := c.QueueDueToolJobs(due); err != nil || queued != 1 {
+		t.Fatalf("QueueDueToolJobs when due = %d, %v", queued, err)
+	}
+	if job, _ := c.ClaimToolJob(time.Second, time.Minute); job == nil || job.ID != "job-1" {
+		t.Fatalf("claimed %+v after the retry, want job-1", job)
+	}
+}
@@ -0,0 +1,214 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Usage is accumulated per user and per tenant in one hash per day, with a field per
+// model and counter, so totals and the daily budget are read without scanning messages.
+// Costs are stored in nanodollars to keep the counters integers.
+
+// usageTTL is how long daily usage is kept.
+const usageTTL = 400 * 24 * time.Hour
+
+const (
+	usagePromptTokensPrefix     = "prompt_tokens:"
+	usageCompletionTokensPrefix = "completion_tokens:"
+	usageCharactersPrefix       = "characters:"
+	usageCostPrefix             = "cost_nanos:"
+	// usageCost is the cost of all models, read by the budget check
+	usageCost = "cost_nanos"
+)
+
+// UsageRecord is the usage of one request to a model or voice, with its computed cost.
+type UsageRecord struct {
+	Model            string
+	PromptTokens     int64
+	CompletionTokens int64
+	Characters       int64
+	CostNanos        int64
+}
+
+// ModelUsage is the usage accumulated for a model.
+type ModelUsage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+	Characters       int64
+	CostNanos        int64
+}
+
+// DailyUsage is the usage of a user or tenant on a day.
+type DailyUsage struct {
+	Day       string
+	Models    map[string]ModelUsage
+	CostNanos int64
+}
+
+// UserUsage is the usage of a user on a day within a tenant.
+type UserUsage struct {
+	UserID    string
+	CostNanos int64
+}
+
+func (c *Client) userUsageKey(userID, day string) string {
+	return c.key(fmt.Sprintf("usage:user:%s:%s", userID, day))
+}
+
+func (c *Client) tenantUsageKey(tenant, day string) string {
+	return c.key(fmt.Sprintf("usage:tenant:%s:%s", tenant, day))
+}
+
+// tenantUsersKey ranks the users of a tenant by their cost on the day.
+func (c *Client) tenantUsersKey(tenant, day string) string {
+	return c.key(fmt.Sprintf("usage:tenant_users:%s:%s", tenant, day))
+}
+
+// RecordUsage adds a request's usage to the user's and the tenant's totals of the day,
+// formatted as 2006-01-02. An empty userID only adds to the tenant's totals.
+func (c *Client) RecordUsage(tenant, userID, day string, record UsageRecord) error {
+	keys := []string{c.tenantUsageKey(tenant, day)}
+	if userID != "" {
+		keys = append(keys, c.userUsageKey(userID, day))
+	}
+
+	pipe := c.rdb.TxPipeline()
+	for _, key := range keys {
+		if record.PromptTokens > 0 {
+			pipe.HIncrBy(c.ctx, key, usagePromptTokensPrefix+record.Model, record.PromptTokens)
+		}
+		if record.CompletionTokens > 0 {
+			pipe.HIncrBy(c.ctx, key, usageCompletionTokensPrefix+record.Model, record.CompletionTokens)
+		}
+		if record.Characters > 0 {
+			pipe.HIncrBy(c.ctx, key, usageCharactersPrefix+record.Model, record.Characters)
+		}
+		pipe.HIncrBy(c.ctx, key, usageCostPrefix+record.Model, record.CostNanos)
+		pipe.HIncrBy(c.ctx, key, usageCost, record.CostNanos)
+		pipe.Expire(c.ctx, key, usageTTL)
+	}
+	if userID != "" {
+		usersKey := c.tenantUsersKey(tenant, day)
+		pipe.ZIncrBy(c.ctx, usersKey, float64(record.CostNanos), userID)
+		pipe.Expire(c.ctx, usersKey, usageTTL)
+	}
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
+
+// GetUserDailyCost returns the user's cost on the day, in nanodollars.
+func (c *Client) GetUserDailyCost(userID, day string) (int64, error) {
+	cost, err := c.rdb.HGet(c.ctx, c.userUsageKey(userID, day), usageCost).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return cost, err
+}
+
+// GetUserUsage returns the user's usage on each day.
+func (c *Client) GetUserUsage(userID string, days []string) ([]DailyUsage, error) {
+	keys := make([]string, len(days))
+	for i, day := range days {
+		keys[i] = c.userUsageKey(userID, day)
+	}
+	return c.getDailyUsage(keys, days)
+}
+
+// GetTenantUsage returns the tenant's usage on each day.
+func (c *Client) GetTenantUsage(tenant string, days []string) ([]DailyUsage, error) {
+	keys := make([]string, len(days))
+	for i, day := range days {
+		keys[i] = c.tenantUsageKey(tenant, day)
+	}
+	return c.getDailyUsage(keys, days)
+}
+
+func (c *Client) getDailyUsage(keys, days []string) ([]DailyUsage, error) {
+	pipe := c.rdb.Pipeline()
+	results := make([]*redis.MapStringStringCmd, len(keys))
+	for i, key := range keys {
+		results[i] = pipe.HGetAll(c.ctx, key)
+	}
+	if _, err := pipe.Exec(c.ctx); err != nil {
+		return nil, err
+	}
+
+	usage := make([]DailyUsage, len(days))
+	for i, day := range days {
+		usage[i] = parseDailyUsage(day, results[i].Val())
+	}
+	return usage, nil
+}
+
+func parseDailyUsage(day string, fields map[string]string) DailyUsage {
+	usage := DailyUsage{Day: day, Models: map[string]ModelUsage{}}
+	for field, raw := range fields {
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			continue
+		}
+		if field == usageCost {
+			usage.CostNanos = value
+			continue
+		}
+		counter, model, ok := strings.Cut(field, ":")
+		if !ok {
+			continue
+		}
+		modelUsage := usage.Models[model]
+		switch counter + ":" {
+		case usagePromptTokensPrefix:
+			modelUsage.PromptTokens = value
+		case usageCompletionTokensPrefix:
+			modelUsage.CompletionTokens = value
+		case usageCharactersPrefix:
+			modelUsage.Characters = value
+		case usageCostPrefix:
+			modelUsage.CostNanos = value
+		default:
+			continue
+		}
+		usage.Models[model] = modelUsage
+	}
+	return usage
+}
+
+// GetTenantUsers returns the tenant's users with usage on the day, most expensive first.
+func (c *Client) GetTenantUsers(tenant, day string) ([]UserUsage, error) {
+	members, err := c.rdb.ZRevRangeWithScores(c.ctx, c.tenantUsersKey(tenant, day), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	users := make([]UserUsage, 0, len(members))
+	for _, member := range members {
+		users = append(users, UserUsage{UserID: member.Member.(string), CostNanos: int64(member.Score)})
+	}
+	return users, nil
+}
+
+// deleteUserUsage deletes the user's daily usage and removes them from the tenant rankings.
+// Tenant totals are aggregates without personal data and are kept.
+func (c *Client) deleteUserUsage(userID string) error {
+	pipe := c.rdb.Pipeline()
+	iter := c.rdb.Scan(c.ctx, 0, escapeGlob(c.userUsageKey(userID, ""))+"*", 500).Iterator()
+	for iter.Next(c.ctx) {
+		pipe.Del(c.ctx, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	iter = c.rdb.Scan(c.ctx, 0, escapeGlob(c.key("usage:tenant_users:"))+"*", 500).Iterator()
+	for iter.Next(c.ctx) {
+		pipe.ZRem(c.ctx, iter.Val(), userID)
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	_, err := pipe.Exec(c.ctx)
+	return err
+}
@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDailyUsage(t *testing.T) {
+	usage := parseDailyUsage("2025-03-01", map[string]string{
+		"prompt_tokens:gpt-4.1-mini":     "1000",
+		"completion_tokens:gpt-4.1-mini": "500",
+		"cost_nanos:gpt-4.1-mini":        "1200000",
+		"prompt_tokens:llama3:8b":        "10",
+		"characters:eleven_v2":           "250",
+		"cost_nanos":                     "1200000",
+	})
+
+	if usage.CostNanos != 1_200_000 {
+		t.Errorf("CostNanos = %d, want 1200000", usage.CostNanos)
+	}
+	want := ModelUsage{PromptTokens: 1000, CompletionTokens: 500, CostNanos: 1_200_000}
+	if got := usage.Models["gpt-4.1-mini"]; got != want {
+		t.Errorf("gpt-4.1-mini usage = %+v, want %+v", got, want)
+	}
+	if got := usage.Models["llama3:8b"].PromptTokens; got != 10 {
+		t.Errorf("model names with colons: prompt tokens = %d, want 10", got)
+	}
+	if got := usage.Models["eleven_v2"].Characters; got != 250 {
+		t.Errorf("characters = %d, want 250", got)
+	}
+}
+
+func TestDeleteUserUsage_PrefixedTenant(t *testing.T) {
+	client, server := newTestClient(t)
+	acme := client.WithPrefix("tenant:acme:")
+	record := UsageRecord{Model: "gpt-4.1-mini", PromptTokens: 100, CostNanos: 5000}
+	for _, userID := range []string{"5511999999999", "5522888888888"} {
+		if err := acme.RecordUsage("acme", userID, "2025-03-01", record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := acme.EraseUserData("5511999999999"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range server.Keys() {
+		if strings.Contains(key, "5511999999999") {
+			t.Errorf("key %s left after erasure", key)
+		}
+	}
+	members, err := server.ZMembers("tenant:acme:usage:tenant_users:acme:2025-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 1 || members[0] != "5522888888888" {
+		t.Errorf("tenant ranking = %v, want only the other user", members)
+	}
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/auth"
+	"github.com/NextMind-AI/chatbot-go/processor"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// apiKeyHeader carries CRM API keys; JWTs go in the Authorization header as bearer tokens.
+const apiKeyHeader = "X-API-Key"
+
+// tenantHeader selects the tenant a CRM request is about; without it the client's own
+// tenant, or the default tenant for unrestricted clients, is used.
+const tenantHeader = "X-Tenant-ID"
+
+// Keys of the request locals
+const (
+	// principalKey stores the authenticated client
+	principalKey = "crm_principal"
+	// processorKey stores the message processor of the request's tenant
+	processorKey = "crm_processor"
+)
+
+// authenticate rejects CRM requests without a valid API key or bearer token.
+// Browsers cannot set headers on EventSource connections, so the event stream
+// also accepts the token in the access_token query parameter.
+func (s *Server) authenticate(c fiber.Ctx) error {
+	token, _ := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" && c.Path() == "/crm/events" {
+		token = c.Query("access_token")
+	}
+
+	principal, err := s.authenticator.Authenticate(c.Get(apiKeyHeader), token)
+	if err != nil {
+		log.Warn().Err(err).Str("path", c.Path()).Str("ip", c.IP()).Msg("Rejected CRM request")
+		message := "Invalid API key or token"
+		if errors.Is(err, auth.ErrMissingCredentials) {
+			message = "An API key or bearer token is required"
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "UNAUTHORIZED",
+				Message: message,
+			},
+		})
+	}
+
+	c.Locals(principalKey, principal)
+	return c.Next()
+}
+
+// scopeTenant selects the tenant of a CRM request and rejects clients restricted to
+// another tenant. Like the token, the event stream also accepts the tenant in the
+// tenant query parameter.
+func (s *Server) scopeTenant(c fiber.Ctx) error {
+	p := principal(c)
+	tenantID := c.Get(tenantHeader)
+	if tenantID == "" && c.Path() == "/crm/events" {
+		tenantID = c.Query("tenant")
+	}
+	if tenantID == "" {
+		tenantID = p.Tenant
+	}
+
+	if !p.CanAccess(tenantID) {
+		log.Warn().Str("actor", p.Name).Str("tenant", tenantID).Str("path", c.Path()).Msg("Rejected CRM request for another tenant")
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "FORBIDDEN",
+				Message: "This client cannot access tenant " + tenantID,
+			},
+		})
+	}
+
+	messageProcessor := s.messageProcessor
+	if tenantID != "" {
+		t, ok := s.lookupTenant(tenantID)
+		if !ok {
+			return tenantNotFound(c)
+		}
+		messageProcessor = t.Processor
+	}
+	c.Locals(processorKey, messageProcessor)
+	return c.Next()
+}
+
+// processor returns the message processor of a CRM request's tenant.
+func (s *Server) processor(c fiber.Ctx) *processor.MessageProcessor {
+	if mp, ok := c.Locals(processorKey).(*processor.MessageProcessor); ok {
+		return mp
+	}
+	return s.messageProcessor
+}
+
+// requireAllTenants rejects clients restricted to a tenant, for routes that manage the tenants.
+func requireAllTenants(c fiber.Ctx) error {
+	if principal(c).Tenant != "" {
+		return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "FORBIDDEN",
+				Message: "This action requires a client with access to every tenant",
+			},
+		})
+	}
+	return c.Next()
+}
+
+// requireRole returns a route middleware that rejects clients without the role.
+func requireRole(role auth.Role) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if !principal(c).Role.Allows(role) {
+			return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "FORBIDDEN",
+					Message: "This action requires the " + string(role) + " role",
+				},
+			})
+		}
+		return c.Next()
+	}
+}
+
+// principal returns the authenticated client of a CRM request.
+func principal(c fiber.Ctx) auth.Principal {
+	p, _ := c.Locals(principalKey).(auth.Principal)
+	return p
+}
+
+// agentName returns the authenticated client as the agent of a request. Only admins can act
+// under the name given in the request body, and the real client is logged when they do.
+func agentName(c fiber.Ctx, agent string) string {
+	p := principal(c)
+	if agent == "" || agent == p.Name {
+		return p.Name
+	}
+	if !p.Role.Allows(auth.Admin) {
+		log.Warn().Str("actor", p.Name).Str("agent", agent).Str("path", c.Path()).Msg("Ignored agent name of a client that is not an admin")
+		return p.Name
+	}
+	log.Warn().Str("actor", p.Name).Str("agent", agent).Str("path", c.Path()).Msg("Admin acting under another agent name")
+	return agent
+}
+
+// audit records that the authenticated client accessed a customer's data.
+// Failures are logged and do not fail the request.
+func (s *Server) audit(c fiber.Ctx, action, userID, detail string) {
+	p := principal(c)
+	entry := redis.AuditEntry{
+		Actor:     p.Name,
+		Role:      string(p.Role),
+		Action:    action,
+		UserID:    userID,
+		Detail:    detail,
+		IP:        c.IP(),
+		Timestamp: time.Now(),
+	}
+
+	log.Info().
+		Str("actor", entry.Actor).
+		Str("action", action).
+		Str("user_id", userID).
+		Msg("CRM audit")
+
+	if err := s.processor(c).GetRedisClient().RecordAudit(entry); err != nil {
+		log.Error().Err(err).Str("action", action).Str("user_id", userID).Msg("Error recording audit entry")
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultAnalyticsTimezone = "America/Sao_Paulo"
+	maxAnalyticsHourDays     = 31
+	maxAnalyticsDayDays      = 366
+)
+
+// crmAnalyticsHandler handles GET /crm/analytics
+// Optional parameters: granularity (day or hour, default day), from and to (inclusive dates
+// as YYYY-MM-DD, default the last 7 days for day and today for hour) and tz (IANA time zone
+// that days and hours are aligned to, default America/Sao_Paulo).
+func (s *Server) crmAnalyticsHandler(c fiber.Ctx) error {
+	granularity := c.Query("granularity", "day")
+	maxDays := maxAnalyticsDayDays
+	switch granularity {
+	case "day":
+	case "hour":
+		maxDays = maxAnalyticsHourDays
+	default:
+		return invalidParameter(c, "granularity must be day or hour")
+	}
+
+	timezone := c.Query("tz", defaultAnalyticsTimezone)
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return invalidParameter(c, "tz must be an IANA time zone such as America/Sao_Paulo")
+	}
+
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if toParam := c.Query("to"); toParam != "" {
+		if to, err = time.ParseInLocation("2006-01-02", toParam, location); err != nil {
+			return invalidParameter(c, "to must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	from := to
+	if granularity == "day" {
+		from = to.AddDate(0, 0, -6)
+	}
+	if fromParam := c.Query("from"); fromParam != "" {
+		if from, err = time.ParseInLocation("2006-01-02", fromParam, location); err != nil {
+			return invalidParameter(c, "from must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return invalidParameter(c, "from must not be after to")
+	}
+	end := to.AddDate(0, 0, 1)
+	if from.AddDate(0, 0, maxDays).Before(end) {
+		return invalidParameter(c, "the range is limited to 366 days for day and 31 days for hour granularity")
+	}
+
+	log.Info().
+		Str("granularity", granularity).
+		Str("from", from.Format("2006-01-02")).
+		Str("to", to.Format("2006-01-02")).
+		Str("tz", timezone).
+		Msg("Received CRM analytics request")
+
+	// Every bucket is aggregated in the same call, followed by the whole range
+	var periods []redis.AnalyticsPeriod
+	for start := from; start.Before(end); {
+		next := start.AddDate(0, 0, 1)
+		if granularity == "hour" {
+			next = start.Add(time.Hour)
+		}
+		periods = append(periods, redis.AnalyticsPeriod{Start: start, End: next})
+		start = next
+	}
+	periods = append(periods, redis.AnalyticsPeriod{Start: from, End: end})
+
+	analytics, err := s.processor(c).Analytics(periods)
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting CRM analytics")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve analytics",
+			},
+		})
+	}
+
+	buckets := make([]AnalyticsBucket, 0, len(analytics)-1)
+	for _, bucket := range analytics[:len(analytics)-1] {
+		buckets = append(buckets, toAnalyticsBucket(bucket))
+	}
+
+	return c.JSON(AnalyticsResponse{
+		Granularity: granularity,
+		Timezone:    timezone,
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Buckets:     buckets,
+		Total:       toAnalyticsBucket(analytics[len(analytics)-1]),
+	})
+}
+
+func toAnalyticsBucket(analytics redis.Analytics) AnalyticsBucket {
+	variants := make(map[string]VariantMetrics, len(analytics.Variants))
+	for name, variant := range analytics.Variants {
+		variants[name] = VariantMetrics{
+			Conversations:             variant.Conversations,
+			Messages:                  variant.Messages,
+			Replies:                   variant.Replies,
+			AverageReplyLength:        ratio(variant.ReplyCharacters, variant.Replies),
+			AverageConversationLength: ratio(variant.Messages, variant.Conversations),
+			Escalations:               variant.Escalations,
+			EscalatedConversations:    variant.EscalatedConversations,
+			EscalationRate:            ratio(variant.EscalatedConversations, variant.Conversations),
+			ThumbsUp:                  variant.ThumbsUp,
+			ThumbsDown:                variant.ThumbsDown,
+			ThumbsUpRate:              ratio(variant.ThumbsUp, variant.ThumbsUp+variant.ThumbsDown),
+		}
+	}
+
+	return AnalyticsBucket{
+		Start:              analytics.Start.UTC().Format("2006-01-02T15:04:05Z"),
+		End:                analytics.End.UTC().Format("2006-01-02T15:04:05Z"),
+		Conversations:      analytics.Conversations,
+		InboundMessages:    analytics.InboundMessages,
+		OutboundMessages:   analytics.OutboundMessages,
+		InboundAudioShare:  ratio(analytics.InboundAudio, analytics.InboundMessages),
+		OutboundAudioShare: ratio(analytics.OutboundAudio, analytics.OutboundMessages),
+		FirstReply: FirstReplyLatency{
+			Count:         analytics.Replies,
+			MedianSeconds: analytics.ReplyLatencyMedian.Seconds(),
+			P95Seconds:    analytics.ReplyLatencyP95.Seconds(),
+		},
+		ToolCalls:              analytics.ToolCalls,
+		ToolErrors:             analytics.ToolErrors,
+		Escalations:            analytics.Escalations,
+		EscalatedConversations: analytics.EscalatedConversations,
+		EscalationRate:         ratio(analytics.EscalatedConversations, analytics.Conversations),
+		Variants:               variants,
+	}
+}
+
+// ratio returns part/total, or 0 when total is 0.
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
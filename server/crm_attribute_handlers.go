@@ -0,0 +1,91 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmConversationAttributesHandler handles GET /crm/conversations/{userId}/attributes
+func (s *Server) crmConversationAttributesHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM conversation attributes request")
+	s.audit(c, "read_attributes", userID, "")
+
+	return s.respondConversationAttributes(c, userID)
+}
+
+// crmSetConversationAttributesHandler handles PUT /crm/conversations/{userId}/attributes.
+// The body is an object of attributes to set; attributes not in the body are kept.
+func (s *Server) crmSetConversationAttributesHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var attributes map[string]string
+	if err := c.Bind().JSON(&attributes); err != nil {
+		return invalidParameter(c, "Body must be an object of string attributes")
+	}
+	for key := range attributes {
+		if strings.TrimSpace(key) == "" {
+			return invalidParameter(c, "Attribute names must not be empty")
+		}
+	}
+
+	log.Info().Str("user_id", userID).Int("attributes", len(attributes)).Msg("Received CRM set conversation attributes request")
+
+	if err := s.processor(c).GetRedisClient().SetConversationAttributes(userID, attributes); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error setting conversation attributes")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to set conversation attributes",
+			},
+		})
+	}
+
+	return s.respondConversationAttributes(c, userID)
+}
+
+// crmDeleteConversationAttributeHandler handles DELETE /crm/conversations/{userId}/attributes/{key}
+func (s *Server) crmDeleteConversationAttributeHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	key := c.Params("key")
+
+	log.Info().Str("user_id", userID).Str("key", key).Msg("Received CRM delete conversation attribute request")
+
+	deleted, err := s.processor(c).GetRedisClient().DeleteConversationAttribute(userID, key)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error deleting conversation attribute")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to delete conversation attribute",
+			},
+		})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Attribute not found",
+			},
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) respondConversationAttributes(c fiber.Ctx, userID string) error {
+	attributes, err := s.processor(c).GetRedisClient().GetConversationAttributes(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting conversation attributes")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve conversation attributes",
+			},
+		})
+	}
+	return c.JSON(attributes)
+}
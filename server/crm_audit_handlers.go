@@ -0,0 +1,48 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmAuditLogHandler handles GET /crm/audit
+// Optional parameters: user_id to list the accesses to one customer's data and limit (default 100, max 1000).
+func (s *Server) crmAuditLogHandler(c fiber.Ctx) error {
+	userID := c.Query("user_id")
+	limit := 100
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= 1000 {
+			limit = l
+		}
+	}
+
+	log.Info().Str("user_id", userID).Int("limit", limit).Msg("Received CRM audit log request")
+
+	entries, err := s.processor(c).GetRedisClient().GetAuditLog(userID, limit)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting audit log")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve audit log",
+			},
+		})
+	}
+
+	apiEntries := []AuditEntry{}
+	for _, entry := range entries {
+		apiEntries = append(apiEntries, AuditEntry{
+			Actor:     entry.Actor,
+			Role:      entry.Role,
+			Action:    entry.Action,
+			UserID:    entry.UserID,
+			Detail:    entry.Detail,
+			IP:        entry.IP,
+			Timestamp: entry.Timestamp.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return c.JSON(apiEntries)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/NextMind-AI/chatbot-go/processor"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmExportUserDataHandler handles GET /crm/conversations/{userId}/export
+// Query parameters: format (json or csv, default json).
+func (s *Server) crmExportUserDataHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	format := c.Query("format", "json")
+	if format != "json" && format != "csv" {
+		return invalidParameter(c, "format must be json or csv")
+	}
+
+	log.Info().Str("user_id", userID).Str("format", format).Msg("Received CRM user data export request")
+
+	export, err := s.processor(c).ExportUserData(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error exporting user data")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to export user data",
+			},
+		})
+	}
+	s.audit(c, "export_data", userID, "format="+format)
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, userID, format))
+	if format == "json" {
+		return c.JSON(export)
+	}
+
+	var body bytes.Buffer
+	if err := export.WriteCSV(&body); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error writing user data CSV")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to export user data",
+			},
+		})
+	}
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	return c.Send(body.Bytes())
+}
+
+// crmEraseUserDataHandler handles DELETE /crm/conversations/{userId}, erasing all data held about the user
+func (s *Server) crmEraseUserDataHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM user data erasure request")
+
+	report, err := s.processor(c).EraseUserData(userID)
+	if errors.Is(err, processor.ErrErasureInProgress) {
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "CONFLICT",
+				Message: err.Error(),
+			},
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error erasing user data")
+		s.audit(c, "erase_data_failed", userID, err.Error())
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to erase user data",
+			},
+		})
+	}
+	s.audit(c, "erase_data", userID, fmt.Sprintf("messages=%d audio_deleted=%d audio_failed=%d",
+		report.MessagesDeleted, report.AudioDeleted, len(report.AudioFailed)))
+
+	return c.JSON(report)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmEscalationsHandler handles GET /crm/escalations
+func (s *Server) crmEscalationsHandler(c fiber.Ctx) error {
+	log.Info().Msg("Received CRM escalations request")
+
+	escalations, err := s.processor(c).EscalationQueue()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting escalation queue")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve escalations",
+			},
+		})
+	}
+
+	apiEscalations := []Escalation{}
+	for _, escalation := range escalations {
+		apiEscalations = append(apiEscalations, apiEscalation(escalation))
+	}
+
+	return c.JSON(apiEscalations)
+}
+
+// crmClaimEscalationHandler handles POST /crm/escalations/{escalationId}/claim
+func (s *Server) crmClaimEscalationHandler(c fiber.Ctx) error {
+	escalationID := c.Params("escalationId")
+
+	var req ClaimEscalationRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return invalidParameter(c, "Invalid JSON body")
+		}
+	}
+	req.Agent = agentName(c, req.Agent)
+	if req.Agent == "" {
+		return invalidParameter(c, "agent is required")
+	}
+
+	log.Info().Str("escalation_id", escalationID).Str("agent", req.Agent).Msg("Received CRM claim escalation request")
+
+	escalation, err := s.processor(c).ClaimEscalation(escalationID, req.Agent)
+	if err != nil {
+		return escalationError(c, escalationID, err)
+	}
+
+	return c.JSON(apiEscalation(escalation))
+}
+
+// crmResolveEscalationHandler handles POST /crm/escalations/{escalationId}/resolve
+func (s *Server) crmResolveEscalationHandler(c fiber.Ctx) error {
+	escalationID := c.Params("escalationId")
+
+	log.Info().Str("escalation_id", escalationID).Msg("Received CRM resolve escalation request")
+
+	escalation, err := s.processor(c).ResolveEscalation(escalationID)
+	if err != nil {
+		return escalationError(c, escalationID, err)
+	}
+
+	return c.JSON(apiEscalation(escalation))
+}
+
+// escalationError responds with 404 for unknown escalations and 500 otherwise
+func escalationError(c fiber.Ctx, escalationID string, err error) error {
+	if errors.Is(err, redis.ErrEscalationNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Escalation not found",
+			},
+		})
+	}
+
+	log.Error().Err(err).Str("escalation_id", escalationID).Msg("Error updating escalation")
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to update escalation",
+		},
+	})
+}
+
+// apiEscalation converts an escalation to the CRM API format
+func apiEscalation(escalation redis.Escalation) Escalation {
+	result := Escalation{
+		ID:        escalation.ID,
+		UserID:    escalation.UserID,
+		Reason:    escalation.Reason,
+		Priority:  escalation.Priority,
+		Status:    escalation.Status,
+		ClaimedBy: escalation.ClaimedBy,
+		CreatedAt: escalation.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: escalation.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if !escalation.ResolvedAt.IsZero() {
+		result.ResolvedAt = escalation.ResolvedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return result
+}
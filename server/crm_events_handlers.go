@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/events"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// eventsHeartbeatInterval keeps idle event streams open through proxies.
+const eventsHeartbeatInterval = 15 * time.Second
+
+// crmEventsHandler handles GET /crm/events, a Server-Sent Events stream of
+// conversation events from every server instance.
+// Optional parameters: user_id and types, both comma-separated lists.
+func (s *Server) crmEventsHandler(c fiber.Ctx) error {
+	userIDs := commaSet(c.Query("user_id"))
+	types := commaSet(c.Query("types"))
+
+	log.Info().
+		Str("user_id", c.Query("user_id")).
+		Str("types", c.Query("types")).
+		Msg("Received CRM events stream request")
+
+	if len(userIDs) == 0 {
+		s.audit(c, "stream_events", "", "")
+	}
+	for userID := range userIDs {
+		s.audit(c, "stream_events", userID, "")
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The stream writer runs after the handler returns, so it must not use c
+	messageProcessor := s.processor(c)
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		subscription, err := messageProcessor.SubscribeEvents()
+		if err != nil {
+			log.Error().Err(err).Msg("Error subscribing to events")
+			fmt.Fprintf(w, "event: error\ndata: %q\n\n", "subscription failed")
+			w.Flush()
+			return
+		}
+		defer subscription.Close()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventsHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if !matches(userIDs, event.UserID) || !matches(types, string(event.Type)) {
+					continue
+				}
+				if err := writeEvent(w, event); err != nil {
+					log.Error().Err(err).Msg("Error encoding event")
+					continue
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+
+			// Flush fails once the client has disconnected
+			if err := w.Flush(); err != nil {
+				log.Info().Msg("CRM events stream closed")
+				return
+			}
+		}
+	})
+}
+
+// writeEvent writes an event in the Server-Sent Events format.
+func writeEvent(w *bufio.Writer, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+// commaSet returns the set of values of a comma-separated parameter, or nil when it is empty.
+func commaSet(param string) map[string]bool {
+	var set map[string]bool
+	for value := range strings.SplitSeq(param, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[value] = true
+		}
+	}
+	return set
+}
+
+// matches reports whether the value is in the set; an empty set matches everything.
+func matches(set map[string]bool, value string) bool {
+	return len(set) == 0 || set[value]
+}
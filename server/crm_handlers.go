@@ -1,20 +1,80 @@
 package server
 
 import (
+	"errors"
 	"fmt"
-	"sort"
 	"strconv"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/rs/zerolog/log"
 )
 
+// defaultMessagesPageSize is the page size of GET /crm/conversations/{userId}
+const defaultMessagesPageSize = 10
+
 // crmConversationsHandler handles GET /crm/conversations
+// Query parameters: limit (default 50, max 200), cursor, sort (newest or oldest),
+// since and until (RFC 3339, filtering on the last message time) and tag.
+// Without limit or cursor it responds with the plain array of every matching
+// conversation that existing clients expect; with either it responds with a page.
 func (s *Server) crmConversationsHandler(c fiber.Ctx) error {
 	log.Info().Msg("Received CRM conversations request")
 
-	// Get all conversation summaries from Redis
-	summaries, err := s.messageProcessor.GetRedisClient().GetAllConversationSummaries()
+	paginated := c.Query("limit") != "" || c.Query("cursor") != ""
+	query := store.ConversationQuery{
+		Cursor: c.Query("cursor"),
+		Order:  store.NewestFirst,
+	}
+	if paginated {
+		query.Limit = 50
+	}
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= 200 {
+			query.Limit = l
+		}
+	}
+
+	switch sortParam := c.Query("sort"); sortParam {
+	case "", "newest":
+	case "oldest":
+		query.Order = store.OldestFirst
+	default:
+		return invalidParameter(c, "sort must be newest or oldest")
+	}
+
+	var err error
+	if query.Since, err = parseTimeParam(c.Query("since")); err != nil {
+		return invalidParameter(c, "since must be an RFC 3339 timestamp")
+	}
+	if query.Until, err = parseTimeParam(c.Query("until")); err != nil {
+		return invalidParameter(c, "until must be an RFC 3339 timestamp")
+	}
+
+	if tag := c.Query("tag"); tag != "" {
+		query.UserIDs, err = s.processor(c).GetRedisClient().GetTaggedConversations(tag)
+		if err != nil {
+			log.Error().Err(err).Str("tag", tag).Msg("Error getting tagged conversations")
+			return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "INTERNAL_ERROR",
+					Message: "Failed to retrieve conversation summaries",
+				},
+			})
+		}
+		// A tag without conversations matches none, unlike a nil restriction
+		if query.UserIDs == nil {
+			query.UserIDs = []string{}
+		}
+	}
+
+	list, err := s.processor(c).GetConversationStore().Summaries(c.Context(), query)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		return invalidParameter(c, "cursor is invalid")
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Error getting conversation summaries")
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
@@ -25,9 +85,8 @@ func (s *Server) crmConversationsHandler(c fiber.Ctx) error {
 		})
 	}
 
-	// Convert to API format and sort by last message time (newest first)
-	var apiSummaries []ConversationSummary
-	for _, summary := range summaries {
+	apiSummaries := []ConversationSummary{}
+	for _, summary := range list.Conversations {
 		apiSummaries = append(apiSummaries, ConversationSummary{
 			UserID:             summary.UserID,
 			LastMessageTime:    summary.LastMessageTime.Format("2006-01-02T15:04:05Z"),
@@ -36,12 +95,32 @@ func (s *Server) crmConversationsHandler(c fiber.Ctx) error {
 		})
 	}
 
-	// Sort by last message time (newest first)
-	sort.Slice(apiSummaries, func(i, j int) bool {
-		return apiSummaries[i].LastMessageTime > apiSummaries[j].LastMessageTime
+	if !paginated {
+		return c.JSON(apiSummaries)
+	}
+	return c.JSON(ConversationListResponse{
+		Conversations: apiSummaries,
+		NextCursor:    list.NextCursor,
+		HasMore:       list.NextCursor != "",
 	})
+}
 
-	return c.JSON(apiSummaries)
+// parseTimeParam parses an optional RFC 3339 query parameter
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// invalidParameter responds with a 400 INVALID_PARAMETER error
+func invalidParameter(c fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INVALID_PARAMETER",
+			Message: message,
+		},
+	})
 }
 
 // crmConversationMessagesHandler handles GET /crm/conversations/{userId}
@@ -60,7 +139,7 @@ func (s *Server) crmConversationMessagesHandler(c fiber.Ctx) error {
 
 	// Parse pagination parameters
 	page := 1
-	pageSize := 10
+	pageSize := defaultMessagesPageSize
 
 	if pageParam := c.Query("page"); pageParam != "" {
 		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
@@ -74,8 +153,9 @@ func (s *Server) crmConversationMessagesHandler(c fiber.Ctx) error {
 		}
 	}
 
-	// Get paginated messages from Redis
-	result, err := s.messageProcessor.GetRedisClient().GetChatHistoryPaginated(userID, page, pageSize)
+	s.audit(c, "read_conversation", userID, fmt.Sprintf("page=%d page_size=%d", page, pageSize))
+
+	result, err := s.processor(c).GetConversationStore().Paginate(c.Context(), userID, page, pageSize)
 	if err != nil {
 		log.Error().Err(err).Str("user_id", userID).Msg("Error getting paginated chat history")
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
@@ -89,24 +169,7 @@ func (s *Server) crmConversationMessagesHandler(c fiber.Ctx) error {
 	// Convert to API format
 	var apiMessages []ConversationMessage
 	for i, msg := range result.Messages {
-		// Generate message ID (using index + timestamp for uniqueness)
-		messageID := fmt.Sprintf("msg_%d_%d", msg.Timestamp.Unix(), i)
-		if msg.MessageUUID != "" {
-			messageID = msg.MessageUUID
-		}
-
-		// Convert role to sender format
-		sender := "user"
-		if msg.Role == "assistant" {
-			sender = "system"
-		}
-
-		apiMessages = append(apiMessages, ConversationMessage{
-			ID:        messageID,
-			Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z"),
-			Content:   msg.Content,
-			Sender:    sender,
-		})
+		apiMessages = append(apiMessages, conversationMessage(msg, i))
 	}
 
 	// Calculate pagination info
@@ -124,3 +187,31 @@ func (s *Server) crmConversationMessagesHandler(c fiber.Ctx) error {
 
 	return c.JSON(response)
 }
+
+// conversationMessage converts a stored message to the CRM API format.
+// index makes the generated ID unique for messages without a UUID.
+func conversationMessage(msg store.ChatMessage, index int) ConversationMessage {
+	messageID := fmt.Sprintf("msg_%d_%d", msg.Timestamp.Unix(), index)
+	if msg.MessageUUID != "" {
+		messageID = msg.MessageUUID
+	}
+
+	// Convert role to sender format
+	sender := "user"
+	switch msg.Role {
+	case "assistant":
+		sender = "system"
+	case "agent":
+		sender = "agent"
+	}
+
+	return ConversationMessage{
+		ID:        messageID,
+		Timestamp: msg.Timestamp.Format("2006-01-02T15:04:05Z"),
+		Content:   msg.Content,
+		Sender:    sender,
+		Agent:     msg.Agent,
+		Model:     msg.Model,
+		Variant:   msg.Variant,
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmUserMemoriesHandler handles GET /crm/conversations/{userId}/memories
+func (s *Server) crmUserMemoriesHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM user memories request")
+	s.audit(c, "read_memories", userID, "")
+
+	memories, err := s.processor(c).GetRedisClient().GetUserMemories(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting user memories")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve user memories",
+			},
+		})
+	}
+
+	apiMemories := []UserMemory{}
+	for _, memory := range memories {
+		apiMemories = append(apiMemories, UserMemory{
+			ID:        memory.ID,
+			Content:   memory.Content,
+			Source:    memory.Source,
+			CreatedAt: memory.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		})
+	}
+
+	return c.JSON(apiMemories)
+}
+
+// crmDeleteUserMemoryHandler handles DELETE /crm/conversations/{userId}/memories/{memoryId}
+func (s *Server) crmDeleteUserMemoryHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	memoryID := c.Params("memoryId")
+
+	log.Info().Str("user_id", userID).Str("memory_id", memoryID).Msg("Received CRM delete user memory request")
+
+	deleted, err := s.processor(c).GetRedisClient().DeleteUserMemory(userID, memoryID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error deleting user memory")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to delete user memory",
+			},
+		})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Memory not found",
+			},
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// crmClearUserMemoriesHandler handles DELETE /crm/conversations/{userId}/memories
+func (s *Server) crmClearUserMemoriesHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM clear user memories request")
+
+	if err := s.processor(c).GetRedisClient().ClearUserMemories(userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error clearing user memories")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to clear user memories",
+			},
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmConversationNotesHandler handles GET /crm/conversations/{userId}/notes
+func (s *Server) crmConversationNotesHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM conversation notes request")
+	s.audit(c, "read_notes", userID, "")
+
+	notes, err := s.processor(c).GetRedisClient().GetConversationNotes(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting conversation notes")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve conversation notes",
+			},
+		})
+	}
+
+	apiNotes := []Note{}
+	for _, note := range notes {
+		apiNotes = append(apiNotes, apiNote(note))
+	}
+	return c.JSON(apiNotes)
+}
+
+// crmAddConversationNoteHandler handles POST /crm/conversations/{userId}/notes
+func (s *Server) crmAddConversationNoteHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var req NoteRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return invalidParameter(c, "Invalid JSON body")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return invalidParameter(c, "content is required")
+	}
+	req.Author = agentName(c, req.Author)
+
+	log.Info().Str("user_id", userID).Str("author", req.Author).Msg("Received CRM add conversation note request")
+
+	note, err := s.processor(c).GetRedisClient().AddConversationNote(userID, req.Author, req.Content)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error adding conversation note")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to add conversation note",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(apiNote(note))
+}
+
+// crmUpdateConversationNoteHandler handles PUT /crm/conversations/{userId}/notes/{noteId}
+func (s *Server) crmUpdateConversationNoteHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	noteID := c.Params("noteId")
+
+	var req NoteRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return invalidParameter(c, "Invalid JSON body")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return invalidParameter(c, "content is required")
+	}
+
+	log.Info().Str("user_id", userID).Str("note_id", noteID).Msg("Received CRM update conversation note request")
+
+	note, err := s.processor(c).GetRedisClient().UpdateConversationNote(userID, noteID, req.Content)
+	if errors.Is(err, redis.ErrNoteNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Note not found",
+			},
+		})
+	}
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error updating conversation note")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to update conversation note",
+			},
+		})
+	}
+
+	return c.JSON(apiNote(note))
+}
+
+// crmDeleteConversationNoteHandler handles DELETE /crm/conversations/{userId}/notes/{noteId}
+func (s *Server) crmDeleteConversationNoteHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	noteID := c.Params("noteId")
+
+	log.Info().Str("user_id", userID).Str("note_id", noteID).Msg("Received CRM delete conversation note request")
+
+	deleted, err := s.processor(c).GetRedisClient().DeleteConversationNote(userID, noteID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error deleting conversation note")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to delete conversation note",
+			},
+		})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Note not found",
+			},
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func apiNote(note redis.Note) Note {
+	return Note{
+		ID:        note.ID,
+		Author:    note.Author,
+		Content:   note.Content,
+		CreatedAt: note.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: note.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"github.com/NextMind-AI/chatbot-go/prompt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmPromptHandler handles GET /crm/prompt, which returns the prompt template stored in Redis
+func (s *Server) crmPromptHandler(c fiber.Ctx) error {
+	log.Info().Msg("Received CRM prompt request")
+
+	text, ok, err := s.processor(c).GetRedisClient().GetPromptTemplate()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting prompt template")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve prompt template",
+			},
+		})
+	}
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "No prompt template is stored",
+			},
+		})
+	}
+	return c.JSON(PromptTemplate{Template: text})
+}
+
+// crmPutPromptHandler handles PUT /crm/prompt, which stores the prompt template in Redis.
+// Bots reading their template from Redis pick it up within their reload interval.
+func (s *Server) crmPutPromptHandler(c fiber.Ctx) error {
+	var request PromptTemplate
+	if err := c.Bind().JSON(&request); err != nil || request.Template == "" {
+		return invalidParameter(c, "Body must have a template")
+	}
+	if _, err := prompt.Parse(request.Template); err != nil {
+		return invalidParameter(c, "Invalid template: "+err.Error())
+	}
+
+	log.Info().Int("length", len(request.Template)).Msg("Received CRM put prompt request")
+
+	if err := s.processor(c).GetRedisClient().SavePromptTemplate(request.Template); err != nil {
+		log.Error().Err(err).Msg("Error saving prompt template")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to save prompt template",
+			},
+		})
+	}
+
+	s.audit(c, "put_prompt", "", "")
+	return c.JSON(request)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmSearchHandler handles GET /crm/search?q=
+// Optional parameters: user_id to search a single conversation and limit (default 20, max 100).
+func (s *Server) crmSearchHandler(c fiber.Ctx) error {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		return invalidParameter(c, "q parameter is required")
+	}
+
+	log.Info().Str("query", text).Msg("Received CRM search request")
+
+	searcher, ok := s.processor(c).GetConversationStore().(store.Searcher)
+	if !ok {
+		return c.Status(fiber.StatusNotImplemented).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_SUPPORTED",
+				Message: "The conversation store does not support search",
+			},
+		})
+	}
+
+	query := store.SearchQuery{
+		Text:   text,
+		UserID: c.Query("user_id"),
+		Limit:  store.DefaultSearchLimit,
+	}
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= 100 {
+			query.Limit = l
+		}
+	}
+
+	results, err := searcher.Search(c.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Str("query", text).Msg("Error searching conversations")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to search conversations",
+			},
+		})
+	}
+
+	// Search results expose messages, so each conversation they come from is audited
+	audited := make(map[string]bool)
+	apiResults := []SearchResult{}
+	for _, result := range results {
+		if !audited[result.UserID] {
+			audited[result.UserID] = true
+			s.audit(c, "search", result.UserID, "q="+text)
+		}
+
+		message := conversationMessage(result.Message, result.Position)
+		page := result.Position/defaultMessagesPageSize + 1
+		apiResults = append(apiResults, SearchResult{
+			UserID:    result.UserID,
+			MessageID: message.ID,
+			Timestamp: message.Timestamp,
+			Content:   message.Content,
+			Sender:    message.Sender,
+			Snippet:   store.Snippet(result.Message.Content, text),
+			Page:      page,
+			Link:      fmt.Sprintf("/crm/conversations/%s?page=%d&page_size=%d", url.PathEscape(result.UserID), page, defaultMessagesPageSize),
+		})
+	}
+
+	return c.JSON(SearchResponse{
+		Query:   text,
+		Results: apiResults,
+	})
+}
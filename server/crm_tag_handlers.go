@@ -0,0 +1,110 @@
+package server
+
+import (
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmTagsHandler handles GET /crm/tags, the tags in use across conversations
+func (s *Server) crmTagsHandler(c fiber.Ctx) error {
+	tags, err := s.processor(c).GetRedisClient().GetAllTags()
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting tags")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve tags",
+			},
+		})
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return c.JSON(tags)
+}
+
+// crmConversationTagsHandler handles GET /crm/conversations/{userId}/tags
+func (s *Server) crmConversationTagsHandler(c fiber.Ctx) error {
+	return s.respondConversationTags(c, c.Params("userId"))
+}
+
+// crmAddConversationTagsHandler handles POST /crm/conversations/{userId}/tags
+func (s *Server) crmAddConversationTagsHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var req ConversationTagsRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return invalidParameter(c, "Invalid JSON body")
+	}
+	var tags []string
+	for _, tag := range req.Tags {
+		if tag = redis.NormalizeTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) == 0 {
+		return invalidParameter(c, "tags is required")
+	}
+
+	log.Info().Str("user_id", userID).Strs("tags", tags).Msg("Received CRM add conversation tags request")
+
+	if err := s.processor(c).GetRedisClient().AddConversationTags(userID, tags...); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error adding conversation tags")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to add conversation tags",
+			},
+		})
+	}
+
+	return s.respondConversationTags(c, userID)
+}
+
+// crmRemoveConversationTagHandler handles DELETE /crm/conversations/{userId}/tags/{tag}
+func (s *Server) crmRemoveConversationTagHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	tag := c.Params("tag")
+
+	log.Info().Str("user_id", userID).Str("tag", tag).Msg("Received CRM remove conversation tag request")
+
+	removed, err := s.processor(c).GetRedisClient().RemoveConversationTag(userID, tag)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error removing conversation tag")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to remove conversation tag",
+			},
+		})
+	}
+	if !removed {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Tag not found",
+			},
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) respondConversationTags(c fiber.Ctx, userID string) error {
+	tags, err := s.processor(c).GetRedisClient().GetConversationTags(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting conversation tags")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve conversation tags",
+			},
+		})
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return c.JSON(ConversationTags{UserID: userID, Tags: tags})
+}
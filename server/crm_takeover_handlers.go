@@ -0,0 +1,117 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmConversationPauseHandler handles GET /crm/conversations/{userId}/pause
+func (s *Server) crmConversationPauseHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	pause, paused, err := s.processor(c).ConversationPause(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting bot pause")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve conversation status",
+			},
+		})
+	}
+
+	return c.JSON(conversationPauseStatus(userID, pause, paused))
+}
+
+// crmPauseConversationHandler handles POST /crm/conversations/{userId}/pause
+func (s *Server) crmPauseConversationHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var req PauseConversationRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return invalidParameter(c, "Invalid JSON body")
+		}
+	}
+
+	req.Agent = agentName(c, req.Agent)
+
+	log.Info().Str("user_id", userID).Str("agent", req.Agent).Msg("Received CRM pause conversation request")
+
+	pause, err := s.processor(c).PauseConversation(userID, req.Agent, req.Reason)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error pausing bot")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to pause conversation",
+			},
+		})
+	}
+
+	return c.JSON(conversationPauseStatus(userID, pause, true))
+}
+
+// crmResumeConversationHandler handles POST /crm/conversations/{userId}/resume
+func (s *Server) crmResumeConversationHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	log.Info().Str("user_id", userID).Msg("Received CRM resume conversation request")
+
+	if _, err := s.processor(c).ResumeConversation(userID); err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error resuming bot")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to resume conversation",
+			},
+		})
+	}
+
+	return c.JSON(conversationPauseStatus(userID, redis.BotPause{}, false))
+}
+
+// crmSendAgentMessageHandler handles POST /crm/conversations/{userId}/messages
+func (s *Server) crmSendAgentMessageHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+
+	var req AgentMessageRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return invalidParameter(c, "Invalid JSON body")
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		return invalidParameter(c, "text is required")
+	}
+
+	req.Agent = agentName(c, req.Agent)
+
+	log.Info().Str("user_id", userID).Str("agent", req.Agent).Msg("Received CRM agent message request")
+
+	message, err := s.processor(c).SendAgentMessage(userID, req.Agent, req.Text)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error sending agent message")
+		return c.Status(fiber.StatusBadGateway).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "SEND_FAILED",
+				Message: "Failed to send agent message",
+			},
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(conversationMessage(message, 0))
+}
+
+// conversationPauseStatus converts a bot pause to the CRM API format
+func conversationPauseStatus(userID string, pause redis.BotPause, paused bool) ConversationPauseStatus {
+	status := ConversationPauseStatus{UserID: userID, Paused: paused}
+	if paused {
+		status.Agent = pause.Agent
+		status.Reason = pause.Reason
+		status.PausedAt = pause.PausedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return status
+}
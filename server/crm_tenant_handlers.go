@@ -0,0 +1,159 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/NextMind-AI/chatbot-go/tenant"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmTenantsHandler handles GET /crm/tenants
+func (s *Server) crmTenantsHandler(c fiber.Ctx) error {
+	log.Info().Msg("Received CRM tenants request")
+
+	response := TenantsResponse{Tenants: []TenantResponse{}}
+	if s.tenants != nil {
+		for _, definition := range s.tenants.List() {
+			response.Tenants = append(response.Tenants, toTenantResponse(definition))
+		}
+	}
+	return c.JSON(response)
+}
+
+// crmTenantHandler handles GET /crm/tenants/{tenantId}
+func (s *Server) crmTenantHandler(c fiber.Ctx) error {
+	tenantID := c.Params("tenantId")
+
+	log.Info().Str("tenant", tenantID).Msg("Received CRM tenant request")
+
+	t, ok := s.lookupTenant(tenantID)
+	if !ok {
+		return tenantNotFound(c)
+	}
+	return c.JSON(toTenantResponse(t.Definition))
+}
+
+// crmPutTenantHandler handles PUT /crm/tenants/{tenantId}, which creates the tenant or
+// replaces its definition. A replaced tenant keeps its Vonage JWT when the body has none.
+func (s *Server) crmPutTenantHandler(c fiber.Ctx) error {
+	tenantID := c.Params("tenantId")
+	if s.tenants == nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Tenants are not enabled",
+			},
+		})
+	}
+
+	var request TenantRequest
+	if err := c.Bind().JSON(&request); err != nil {
+		return invalidParameter(c, "Body must be a tenant definition")
+	}
+
+	log.Info().
+		Str("tenant", tenantID).
+		Strs("numbers", request.Numbers).
+		Msg("Received CRM put tenant request")
+
+	definition := tenant.Definition{
+		ID:           tenantID,
+		Name:         request.Name,
+		Numbers:      request.Numbers,
+		Prompt:       request.Prompt,
+		Tools:        request.Tools,
+		Model:        request.Model,
+		VoiceID:      request.VoiceID,
+		VonageJWT:    request.VonageJWT,
+		SenderNumber: request.SenderNumber,
+		RedisPrefix:  request.RedisPrefix,
+	}
+	if current, ok := s.tenants.Get(tenantID); ok && definition.VonageJWT == "" {
+		definition.VonageJWT = current.Definition.VonageJWT
+	}
+
+	definition, err := s.tenants.Put(definition)
+	switch {
+	case errors.Is(err, tenant.ErrInvalidDefinition):
+		return invalidParameter(c, err.Error())
+	case errors.Is(err, tenant.ErrConflict):
+		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "CONFLICT",
+				Message: err.Error(),
+			},
+		})
+	case err != nil:
+		log.Error().Err(err).Str("tenant", tenantID).Msg("Error saving tenant")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to save tenant",
+			},
+		})
+	}
+
+	s.audit(c, "put_tenant", "", tenantID)
+	return c.JSON(toTenantResponse(definition))
+}
+
+// crmDeleteTenantHandler handles DELETE /crm/tenants/{tenantId}. The tenant's
+// conversations and other data stay in Redis under its prefix.
+func (s *Server) crmDeleteTenantHandler(c fiber.Ctx) error {
+	tenantID := c.Params("tenantId")
+
+	log.Info().Str("tenant", tenantID).Msg("Received CRM delete tenant request")
+
+	if s.tenants == nil {
+		return tenantNotFound(c)
+	}
+	err := s.tenants.Delete(tenantID)
+	if errors.Is(err, tenant.ErrNotFound) {
+		return tenantNotFound(c)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("tenant", tenantID).Msg("Error deleting tenant")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to delete tenant",
+			},
+		})
+	}
+
+	s.audit(c, "delete_tenant", "", tenantID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (s *Server) lookupTenant(tenantID string) (tenant.Tenant, bool) {
+	if s.tenants == nil {
+		return tenant.Tenant{}, false
+	}
+	return s.tenants.Get(tenantID)
+}
+
+func tenantNotFound(c fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "NOT_FOUND",
+			Message: "Tenant not found",
+		},
+	})
+}
+
+func toTenantResponse(definition tenant.Definition) TenantResponse {
+	return TenantResponse{
+		ID:           definition.ID,
+		Name:         definition.Name,
+		Numbers:      definition.Numbers,
+		Prompt:       definition.Prompt,
+		Tools:        definition.Tools,
+		Model:        definition.Model,
+		VoiceID:      definition.VoiceID,
+		HasVonageJWT: definition.VonageJWT != "",
+		SenderNumber: definition.SenderNumber,
+		RedisPrefix:  definition.RedisPrefix,
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// crmToolsHandler handles GET /crm/tools
+func (s *Server) crmToolsHandler(c fiber.Ctx) error {
+	log.Info().Msg("Received CRM tools request")
+
+	disabled, err := s.disabledTools(c)
+	if err != nil {
+		return toolsError(c, err)
+	}
+
+	response := ToolsResponse{Tools: []ToolStatus{}}
+	for _, tool := range s.processor(c).GetTools() {
+		response.Tools = append(response.Tools, ToolStatus{
+			Name:        tool.Definition.Name,
+			Description: tool.Definition.Description,
+			Async:       tool.Async,
+			Enabled:     !disabled[tool.Definition.Name],
+		})
+	}
+	return c.JSON(response)
+}
+
+// crmSetToolStateHandler handles PUT /crm/tools/{toolName}, which switches a tool on or off
+// for every server instance from the next turn on
+func (s *Server) crmSetToolStateHandler(c fiber.Ctx) error {
+	toolName := c.Params("toolName")
+
+	var request ToolStateRequest
+	if err := c.Bind().JSON(&request); err != nil || request.Enabled == nil {
+		return invalidParameter(c, "Body must have an enabled boolean")
+	}
+
+	log.Info().Str("tool", toolName).Bool("enabled", *request.Enabled).Msg("Received CRM set tool state request")
+
+	var status *ToolStatus
+	for _, tool := range s.processor(c).GetTools() {
+		if tool.Definition.Name == toolName {
+			status = &ToolStatus{
+				Name:        tool.Definition.Name,
+				Description: tool.Definition.Description,
+				Async:       tool.Async,
+				Enabled:     *request.Enabled,
+			}
+			break
+		}
+	}
+	if status == nil {
+		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "NOT_FOUND",
+				Message: "Tool not found",
+			},
+		})
+	}
+
+	if err := s.processor(c).GetRedisClient().SetToolEnabled(toolName, *request.Enabled); err != nil {
+		return toolsError(c, err)
+	}
+
+	action := "disable_tool"
+	if *request.Enabled {
+		action = "enable_tool"
+	}
+	s.audit(c, action, "", toolName)
+	return c.JSON(status)
+}
+
+func (s *Server) disabledTools(c fiber.Ctx) (map[string]bool, error) {
+	names, err := s.processor(c).GetRedisClient().GetDisabledTools()
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+func toolsError(c fiber.Ctx, err error) error {
+	log.Error().Err(err).Msg("Error accessing tool states")
+	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to access tool states",
+		},
+	})
+}
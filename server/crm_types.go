@@ -8,12 +8,40 @@ type ConversationSummary struct {
 	MessageCount       int    `json:"message_count"`
 }
 
+// ConversationListResponse represents a page of conversation summaries
+type ConversationListResponse struct {
+	Conversations []ConversationSummary `json:"conversations"`
+	NextCursor    string                `json:"next_cursor,omitempty"`
+	HasMore       bool                  `json:"has_more"`
+}
+
+// SearchResult represents a message matching a CRM search
+type SearchResult struct {
+	UserID    string `json:"user_id"`
+	MessageID string `json:"message_id"`
+	Timestamp string `json:"timestamp"`
+	Content   string `json:"content"`
+	Sender    string `json:"sender"`
+	Snippet   string `json:"snippet"`
+	Page      int    `json:"page"`
+	Link      string `json:"link"`
+}
+
+// SearchResponse represents the response of a CRM search
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
+
 // ConversationMessage represents a message in a conversation for the CRM API
 type ConversationMessage struct {
 	ID        string `json:"id"`
 	Timestamp string `json:"timestamp"`
 	Content   string `json:"content"`
 	Sender    string `json:"sender"`
+	Agent     string `json:"agent,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Variant   string `json:"variant,omitempty"`
 }
 
 // ConversationResponse represents the paginated response for conversation messages
@@ -26,6 +54,14 @@ type ConversationResponse struct {
 	HasPreviousPage bool                  `json:"has_previous_page"`
 }
 
+// UserMemory represents a long-term fact about a user for the CRM API
+type UserMemory struct {
+	ID        string `json:"id"`
+	Content   string `json:"content"`
+	Source    string `json:"source"`
+	CreatedAt string `json:"created_at"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
@@ -37,3 +73,227 @@ type ErrorDetail struct {
 	Message string      `json:"message"`
 	Details interface{} `json:"details,omitempty"`
 }
+
+// PauseConversationRequest is the body of POST /crm/conversations/{userId}/pause
+type PauseConversationRequest struct {
+	Agent  string `json:"agent"`
+	Reason string `json:"reason"`
+}
+
+// AgentMessageRequest is the body of POST /crm/conversations/{userId}/messages
+type AgentMessageRequest struct {
+	Agent string `json:"agent"`
+	Text  string `json:"text"`
+}
+
+// ConversationPauseStatus represents whether a human agent took over a conversation
+type ConversationPauseStatus struct {
+	UserID   string `json:"user_id"`
+	Paused   bool   `json:"paused"`
+	Agent    string `json:"agent,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	PausedAt string `json:"paused_at,omitempty"`
+}
+
+// Escalation represents a conversation waiting for a human agent
+type Escalation struct {
+	ID         string `json:"id"`
+	UserID     string `json:"user_id"`
+	Reason     string `json:"reason"`
+	Priority   string `json:"priority"`
+	Status     string `json:"status"`
+	ClaimedBy  string `json:"claimed_by,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	ResolvedAt string `json:"resolved_at,omitempty"`
+}
+
+// ClaimEscalationRequest is the body of POST /crm/escalations/{escalationId}/claim
+type ClaimEscalationRequest struct {
+	Agent string `json:"agent"`
+}
+
+// AuditEntry represents an access to customer data through the CRM API
+type AuditEntry struct {
+	Actor     string `json:"actor"`
+	Role      string `json:"role"`
+	Action    string `json:"action"`
+	UserID    string `json:"user_id,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Timestamp string `json:"timestamp"`
+}
+
+// ConversationTagsRequest is the body of POST /crm/conversations/{userId}/tags
+type ConversationTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// ConversationTags represents the tags of a conversation
+type ConversationTags struct {
+	UserID string   `json:"user_id"`
+	Tags   []string `json:"tags"`
+}
+
+// Note represents an internal agent note on a conversation
+type Note struct {
+	ID        string `json:"id"`
+	Author    string `json:"author"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NoteRequest is the body of POST and PUT /crm/conversations/{userId}/notes
+type NoteRequest struct {
+	Author  string `json:"author"`
+	Content string `json:"content"`
+}
+
+// FirstReplyLatency summarizes the time between a user message and the bot's first reply
+type FirstReplyLatency struct {
+	Count         int64   `json:"count"`
+	MedianSeconds float64 `json:"median_seconds"`
+	P95Seconds    float64 `json:"p95_seconds"`
+}
+
+// AnalyticsBucket represents the conversation metrics of a day or hour
+type AnalyticsBucket struct {
+	Start                  string            `json:"start"`
+	End                    string            `json:"end"`
+	Conversations          int64             `json:"conversations"`
+	InboundMessages        int64             `json:"inbound_messages"`
+	OutboundMessages       int64             `json:"outbound_messages"`
+	InboundAudioShare      float64           `json:"inbound_audio_share"`
+	OutboundAudioShare     float64           `json:"outbound_audio_share"`
+	FirstReply             FirstReplyLatency `json:"first_reply"`
+	ToolCalls              map[string]int64  `json:"tool_calls"`
+	ToolErrors             map[string]int64  `json:"tool_errors"`
+	Escalations            int64             `json:"escalations"`
+	EscalatedConversations int64             `json:"escalated_conversations"`
+	EscalationRate         float64           `json:"escalation_rate"`
+	// Variants holds the metrics of each experiment variant, empty without an experiment
+	Variants map[string]VariantMetrics `json:"variants"`
+}
+
+// VariantMetrics represents the metrics of an experiment variant in a day or hour
+type VariantMetrics struct {
+	Conversations             int64   `json:"conversations"`
+	Messages                  int64   `json:"messages"`
+	Replies                   int64   `json:"replies"`
+	AverageReplyLength        float64 `json:"average_reply_length"`
+	AverageConversationLength float64 `json:"average_conversation_length"`
+	Escalations               int64   `json:"escalations"`
+	EscalatedConversations    int64   `json:"escalated_conversations"`
+	EscalationRate            float64 `json:"escalation_rate"`
+	ThumbsUp                  int64   `json:"thumbs_up"`
+	ThumbsDown                int64   `json:"thumbs_down"`
+	ThumbsUpRate              float64 `json:"thumbs_up_rate"`
+}
+
+// AnalyticsResponse represents the response of GET /crm/analytics
+type AnalyticsResponse struct {
+	Granularity string            `json:"granularity"`
+	Timezone    string            `json:"timezone"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Buckets     []AnalyticsBucket `json:"buckets"`
+	Total       AnalyticsBucket   `json:"total"`
+}
+
+// ModelUsage represents the tokens and speech characters consumed with a model
+type ModelUsage struct {
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Characters       int64   `json:"characters"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// DailyUsage represents the usage of a day, per model
+type DailyUsage struct {
+	Date    string                `json:"date,omitempty"`
+	Models  map[string]ModelUsage `json:"models"`
+	CostUSD float64               `json:"cost_usd"`
+}
+
+// UsageResponse represents the response of GET /crm/usage and GET /crm/conversations/{userId}/usage
+type UsageResponse struct {
+	Tenant         string       `json:"tenant,omitempty"`
+	UserID         string       `json:"user_id,omitempty"`
+	Timezone       string       `json:"timezone"`
+	From           string       `json:"from"`
+	To             string       `json:"to"`
+	Days           []DailyUsage `json:"days"`
+	Total          DailyUsage   `json:"total"`
+	DailyBudgetUSD float64      `json:"daily_budget_usd,omitempty"`
+	OverBudget     *bool        `json:"over_budget,omitempty"`
+}
+
+// UserCost represents what a user cost on a day
+type UserCost struct {
+	UserID  string  `json:"user_id"`
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// UsageUsersResponse represents the response of GET /crm/usage/users
+type UsageUsersResponse struct {
+	Tenant string     `json:"tenant"`
+	Date   string     `json:"date"`
+	Users  []UserCost `json:"users"`
+}
+
+// TenantRequest represents the body of PUT /crm/tenants/{tenantId}
+type TenantRequest struct {
+	Name         string   `json:"name"`
+	Numbers      []string `json:"numbers"`
+	Prompt       string   `json:"prompt"`
+	Tools        []string `json:"tools"`
+	Model        string   `json:"model"`
+	VoiceID      string   `json:"voice_id"`
+	VonageJWT    string   `json:"vonage_jwt"`
+	SenderNumber string   `json:"sender_number"`
+	RedisPrefix  string   `json:"redis_prefix"`
+}
+
+// TenantResponse represents a tenant. The Vonage JWT is a secret, so only whether the
+// tenant has its own is returned.
+type TenantResponse struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	Numbers      []string `json:"numbers"`
+	Prompt       string   `json:"prompt,omitempty"`
+	Tools        []string `json:"tools"`
+	Model        string   `json:"model,omitempty"`
+	VoiceID      string   `json:"voice_id,omitempty"`
+	HasVonageJWT bool     `json:"has_vonage_jwt"`
+	SenderNumber string   `json:"sender_number"`
+	RedisPrefix  string   `json:"redis_prefix"`
+}
+
+// TenantsResponse represents the response of GET /crm/tenants
+type TenantsResponse struct {
+	Tenants []TenantResponse `json:"tenants"`
+}
+
+// ToolStatus represents a tool offered to the model and whether it is switched on
+type ToolStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Async       bool   `json:"async"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// ToolsResponse represents the response of GET /crm/tools
+type ToolsResponse struct {
+	Tools []ToolStatus `json:"tools"`
+}
+
+// ToolStateRequest is the body of PUT /crm/tools/{toolName}
+type ToolStateRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// PromptTemplate is the body and response of GET and PUT /crm/prompt
+type PromptTemplate struct {
+	Template string `json:"template"`
+}
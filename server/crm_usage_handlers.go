@@ -0,0 +1,221 @@
+package server
+
+import (
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/redis"
+	"github.com/NextMind-AI/chatbot-go/usage"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const maxUsageDays = 366
+
+// crmUsageHandler handles GET /crm/usage
+// Optional parameters: from and to (inclusive dates as YYYY-MM-DD, default the last 7 days).
+// Days are counted in the time zone of the usage meter.
+func (s *Server) crmUsageHandler(c fiber.Ctx) error {
+	meter := s.processor(c).UsageMeter()
+	if meter == nil {
+		return usageDisabled(c)
+	}
+	from, to, ok, err := usageRange(c, meter)
+	if !ok {
+		return err
+	}
+
+	log.Info().
+		Str("tenant", meter.Tenant()).
+		Str("from", from.Format(usage.DayLayout)).
+		Str("to", to.Format(usage.DayLayout)).
+		Msg("Received CRM usage request")
+
+	days, err := meter.TenantUsage(usage.Days(from, to))
+	if err != nil {
+		log.Error().Err(err).Msg("Error getting tenant usage")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve usage",
+			},
+		})
+	}
+
+	response := toUsageResponse(meter, from, to, days)
+	response.Tenant = meter.Tenant()
+	return c.JSON(response)
+}
+
+// crmUsageUsersHandler handles GET /crm/usage/users
+// Optional parameter: date (YYYY-MM-DD, default today). Users are sorted by cost, highest first.
+func (s *Server) crmUsageUsersHandler(c fiber.Ctx) error {
+	meter := s.processor(c).UsageMeter()
+	if meter == nil {
+		return usageDisabled(c)
+	}
+
+	date := c.Query("date", meter.Day(time.Now()))
+	if _, err := time.Parse(usage.DayLayout, date); err != nil {
+		return invalidParameter(c, "date must be formatted as YYYY-MM-DD")
+	}
+
+	log.Info().
+		Str("tenant", meter.Tenant()).
+		Str("date", date).
+		Msg("Received CRM usage by user request")
+
+	users, err := meter.TenantUsers(date)
+	if err != nil {
+		log.Error().Err(err).Str("date", date).Msg("Error getting usage by user")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve usage",
+			},
+		})
+	}
+
+	response := UsageUsersResponse{
+		Tenant: meter.Tenant(),
+		Date:   date,
+		Users:  make([]UserCost, 0, len(users)),
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, UserCost{
+			UserID:  user.UserID,
+			CostUSD: usage.NanosToUSD(user.CostNanos),
+		})
+	}
+	return c.JSON(response)
+}
+
+// crmConversationUsageHandler handles GET /crm/conversations/{userId}/usage
+// Optional parameters: from and to, as in GET /crm/usage. The response also tells
+// whether the user reached their daily budget today.
+func (s *Server) crmConversationUsageHandler(c fiber.Ctx) error {
+	userID := c.Params("userId")
+	if userID == "" {
+		return invalidParameter(c, "userId parameter is required")
+	}
+	meter := s.processor(c).UsageMeter()
+	if meter == nil {
+		return usageDisabled(c)
+	}
+	from, to, ok, err := usageRange(c, meter)
+	if !ok {
+		return err
+	}
+
+	log.Info().
+		Str("user_id", userID).
+		Str("from", from.Format(usage.DayLayout)).
+		Str("to", to.Format(usage.DayLayout)).
+		Msg("Received CRM conversation usage request")
+
+	days, err := meter.UserUsage(userID, usage.Days(from, to))
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error getting user usage")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve usage",
+			},
+		})
+	}
+	overBudget, err := meter.OverBudget(userID)
+	if err != nil {
+		log.Error().Err(err).Str("user_id", userID).Msg("Error checking daily budget")
+		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to retrieve usage",
+			},
+		})
+	}
+
+	response := toUsageResponse(meter, from, to, days)
+	response.UserID = userID
+	response.OverBudget = &overBudget
+	return c.JSON(response)
+}
+
+// usageRange parses the from and to parameters of the usage endpoints. When they are
+// invalid it writes the error response and returns false along with the write's error.
+func usageRange(c fiber.Ctx, meter *usage.Meter) (from, to time.Time, ok bool, err error) {
+	location := meter.Location()
+	now := time.Now().In(location)
+	to = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if toParam := c.Query("to"); toParam != "" {
+		if to, err = time.ParseInLocation(usage.DayLayout, toParam, location); err != nil {
+			return from, to, false, invalidParameter(c, "to must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	from = to.AddDate(0, 0, -6)
+	if fromParam := c.Query("from"); fromParam != "" {
+		if from, err = time.ParseInLocation(usage.DayLayout, fromParam, location); err != nil {
+			return from, to, false, invalidParameter(c, "from must be a date formatted as YYYY-MM-DD")
+		}
+	}
+	if to.Before(from) {
+		return from, to, false, invalidParameter(c, "from must not be after to")
+	}
+	if from.AddDate(0, 0, maxUsageDays).Before(to.AddDate(0, 0, 1)) {
+		return from, to, false, invalidParameter(c, "the range is limited to 366 days")
+	}
+	return from, to, true, nil
+}
+
+func usageDisabled(c fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
+		Error: ErrorDetail{
+			Code:    "NOT_FOUND",
+			Message: "Usage accounting is not enabled",
+		},
+	})
+}
+
+// toUsageResponse converts the daily usage and adds up the total of the range.
+func toUsageResponse(meter *usage.Meter, from, to time.Time, days []redis.DailyUsage) UsageResponse {
+	response := UsageResponse{
+		Timezone:       meter.Location().String(),
+		From:           from.Format(usage.DayLayout),
+		To:             to.Format(usage.DayLayout),
+		Days:           make([]DailyUsage, 0, len(days)),
+		DailyBudgetUSD: meter.DailyBudgetUSD(),
+	}
+
+	var totalNanos int64
+	totalModels := map[string]redis.ModelUsage{}
+	for _, day := range days {
+		response.Days = append(response.Days, toDailyUsage(day.Day, day.Models, day.CostNanos))
+		totalNanos += day.CostNanos
+		for model, modelUsage := range day.Models {
+			total := totalModels[model]
+			total.PromptTokens += modelUsage.PromptTokens
+			total.CompletionTokens += modelUsage.CompletionTokens
+			total.Characters += modelUsage.Characters
+			total.CostNanos += modelUsage.CostNanos
+			totalModels[model] = total
+		}
+	}
+	response.Total = toDailyUsage("", totalModels, totalNanos)
+	return response
+}
+
+func toDailyUsage(date string, models map[string]redis.ModelUsage, costNanos int64) DailyUsage {
+	daily := DailyUsage{
+		Date:    date,
+		Models:  make(map[string]ModelUsage, len(models)),
+		CostUSD: usage.NanosToUSD(costNanos),
+	}
+	for model, modelUsage := range models {
+		daily.Models[model] = ModelUsage{
+			PromptTokens:     modelUsage.PromptTokens,
+			CompletionTokens: modelUsage.CompletionTokens,
+			Characters:       modelUsage.Characters,
+			CostUSD:          usage.NanosToUSD(modelUsage.CostNanos),
+		}
+	}
+	return daily
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"github.com/NextMind-AI/chatbot-go/metrics"
 	"github.com/NextMind-AI/chatbot-go/processor"
 
 	"github.com/gofiber/fiber/v3"
@@ -16,15 +17,79 @@ func (s *Server) inboundMessageHandler(c fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Error parsing JSON")
 	}
 
+	messageProcessor, tenantID := s.processorForNumber(message.To)
+
 	log.Info().
 		Str("message_uuid", message.MessageUUID).
 		Str("message_type", message.MessageType).
+		Str("tenant", tenantID).
 		Str("from", message.From).
 		Str("text", message.Text).
 		Bool("has_audio", message.Audio != nil).
 		Msg("Processing inbound message")
+	metrics.WebhooksReceived.WithLabelValues("inbound", webhookTypeLabel(message.MessageType, inboundMessageTypes)).Inc()
 
-	go s.messageProcessor.ProcessMessage(message)
+	go messageProcessor.ProcessMessage(message)
 
 	return c.SendStatus(fiber.StatusOK)
 }
+
+// messageStatusHandler handles the Vonage status webhook with delivery updates of sent messages.
+func (s *Server) messageStatusHandler(c fiber.Ctx) error {
+	var status processor.MessageStatus
+	if err := c.Bind().JSON(&status); err != nil {
+		log.Error().Err(err).Msg("Error parsing JSON")
+		return c.Status(fiber.StatusBadRequest).SendString("Error parsing JSON")
+	}
+
+	// Statuses are about messages the bot sent, so the tenant's number is the sender
+	messageProcessor, tenantID := s.processorForNumber(status.From)
+
+	log.Info().
+		Str("message_uuid", status.MessageUUID).
+		Str("tenant", tenantID).
+		Str("to", status.To).
+		Str("status", status.Status).
+		Msg("Received message status")
+	metrics.WebhooksReceived.WithLabelValues("status", webhookTypeLabel(status.Status, messageStatuses)).Inc()
+
+	if err := messageProcessor.HandleMessageStatus(status); err != nil {
+		log.Error().
+			Err(err).
+			Str("message_uuid", status.MessageUUID).
+			Msg("Error handling message status")
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// processorForNumber returns the processor of the tenant owning the number or channel ID,
+// and the tenant's ID, falling back to the default tenant, whose ID is empty.
+func (s *Server) processorForNumber(number string) (*processor.MessageProcessor, string) {
+	if s.tenants != nil {
+		if t, ok := s.tenants.Resolve(number); ok {
+			return t.Processor, t.Definition.ID
+		}
+	}
+	return s.messageProcessor, ""
+}
+
+// The webhooks are not authenticated, so only the values documented by Vonage are used as
+// metric labels and anything else is counted as "other".
+var (
+	inboundMessageTypes = map[string]bool{
+		"text": true, "image": true, "audio": true, "video": true, "file": true,
+		"sticker": true, "location": true, "reply": true, "order": true, "unsupported": true,
+	}
+	messageStatuses = map[string]bool{
+		"submitted": true, "delivered": true, "read": true, "rejected": true, "undeliverable": true,
+	}
+)
+
+func webhookTypeLabel(value string, known map[string]bool) string {
+	if known[value] {
+		return value
+	}
+	return "other"
+}
@@ -15,16 +15,22 @@ func (s *Server) setupMiddleware() {
 	// Add logger middleware
 	s.app.Use(logger.New())
 
-	// Add CORS middleware for CRM API access
-	s.app.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization"},
-	}))
+	// Add CORS middleware for CRM API access from the configured origins only;
+	// the cors middleware would allow every origin with an empty list
+	if len(s.corsOrigins) > 0 {
+		s.app.Use(cors.New(cors.Config{
+			AllowOrigins: s.corsOrigins,
+			AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", apiKeyHeader, tenantHeader},
+		}))
+	}
 
 	// Add JSON content type for CRM endpoints
 	s.app.Use("/crm/*", func(c fiber.Ctx) error {
 		c.Set("Content-Type", "application/json")
 		return c.Next()
 	})
+
+	// Authenticate every CRM request and select its tenant; routes then check the role they need
+	s.app.Use("/crm/*", s.authenticate, s.scopeTenant)
 }
@@ -1,9 +1,58 @@
 package server
 
+import (
+	"github.com/NextMind-AI/chatbot-go/auth"
+	"github.com/NextMind-AI/chatbot-go/metrics"
+
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+)
+
 func (s *Server) setupRoutes() {
 	s.app.Post("/webhooks/inbound-message", s.inboundMessageHandler)
+	s.app.Post("/webhooks/message-status", s.messageStatusHandler)
+	s.app.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+
+	// CRM API endpoints, authenticated in setupMiddleware; each route requires a role
+	viewer, agent, admin := requireRole(auth.Viewer), requireRole(auth.Agent), requireRole(auth.Admin)
 
-	// CRM API endpoints
-	s.app.Get("/crm/conversations", s.crmConversationsHandler)
-	s.app.Get("/crm/conversations/:userId", s.crmConversationMessagesHandler)
+	s.app.Get("/crm/conversations", s.crmConversationsHandler, viewer)
+	s.app.Get("/crm/search", s.crmSearchHandler, viewer)
+	s.app.Get("/crm/events", s.crmEventsHandler, viewer)
+	s.app.Get("/crm/audit", s.crmAuditLogHandler, admin)
+	s.app.Get("/crm/analytics", s.crmAnalyticsHandler, viewer)
+	s.app.Get("/crm/usage", s.crmUsageHandler, admin)
+	s.app.Get("/crm/usage/users", s.crmUsageUsersHandler, admin)
+	s.app.Get("/crm/tenants", s.crmTenantsHandler, admin, requireAllTenants)
+	s.app.Get("/crm/tenants/:tenantId", s.crmTenantHandler, admin, requireAllTenants)
+	s.app.Put("/crm/tenants/:tenantId", s.crmPutTenantHandler, admin, requireAllTenants)
+	s.app.Delete("/crm/tenants/:tenantId", s.crmDeleteTenantHandler, admin, requireAllTenants)
+	s.app.Get("/crm/tools", s.crmToolsHandler, viewer)
+	s.app.Put("/crm/tools/:toolName", s.crmSetToolStateHandler, admin)
+	s.app.Get("/crm/prompt", s.crmPromptHandler, admin)
+	s.app.Put("/crm/prompt", s.crmPutPromptHandler, admin)
+	s.app.Get("/crm/tags", s.crmTagsHandler, viewer)
+	s.app.Get("/crm/escalations", s.crmEscalationsHandler, viewer)
+	s.app.Post("/crm/escalations/:escalationId/claim", s.crmClaimEscalationHandler, agent)
+	s.app.Post("/crm/escalations/:escalationId/resolve", s.crmResolveEscalationHandler, agent)
+	s.app.Get("/crm/conversations/:userId", s.crmConversationMessagesHandler, viewer)
+	s.app.Delete("/crm/conversations/:userId", s.crmEraseUserDataHandler, admin)
+	s.app.Get("/crm/conversations/:userId/export", s.crmExportUserDataHandler, admin)
+	s.app.Get("/crm/conversations/:userId/usage", s.crmConversationUsageHandler, admin)
+	s.app.Get("/crm/conversations/:userId/pause", s.crmConversationPauseHandler, viewer)
+	s.app.Post("/crm/conversations/:userId/pause", s.crmPauseConversationHandler, agent)
+	s.app.Post("/crm/conversations/:userId/resume", s.crmResumeConversationHandler, agent)
+	s.app.Post("/crm/conversations/:userId/messages", s.crmSendAgentMessageHandler, agent)
+	s.app.Get("/crm/conversations/:userId/memories", s.crmUserMemoriesHandler, viewer)
+	s.app.Delete("/crm/conversations/:userId/memories", s.crmClearUserMemoriesHandler, admin)
+	s.app.Delete("/crm/conversations/:userId/memories/:memoryId", s.crmDeleteUserMemoryHandler, agent)
+	s.app.Get("/crm/conversations/:userId/tags", s.crmConversationTagsHandler, viewer)
+	s.app.Post("/crm/conversations/:userId/tags", s.crmAddConversationTagsHandler, agent)
+	s.app.Delete("/crm/conversations/:userId/tags/:tag", s.crmRemoveConversationTagHandler, agent)
+	s.app.Get("/crm/conversations/:userId/notes", s.crmConversationNotesHandler, viewer)
+	s.app.Post("/crm/conversations/:userId/notes", s.crmAddConversationNoteHandler, agent)
+	s.app.Put("/crm/conversations/:userId/notes/:noteId", s.crmUpdateConversationNoteHandler, agent)
+	s.app.Delete("/crm/conversations/:userId/notes/:noteId", s.crmDeleteConversationNoteHandler, agent)
+	s.app.Get("/crm/conversations/:userId/attributes", s.crmConversationAttributesHandler, viewer)
+	s.app.Put("/crm/conversations/:userId/attributes", s.crmSetConversationAttributesHandler, agent)
+	s.app.Delete("/crm/conversations/:userId/attributes/:key", s.crmDeleteConversationAttributeHandler, agent)
 }
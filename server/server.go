@@ -1,23 +1,53 @@
 package server
 
 import (
+	"context"
+
+	"github.com/NextMind-AI/chatbot-go/auth"
 	"github.com/NextMind-AI/chatbot-go/processor"
+	"github.com/NextMind-AI/chatbot-go/tenant"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/rs/zerolog/log"
 )
 
+// Options configures access to the CRM API.
+type Options struct {
+	// Authenticator checks CRM credentials; without credentials every CRM request is rejected
+	Authenticator *auth.Authenticator
+	// CORSOrigins are the browser origins allowed to call the API; empty allows none
+	CORSOrigins []string
+	// Tenants are served next to the default tenant; nil serves only the default tenant
+	Tenants *tenant.Registry
+}
+
 type Server struct {
-	app              *fiber.App
+	app *fiber.App
+	// messageProcessor serves the default tenant: the numbers no tenant owns and CRM
+	// requests that don't select a tenant
 	messageProcessor *processor.MessageProcessor
+	tenants          *tenant.Registry
+	authenticator    *auth.Authenticator
+	corsOrigins      []string
 }
 
-func New(messageProcessor *processor.MessageProcessor) *Server {
+func New(messageProcessor *processor.MessageProcessor, options Options) *Server {
 	app := fiber.New()
 
+	authenticator := options.Authenticator
+	if authenticator == nil {
+		authenticator = auth.NewAuthenticator(nil, "")
+	}
+	if !authenticator.Enabled() {
+		log.Warn().Msg("No CRM API keys or JWT secret configured, all CRM requests will be rejected")
+	}
+
 	server := &Server{
 		app:              app,
 		messageProcessor: messageProcessor,
+		tenants:          options.Tenants,
+		authenticator:    authenticator,
+		corsOrigins:      options.CORSOrigins,
 	}
 
 	server.setupMiddleware()
@@ -36,3 +66,8 @@ func (s *Server) Start(port string) {
 		log.Fatal().Err(err).Msg("Failed to start server")
 	}
 }
+
+// Shutdown stops accepting requests and waits for the ones in progress until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.app.ShutdownWithContext(ctx)
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"html"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultSearchLimit is the number of results returned when the query sets no limit.
+const DefaultSearchLimit = 20
+
+// SearchQuery is a full-text search over message content.
+type SearchQuery struct {
+	// Text is matched word by word; every word must appear in the message
+	Text string
+	// UserID restricts the search to one conversation when set
+	UserID string
+	// Limit defaults to DefaultSearchLimit
+	Limit int
+}
+
+// SearchResult is a message matching a search, most recent first.
+type SearchResult struct {
+	UserID  string
+	Message ChatMessage
+	// Position is the index of the message in the conversation, starting at 0
+	Position int
+}
+
+// Searcher is implemented by conversation stores that support full-text search.
+type Searcher interface {
+	Search(ctx context.Context, query SearchQuery) ([]SearchResult, error)
+}
+
+// accentFolds maps the accented letters of Portuguese to their base letter,
+// so "não" matches "nao".
+var accentFolds = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// foldRune lowercases r and removes its accent.
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if folded, ok := accentFolds[r]; ok {
+		return folded
+	}
+	return r
+}
+
+// Fold lowercases text and removes the accents of Portuguese letters.
+func Fold(text string) string {
+	return strings.Map(foldRune, text)
+}
+
+// Terms splits text into the lowercase, accent-free words used by search indexes.
+// Single-character words are dropped.
+func Terms(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		term := Fold(word)
+		if len([]rune(term)) < 2 || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+const snippetRadius = 60
+
+// Snippet returns the part of content around the first match of the query, HTML-escaped,
+// with every word matching a query term wrapped in <mark> tags.
+func Snippet(content, query string) string {
+	terms := Terms(query)
+	runes := []rune(content)
+
+	type span struct{ start, end int }
+	var matches []span
+	for start := 0; start < len(runes); {
+		if !unicode.IsLetter(runes[start]) && !unicode.IsNumber(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsNumber(runes[end])) {
+			end++
+		}
+		word := Fold(string(runes[start:end]))
+		for _, term := range terms {
+			// Prefix matching also highlights inflections found by stemming, like "boletos"
+			if strings.HasPrefix(word, term) {
+				matches = append(matches, span{start, end})
+				break
+			}
+		}
+		start = end
+	}
+
+	from, to := 0, len(runes)
+	if len(matches) > 0 {
+		from = max(0, matches[0].start-snippetRadius)
+		to = min(len(runes), matches[0].end+snippetRadius)
+	} else if to > 2*snippetRadius {
+		to = 2 * snippetRadius
+	}
+
+	var snippet strings.Builder
+	if from > 0 {
+		snippet.WriteString("...")
+	}
+	pos := from
+	for _, match := range matches {
+		if match.start < from || match.end > to {
+			continue
+		}
+		snippet.WriteString(html.EscapeString(string(runes[pos:match.start])))
+		snippet.WriteString("<mark>" + html.EscapeString(string(runes[match.start:match.end])) + "</mark>")
+		pos = match.end
+	}
+	snippet.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		snippet.WriteString("...")
+	}
+	return snippet.String()
+}
+
+// SortResults orders search results most recent first and applies the limit.
+func SortResults(results []SearchResult, limit int) []SearchResult {
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		return b.Message.Timestamp.Compare(a.Message.Timestamp)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
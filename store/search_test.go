@@ -0,0 +1,25 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	got := Terms("Não recebi o BOLETO, não!")
+	if strings.Join(got, ",") != "nao,recebi,boleto" {
+		t.Fatalf("Terms = %v", got)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	content := strings.Repeat("a ", 50) + "Quero pagar os boletos <hoje>" + strings.Repeat(" b", 50)
+	snippet := Snippet(content, "boleto")
+
+	if !strings.Contains(snippet, "<mark>boletos</mark> &lt;hoje&gt;") {
+		t.Fatalf("snippet does not highlight the match: %q", snippet)
+	}
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Fatalf("snippet is not trimmed around the match: %q", snippet)
+	}
+}
@@ -0,0 +1,35 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	// Registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// SQLite is the SQLite dialect.
+var SQLite = Dialect{
+	Name:        "sqlite",
+	Placeholder: func(int) string { return "?" },
+	IDColumn:    "INTEGER PRIMARY KEY AUTOINCREMENT",
+	// SQLite's LIKE is case-insensitive for ASCII letters
+	Like: "LIKE",
+}
+
+// OpenSQLite opens, creating it if needed, the SQLite database file at path.
+// Writes are serialized through a single connection, which is all SQLite supports.
+func OpenSQLite(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	s, err := New(ctx, db, SQLite)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,415 @@
+// Package sqlstore implements store.ConversationStore on a SQL database, for
+// deployments that need durable, queryable history. PostgreSQL and an embedded
+// SQLite file are supported.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+)
+
+// Dialect captures the differences between the supported databases.
+type Dialect struct {
+	Name string
+	// Placeholder returns the bind parameter for the n-th argument, starting at 1.
+	Placeholder func(n int) string
+	// IDColumn is the definition of the auto-incrementing primary key.
+	IDColumn string
+	// Like is the case-insensitive LIKE operator.
+	Like string
+}
+
+// Store keeps every message as a row of the chat_messages table.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	// tenant scopes every query to the rows of one tenant; empty for a single-tenant bot
+	tenant string
+}
+
+// New returns a store on an open database and creates its schema if needed.
+func New(ctx context.Context, db *sql.DB, dialect Dialect) (*Store, error) {
+	s := &Store{db: db, dialect: dialect}
+	if err := s.migrate(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ForTenant returns a store on the same database that only sees the tenant's conversations,
+// so tenants can share the chat_messages table.
+func (s *Store) ForTenant(tenant string) *Store {
+	scoped := *s
+	scoped.tenant = tenant
+	return &scoped
+}
+
+// DB returns the underlying database, for queries the store does not cover.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS chat_messages (
+			id ` + s.dialect.IDColumn + `,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT NOT NULL,
+			message_uuid TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS chat_messages_user_id_idx ON chat_messages (user_id, id)`,
+		`CREATE INDEX IF NOT EXISTS chat_messages_created_at_idx ON chat_messages (created_at)`,
+		`CREATE TABLE IF NOT EXISTS chat_summaries (
+			tenant TEXT NOT NULL DEFAULT '',
+			user_id TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			message_count INTEGER NOT NULL,
+			last_message_time TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (tenant, user_id)
+		)`,
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	// Columns added after the table was first released
+	columns := []struct{ name, definition string }{
+		{"agent", "TEXT NOT NULL DEFAULT ''"},
+		{"model", "TEXT NOT NULL DEFAULT ''"},
+		{"tenant", "TEXT NOT NULL DEFAULT ''"},
+		{"variant", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, column := range columns {
+		if err := s.ensureColumn(ctx, column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	_, err := s.db.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS chat_messages_tenant_user_id_idx ON chat_messages (tenant, user_id, id)`)
+	return err
+}
+
+// ensureColumn adds a column to chat_messages unless it already exists.
+func (s *Store) ensureColumn(ctx context.Context, name, definition string) error {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+name+` FROM chat_messages LIMIT 0`)
+	if err == nil {
+		return rows.Close()
+	}
+	_, err = s.db.ExecContext(ctx, `ALTER TABLE chat_messages ADD COLUMN `+name+` `+definition)
+	return err
+}
+
+// query rewrites the ? placeholders of q into the dialect's bind parameters.
+func (s *Store) query(q string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString(s.dialect.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Append inserts a message into the user's conversation.
+func (s *Store) Append(ctx context.Context, userID string, message store.ChatMessage) error {
+	_, err := s.db.ExecContext(ctx,
+		s.query(`INSERT INTO chat_messages (tenant, user_id, role, content, message_uuid, agent, model, variant, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		s.tenant, userID, message.Role, message.Content, message.MessageUUID, message.Agent, message.Model, message.Variant, message.Timestamp.UTC(),
+	)
+	return err
+}
+
+// Range returns up to limit messages of the conversation starting at offset.
+func (s *Store) Range(ctx context.Context, userID string, offset, limit int) ([]store.ChatMessage, error) {
+	q := `SELECT role, content, message_uuid, agent, model, variant, created_at FROM chat_messages WHERE tenant = ? AND user_id = ? ORDER BY id`
+	args := []any{s.tenant, userID}
+	if limit > 0 {
+		q += ` LIMIT ` + strconv.Itoa(limit) + ` OFFSET ` + strconv.Itoa(offset)
+	} else if offset > 0 {
+		// SQLite only accepts OFFSET after a LIMIT, where -1 means no limit
+		if s.dialect.Name == "sqlite" {
+			q += ` LIMIT -1 OFFSET ` + strconv.Itoa(offset)
+		} else {
+			q += ` OFFSET ` + strconv.Itoa(offset)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.query(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []store.ChatMessage
+	for rows.Next() {
+		var msg store.ChatMessage
+		if err := rows.Scan(&msg.Role, &msg.Content, &msg.MessageUUID, &msg.Agent, &msg.Model, &msg.Variant, &msg.Timestamp); err != nil {
+			return nil, err
+		}
+		msg.Timestamp = msg.Timestamp.Local()
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// Paginate returns a page of the user's conversation.
+func (s *Store) Paginate(ctx context.Context, userID string, page, pageSize int) (store.PaginatedMessages, error) {
+	var total int
+	err := s.db.QueryRowContext(ctx,
+		s.query(`SELECT COUNT(*) FROM chat_messages WHERE tenant = ? AND user_id = ?`), s.tenant, userID,
+	).Scan(&total)
+	if err != nil {
+		return store.PaginatedMessages{}, err
+	}
+
+	page, totalPages, offset := store.PageBounds(total, page, pageSize)
+	if total == 0 {
+		return store.PaginatedMessages{
+			Messages: []store.ChatMessage{},
+			Page:     page,
+		}, nil
+	}
+
+	messages, err := s.Range(ctx, userID, offset, pageSize)
+	if err != nil {
+		return store.PaginatedMessages{}, err
+	}
+
+	return store.PaginatedMessages{
+		Messages:      messages,
+		TotalMessages: total,
+		Page:          page,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// Summaries returns a page of conversations ordered by last message time, using keyset
+// pagination on (last message time, user ID).
+func (s *Store) Summaries(ctx context.Context, query store.ConversationQuery) (store.ConversationList, error) {
+	q := `
+		SELECT m.user_id, m.content, m.created_at, c.message_count
+		FROM chat_messages m
+		JOIN (
+			SELECT user_id, MAX(id) AS last_id, COUNT(*) AS message_count
+			FROM chat_messages
+			WHERE tenant = ?
+			GROUP BY user_id
+		) c ON m.id = c.last_id
+		WHERE 1 = 1`
+	args := []any{s.tenant}
+	if !query.Since.IsZero() {
+		q += ` AND m.created_at >= ?`
+		args = append(args, query.Since.UTC())
+	}
+	if !query.Until.IsZero() {
+		q += ` AND m.created_at < ?`
+		args = append(args, query.Until.UTC())
+	}
+	if query.UserIDs != nil {
+		if len(query.UserIDs) == 0 {
+			return store.ConversationList{}, nil
+		}
+		q += ` AND m.user_id IN (?` + strings.Repeat(`, ?`, len(query.UserIDs)-1) + `)`
+		for _, userID := range query.UserIDs {
+			args = append(args, userID)
+		}
+	}
+
+	direction, comparison := "DESC", "<"
+	if query.Order == store.OldestFirst {
+		direction, comparison = "ASC", ">"
+	}
+	if query.Cursor != "" {
+		cursorTime, cursorUser, err := store.DecodeCursor(query.Cursor)
+		if err != nil {
+			return store.ConversationList{}, err
+		}
+		q += ` AND (m.created_at ` + comparison + ` ? OR (m.created_at = ? AND m.user_id ` + comparison + ` ?))`
+		args = append(args, cursorTime.UTC(), cursorTime.UTC(), cursorUser)
+	}
+	q += ` ORDER BY m.created_at ` + direction + `, m.user_id ` + direction
+	if query.Limit > 0 {
+		q += ` LIMIT ` + strconv.Itoa(query.Limit+1)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.query(q), args...)
+	if err != nil {
+		return store.ConversationList{}, err
+	}
+	defer rows.Close()
+
+	var list store.ConversationList
+	for rows.Next() {
+		var (
+			summary store.ConversationSummary
+			content string
+			last    time.Time
+		)
+		if err := rows.Scan(&summary.UserID, &content, &last, &summary.MessageCount); err != nil {
+			return store.ConversationList{}, err
+		}
+		summary.LastMessageTime = last.Local()
+		summary.LastMessagePreview = store.Preview(content)
+		list.Conversations = append(list.Conversations, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return store.ConversationList{}, err
+	}
+
+	if query.Limit > 0 && len(list.Conversations) > query.Limit {
+		list.Conversations = list.Conversations[:query.Limit]
+		last := list.Conversations[query.Limit-1]
+		list.NextCursor = store.EncodeCursor(last.LastMessageTime, last.UserID)
+	}
+	return list, nil
+}
+
+// Delete removes every message of the user's conversation and its rolling summary.
+func (s *Store) Delete(ctx context.Context, userID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM chat_messages WHERE tenant = ? AND user_id = ?`), s.tenant, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM chat_summaries WHERE tenant = ? AND user_id = ?`), s.tenant, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetHistorySummary returns the rolling summary of the user's conversation, if any.
+func (s *Store) GetHistorySummary(ctx context.Context, userID string) (store.HistorySummary, bool, error) {
+	var summary store.HistorySummary
+	err := s.db.QueryRowContext(ctx, s.query(`
+		SELECT summary, message_count, last_message_time, updated_at
+		FROM chat_summaries
+		WHERE tenant = ? AND user_id = ?`), s.tenant, userID).
+		Scan(&summary.Summary, &summary.MessageCount, &summary.LastMessageTime, &summary.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.HistorySummary{}, false, nil
+	}
+	if err != nil {
+		return store.HistorySummary{}, false, err
+	}
+	summary.LastMessageTime = summary.LastMessageTime.Local()
+	summary.UpdatedAt = summary.UpdatedAt.Local()
+	return summary, true, nil
+}
+
+// SaveHistorySummary replaces the rolling summary of the user's conversation.
+func (s *Store) SaveHistorySummary(ctx context.Context, userID string, summary store.HistorySummary) error {
+	if summary.UpdatedAt.IsZero() {
+		summary.UpdatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, s.query(`
+		INSERT INTO chat_summaries (tenant, user_id, summary, message_count, last_message_time, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tenant, user_id) DO UPDATE SET
+			summary = excluded.summary,
+			message_count = excluded.message_count,
+			last_message_time = excluded.last_message_time,
+			updated_at = excluded.updated_at`),
+		s.tenant, userID, summary.Summary, summary.MessageCount, summary.LastMessageTime.UTC(), summary.UpdatedAt.UTC())
+	return err
+}
+
+// Search returns the most recent messages containing every word of the query.
+func (s *Store) Search(ctx context.Context, query store.SearchQuery) ([]store.SearchResult, error) {
+	words := strings.Fields(query.Text)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = store.DefaultSearchLimit
+	}
+
+	q := `
+		SELECT m.user_id, m.role, m.content, m.message_uuid, m.agent, m.model, m.variant, m.created_at,
+			(SELECT COUNT(*) FROM chat_messages p WHERE p.tenant = m.tenant AND p.user_id = m.user_id AND p.id < m.id)
+		FROM chat_messages m
+		WHERE m.tenant = ?`
+	args := []any{s.tenant}
+	for _, word := range words {
+		q += ` AND m.content ` + s.dialect.Like + ` ? ESCAPE '\'`
+		args = append(args, "%"+likeEscaper.Replace(word)+"%")
+	}
+	if query.UserID != "" {
+		q += ` AND m.user_id = ?`
+		args = append(args, query.UserID)
+	}
+	q += ` ORDER BY m.id DESC LIMIT ` + strconv.Itoa(limit)
+
+	rows, err := s.db.QueryContext(ctx, s.query(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []store.SearchResult
+	for rows.Next() {
+		var result store.SearchResult
+		msg := &result.Message
+		if err := rows.Scan(&result.UserID, &msg.Role, &msg.Content, &msg.MessageUUID, &msg.Agent, &msg.Model, &msg.Variant, &msg.Timestamp, &result.Position); err != nil {
+			return nil, err
+		}
+		msg.Timestamp = msg.Timestamp.Local()
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// likeEscaper escapes the LIKE wildcards of a search word.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// DeleteBefore removes the messages older than the given time from every
+// conversation of the tenant and returns how many were deleted, for deployments that
+// still want a retention period on durable history. The rolling summaries of the
+// conversations it trims are removed too, since they no longer match what is left.
+func (s *Store) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, s.query(`
+		DELETE FROM chat_summaries
+		WHERE tenant = ? AND user_id IN (SELECT user_id FROM chat_messages WHERE tenant = ? AND created_at < ?)`),
+		s.tenant, s.tenant, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, s.query(`DELETE FROM chat_messages WHERE tenant = ? AND created_at < ?`), s.tenant, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit()
+}
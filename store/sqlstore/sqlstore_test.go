@@ -0,0 +1,241 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/store"
+)
+
+func TestSQLiteStore_SummariesPagination(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer s.Close()
+
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	users := []string{"a", "b", "c", "d", "e"}
+	for i, user := range users {
+		// b and c share the same last message time to exercise the cursor tie-break
+		at := start.Add(time.Duration(i) * time.Hour)
+		if user == "c" {
+			at = start.Add(time.Hour)
+		}
+		if err := s.Append(ctx, user, store.ChatMessage{Role: "user", Content: "oi " + user, Timestamp: at}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	var got []string
+	query := store.ConversationQuery{Limit: 2}
+	for {
+		list, err := s.Summaries(ctx, query)
+		if err != nil {
+			t.Fatalf("Summaries: %v", err)
+		}
+		for _, summary := range list.Conversations {
+			got = append(got, summary.UserID)
+		}
+		if list.NextCursor == "" {
+			break
+		}
+		query.Cursor = list.NextCursor
+	}
+	if strings.Join(got, ",") != "e,d,c,b,a" {
+		t.Fatalf("newest first pages = %v", got)
+	}
+
+	list, err := s.Summaries(ctx, store.ConversationQuery{
+		Order: store.OldestFirst,
+		Since: start.Add(time.Hour),
+		Until: start.Add(4 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Summaries: %v", err)
+	}
+	got = got[:0]
+	for _, summary := range list.Conversations {
+		got = append(got, summary.UserID)
+	}
+	if strings.Join(got, ",") != "b,c,d" || list.NextCursor != "" {
+		t.Fatalf("filtered oldest first = %v, cursor %q", got, list.NextCursor)
+	}
+
+	list, err = s.Summaries(ctx, store.ConversationQuery{UserIDs: []string{"a", "d", "z"}})
+	if err != nil {
+		t.Fatalf("Summaries: %v", err)
+	}
+	got = got[:0]
+	for _, summary := range list.Conversations {
+		got = append(got, summary.UserID)
+	}
+	if strings.Join(got, ",") != "d,a" {
+		t.Fatalf("restricted to users = %v", got)
+	}
+}
+
+func TestSQLiteStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer s.Close()
+
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i, content := range []string{"oi", "olá! como posso ajudar?", "quero um pedido", "claro"} {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		msg := store.ChatMessage{Role: role, Content: content, Timestamp: start.Add(time.Duration(i) * time.Minute)}
+		if err := s.Append(ctx, "5511999999999", msg); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := s.Append(ctx, "5511888888888", store.UserMessage("bom dia", "uuid-1")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	history, err := store.History(ctx, s, "5511999999999")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 4 || history[2].Content != "quero um pedido" || !history[3].Timestamp.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	tail, err := s.Range(ctx, "5511999999999", 3, 0)
+	if err != nil || len(tail) != 1 || tail[0].Content != "claro" {
+		t.Fatalf("Range from offset = %+v, %v", tail, err)
+	}
+
+	page, err := s.Paginate(ctx, "5511999999999", 5, 3)
+	if err != nil {
+		t.Fatalf("Paginate: %v", err)
+	}
+	if page.Page != 2 || page.TotalPages != 2 || page.TotalMessages != 4 || len(page.Messages) != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	list, err := s.Summaries(ctx, store.ConversationQuery{})
+	if err != nil {
+		t.Fatalf("Summaries: %v", err)
+	}
+	counts := map[string]int{}
+	for _, summary := range list.Conversations {
+		counts[summary.UserID] = summary.MessageCount
+	}
+	if counts["5511999999999"] != 4 || counts["5511888888888"] != 1 {
+		t.Fatalf("unexpected summaries: %+v", list.Conversations)
+	}
+
+	results, err := s.Search(ctx, store.SearchQuery{Text: "PEDIDO quero"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].UserID != "5511999999999" || results[0].Position != 2 {
+		t.Fatalf("unexpected search results: %+v", results)
+	}
+
+	if err := s.Delete(ctx, "5511999999999"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	history, err = store.History(ctx, s, "5511999999999")
+	if err != nil || len(history) != 0 {
+		t.Fatalf("history after Delete = %+v, %v", history, err)
+	}
+}
+
+func TestSQLiteStore_ForTenant(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer s.Close()
+
+	acme, globex := s.ForTenant("acme"), s.ForTenant("globex")
+	if err := acme.Append(ctx, "5511999999999", store.UserMessage("quero um pedido", "uuid-1")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := globex.Append(ctx, "5511999999999", store.UserMessage("bom dia", "uuid-2")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	history, err := store.History(ctx, acme, "5511999999999")
+	if err != nil || len(history) != 1 || history[0].Content != "quero um pedido" {
+		t.Fatalf("acme history = %+v, %v", history, err)
+	}
+	list, err := s.Summaries(ctx, store.ConversationQuery{})
+	if err != nil || len(list.Conversations) != 0 {
+		t.Fatalf("default tenant summaries = %+v, %v", list.Conversations, err)
+	}
+	results, err := globex.Search(ctx, store.SearchQuery{Text: "pedido"})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("globex search = %+v, %v", results, err)
+	}
+
+	if err := acme.Delete(ctx, "5511999999999"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	history, err = store.History(ctx, globex, "5511999999999")
+	if err != nil || len(history) != 1 {
+		t.Fatalf("globex history after acme Delete = %+v, %v", history, err)
+	}
+}
+
+func TestSQLiteStore_HistorySummary(t *testing.T) {
+	ctx := context.Background()
+	s, err := OpenSQLite(ctx, filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer s.Close()
+
+	acme, globex := s.ForTenant("acme"), s.ForTenant("globex")
+	userID := "5511999999999"
+	lastMessage := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tenantStore := range []*Store{acme, globex} {
+		if err := tenantStore.Append(ctx, userID, store.UserMessage("quero um pedido", "uuid-1")); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		summary := store.HistorySummary{Summary: "Cliente quer um pedido", MessageCount: 1, LastMessageTime: lastMessage}
+		if err := tenantStore.SaveHistorySummary(ctx, userID, summary); err != nil {
+			t.Fatalf("SaveHistorySummary: %v", err)
+		}
+	}
+	if err := acme.SaveHistorySummary(ctx, userID, store.HistorySummary{Summary: "Cliente quer dois pedidos", MessageCount: 1, LastMessageTime: lastMessage}); err != nil {
+		t.Fatalf("SaveHistorySummary: %v", err)
+	}
+
+	summary, found, err := acme.GetHistorySummary(ctx, userID)
+	if err != nil || !found {
+		t.Fatalf("GetHistorySummary = %v, %v", found, err)
+	}
+	if summary.Summary != "Cliente quer dois pedidos" || summary.MessageCount != 1 || !summary.LastMessageTime.Equal(lastMessage) {
+		t.Errorf("summary = %+v, want the replaced summary", summary)
+	}
+
+	if err := acme.Delete(ctx, userID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, found, err := acme.GetHistorySummary(ctx, userID); err != nil || found {
+		t.Errorf("summary after Delete: found=%v err=%v, want it deleted with the conversation", found, err)
+	}
+	if _, found, err := globex.GetHistorySummary(ctx, userID); err != nil || !found {
+		t.Errorf("globex summary after acme Delete: found=%v err=%v", found, err)
+	}
+
+	if _, err := globex.DeleteBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("DeleteBefore: %v", err)
+	}
+	if _, found, err := globex.GetHistorySummary(ctx, userID); err != nil || found {
+		t.Errorf("summary after DeleteBefore: found=%v err=%v, want it deleted with the messages", found, err)
+	}
+}
@@ -0,0 +1,200 @@
+// Package store defines how conversation history is persisted. The Redis
+// implementation keeps recent history with a retention window; the sqlstore
+// package keeps durable, queryable history in PostgreSQL or an embedded SQLite file.
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message roles.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	// RoleAgent marks messages sent by a human agent who took over the conversation
+	RoleAgent = "agent"
+)
+
+// ChatMessage is a message of a conversation as stored in history.
+type ChatMessage struct {
+	Role        string    `json:"role"`
+	Content     string    `json:"content"`
+	Timestamp   time.Time `json:"timestamp"`
+	MessageUUID string    `json:"message_uuid,omitempty"`
+	// Agent identifies the human agent who sent a RoleAgent message
+	Agent string `json:"agent,omitempty"`
+	// Model is the model that generated a RoleAssistant message
+	Model string `json:"model,omitempty"`
+	// Variant is the experiment variant of the user when the message was stored
+	Variant string `json:"variant,omitempty"`
+}
+
+// ConversationSummary represents a conversation summary
+type ConversationSummary struct {
+	UserID             string
+	LastMessageTime    time.Time
+	LastMessagePreview string
+	MessageCount       int
+}
+
+// PaginatedMessages represents paginated chat messages
+type PaginatedMessages struct {
+	Messages      []ChatMessage
+	TotalMessages int
+	Page          int
+	TotalPages    int
+}
+
+// ConversationOrder sorts conversation listings by the time of their last message.
+type ConversationOrder string
+
+const (
+	NewestFirst ConversationOrder = "newest"
+	OldestFirst ConversationOrder = "oldest"
+)
+
+// ConversationQuery selects a page of conversations.
+type ConversationQuery struct {
+	// Since and Until bound the last message time; Until is exclusive and zero values are unbounded
+	Since time.Time
+	Until time.Time
+	// Order defaults to NewestFirst
+	Order ConversationOrder
+	// Limit is the page size; zero or less returns every conversation
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+	// UserIDs restricts the listing to these conversations when not nil, such as the ones with a tag
+	UserIDs []string
+}
+
+// ConversationList is a page of conversation summaries.
+type ConversationList struct {
+	Conversations []ConversationSummary
+	// NextCursor fetches the next page; it is empty on the last page
+	NextCursor string
+}
+
+// ConversationStore persists the message history of each user's conversation.
+type ConversationStore interface {
+	// Append adds a message to the end of the user's conversation.
+	Append(ctx context.Context, userID string, message ChatMessage) error
+	// Range returns up to limit messages starting at offset, oldest first.
+	// A limit of zero or less returns every message from offset on.
+	Range(ctx context.Context, userID string, offset, limit int) ([]ChatMessage, error)
+	// Paginate returns a page of the conversation, pages starting at 1.
+	Paginate(ctx context.Context, userID string, page, pageSize int) (PaginatedMessages, error)
+	// Summaries returns a page of conversation summaries without scanning every conversation.
+	Summaries(ctx context.Context, query ConversationQuery) (ConversationList, error)
+	// Delete removes the user's conversation.
+	Delete(ctx context.Context, userID string) error
+}
+
+// HistorySummary is the rolling summary of the oldest part of a conversation.
+// MessageCount is how many messages from the start of the history it covers and
+// LastMessageTime the timestamp of the last of them, so a summary that no longer
+// matches the stored history (e.g. after it was cleared) can be detected.
+type HistorySummary struct {
+	Summary         string    `json:"summary"`
+	MessageCount    int       `json:"message_count"`
+	LastMessageTime time.Time `json:"last_message_time"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// HistorySummaryStore is implemented by conversation stores that keep the rolling summary
+// of each conversation next to its messages, so the summary is removed with them.
+type HistorySummaryStore interface {
+	// GetHistorySummary returns the summary of the user's conversation, if any.
+	GetHistorySummary(ctx context.Context, userID string) (HistorySummary, bool, error)
+	// SaveHistorySummary replaces the summary of the user's conversation.
+	SaveHistorySummary(ctx context.Context, userID string, summary HistorySummary) error
+}
+
+// UserMessage returns a message sent by the user, timestamped now.
+func UserMessage(content, messageUUID string) ChatMessage {
+	return ChatMessage{
+		Role:        RoleUser,
+		Content:     content,
+		Timestamp:   time.Now(),
+		MessageUUID: messageUUID,
+	}
+}
+
+// AssistantMessage returns a message sent by the bot, timestamped now.
+func AssistantMessage(content string) ChatMessage {
+	return ChatMessage{
+		Role:      RoleAssistant,
+		Content:   content,
+		Timestamp: time.Now(),
+	}
+}
+
+// AgentMessage returns a message sent by a human agent, timestamped now.
+func AgentMessage(agent, content string) ChatMessage {
+	return ChatMessage{
+		Role:      RoleAgent,
+		Content:   content,
+		Timestamp: time.Now(),
+		Agent:     agent,
+	}
+}
+
+// History returns the whole conversation of the user.
+func History(ctx context.Context, s ConversationStore, userID string) ([]ChatMessage, error) {
+	return s.Range(ctx, userID, 0, 0)
+}
+
+// PageBounds clamps page to the valid range for total messages and returns it with
+// the number of pages and the offset of its first message.
+func PageBounds(total, page, pageSize int) (clampedPage, totalPages, offset int) {
+	if total == 0 {
+		return page, 0, 0
+	}
+	totalPages = (total + pageSize - 1) / pageSize
+	if page > totalPages {
+		page = totalPages
+	}
+	if page < 1 {
+		page = 1
+	}
+	return page, totalPages, (page - 1) * pageSize
+}
+
+// Preview shortens a message to the first 100 characters for conversation listings.
+func Preview(content string) string {
+	if len(content) > 100 {
+		return content[:100] + "..."
+	}
+	return content
+}
+
+// ErrInvalidCursor is returned for a cursor that was not produced by a previous listing.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor returns the cursor that resumes a listing after the given conversation.
+func EncodeCursor(lastMessageTime time.Time, userID string) string {
+	raw := strconv.FormatInt(lastMessageTime.UnixNano(), 10) + ":" + userID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor returns the last message time and user ID encoded in a cursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	nanos, userID, found := strings.Cut(string(raw), ":")
+	if !found || userID == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return time.Unix(0, unixNano), userID, nil
+}
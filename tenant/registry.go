@@ -0,0 +1,274 @@
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/NextMind-AI/chatbot-go/processor"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrNotFound is returned for unknown tenant IDs.
+	ErrNotFound = errors.New("tenant not found")
+	// ErrConflict is returned when a definition claims a number or Redis prefix of another tenant.
+	ErrConflict = errors.New("tenant conflict")
+)
+
+// Builder creates the message processor serving a tenant, with its background workers started.
+type Builder func(Definition) (*processor.MessageProcessor, error)
+
+// Tenant is a tenant being served.
+type Tenant struct {
+	Definition Definition
+	Processor  *processor.MessageProcessor
+}
+
+// Registry holds the tenants of the process and routes numbers to them.
+type Registry struct {
+	redisClient *redis.Client
+	build       Builder
+
+	mu      sync.RWMutex
+	tenants map[string]Tenant
+	// numbers maps each number or channel ID to the tenant owning it
+	numbers map[string]string
+}
+
+// NewRegistry creates an empty registry that stores definitions with redisClient, which
+// must not be prefixed, and serves them with processors made by build.
+func NewRegistry(redisClient *redis.Client, build Builder) *Registry {
+	return &Registry{
+		redisClient: redisClient,
+		build:       build,
+		tenants:     map[string]Tenant{},
+		numbers:     map[string]string{},
+	}
+}
+
+// Load stores the given definitions, replacing the stored ones with the same ID, then
+// serves every stored tenant, including those created through the admin API.
+func (r *Registry) Load(definitions []Definition) error {
+	for _, definition := range definitions {
+		definition.Normalize()
+		if err := definition.Validate(); err != nil {
+			return err
+		}
+		if err := r.save(definition); err != nil {
+			return err
+		}
+	}
+
+	stored, err := r.redisClient.GetTenants()
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(stored))
+	for id := range stored {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		var definition Definition
+		if err := json.Unmarshal([]byte(stored[id]), &definition); err != nil {
+			return fmt.Errorf("tenant %s: %w", id, err)
+		}
+		r.mu.Lock()
+		err := r.install(definition, false)
+		r.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Put creates or replaces a tenant and tells the other server instances about it.
+// The replaced tenant's processor is stopped; replies it is generating still finish.
+func (r *Registry) Put(definition Definition) (Definition, error) {
+	definition.Normalize()
+	if err := definition.Validate(); err != nil {
+		return Definition{}, err
+	}
+
+	r.mu.Lock()
+	err := r.install(definition, true)
+	r.mu.Unlock()
+	if err != nil {
+		return Definition{}, err
+	}
+	r.publish(definition.ID)
+	return definition, nil
+}
+
+// Delete stops serving a tenant and removes its definition. Its data is kept, so
+// creating the tenant again with the same Redis prefix restores its conversations.
+func (r *Registry) Delete(id string) error {
+	r.mu.Lock()
+	_, ok := r.tenants[id]
+	if ok {
+		r.uninstall(id)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+
+	if err := r.redisClient.DeleteTenant(id); err != nil {
+		return err
+	}
+	r.publish(id)
+	return nil
+}
+
+// Get returns the tenant with the ID.
+func (r *Registry) Get(id string) (Tenant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tenant, ok := r.tenants[id]
+	return tenant, ok
+}
+
+// Resolve returns the tenant owning the number or channel ID.
+func (r *Registry) Resolve(number string) (Tenant, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.numbers[NormalizeNumber(number)]
+	if !ok {
+		return Tenant{}, false
+	}
+	return r.tenants[id], true
+}
+
+// List returns the definitions of every tenant, sorted by ID.
+func (r *Registry) List() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	definitions := make([]Definition, 0, len(r.tenants))
+	for _, tenant := range r.tenants {
+		definitions = append(definitions, tenant.Definition)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].ID < definitions[j].ID
+	})
+	return definitions
+}
+
+// Watch applies the changes other server instances make to the tenants until the
+// returned function is called.
+func (r *Registry) Watch() (func(), error) {
+	updates, unsubscribe, err := r.redisClient.SubscribeTenantUpdates()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for id := range updates {
+			r.reload(id)
+		}
+	}()
+	return unsubscribe, nil
+}
+
+// reload serves the stored definition of a tenant, or stops serving it once deleted.
+func (r *Registry) reload(id string) {
+	data, err := r.redisClient.GetTenant(id)
+	if err != nil {
+		log.Error().Err(err).Str("tenant", id).Msg("Error loading updated tenant")
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if data == nil {
+		if _, ok := r.tenants[id]; ok {
+			r.uninstall(id)
+		}
+		return
+	}
+
+	var definition Definition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		log.Error().Err(err).Str("tenant", id).Msg("Error parsing updated tenant")
+		return
+	}
+	// The instance that made the change already serves it
+	if current, ok := r.tenants[id]; ok && reflect.DeepEqual(current.Definition, definition) {
+		return
+	}
+	if err := r.install(definition, false); err != nil {
+		log.Error().Err(err).Str("tenant", id).Msg("Error serving updated tenant")
+	}
+}
+
+// install builds and serves a tenant in place of any previous version, storing the
+// definition first when save is set. Must be called with mu held.
+func (r *Registry) install(definition Definition, save bool) error {
+	for _, number := range definition.Numbers {
+		if owner, ok := r.numbers[number]; ok && owner != definition.ID {
+			return fmt.Errorf("%w: %s belongs to tenant %s", ErrConflict, number, owner)
+		}
+	}
+	for id, tenant := range r.tenants {
+		if id != definition.ID && tenant.Definition.RedisPrefix == definition.RedisPrefix {
+			return fmt.Errorf("%w: redis_prefix %q is used by tenant %s", ErrConflict, definition.RedisPrefix, id)
+		}
+	}
+
+	messageProcessor, err := r.build(definition)
+	if err != nil {
+		return err
+	}
+	if save {
+		if err := r.save(definition); err != nil {
+			messageProcessor.Stop()
+			return err
+		}
+	}
+
+	if _, ok := r.tenants[definition.ID]; ok {
+		r.uninstall(definition.ID)
+	}
+	r.tenants[definition.ID] = Tenant{Definition: definition, Processor: messageProcessor}
+	for _, number := range definition.Numbers {
+		r.numbers[number] = definition.ID
+	}
+
+	log.Info().
+		Str("tenant", definition.ID).
+		Strs("numbers", definition.Numbers).
+		Msg("Serving tenant")
+	return nil
+}
+
+// uninstall stops serving a tenant. Must be called with mu held.
+func (r *Registry) uninstall(id string) {
+	tenant := r.tenants[id]
+	tenant.Processor.Stop()
+	delete(r.tenants, id)
+	for _, number := range tenant.Definition.Numbers {
+		delete(r.numbers, number)
+	}
+	log.Info().Str("tenant", id).Msg("Stopped serving tenant")
+}
+
+func (r *Registry) save(definition Definition) error {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		return err
+	}
+	return r.redisClient.SaveTenant(definition.ID, data)
+}
+
+// publish tells the other instances about a change; they catch up on restart if it fails.
+func (r *Registry) publish(id string) {
+	if err := r.redisClient.PublishTenantUpdate(id); err != nil {
+		log.Error().Err(err).Str("tenant", id).Msg("Error publishing tenant update")
+	}
+}
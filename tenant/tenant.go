@@ -0,0 +1,106 @@
+// Package tenant serves several businesses from one process. Each tenant owns the
+// WhatsApp numbers or channel IDs its messages arrive on and answers them with its own
+// prompt, tools, model, voice, Vonage credentials and Redis key prefix; CRM clients
+// only see the tenants their credentials grant.
+//
+// Definitions come from a JSON file or the admin API and are kept in Redis, so every
+// server instance serves the same tenants and picks up changes made on another one.
+package tenant
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Definition describes a tenant.
+type Definition struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	Numbers      []string `json:"numbers"`                 // Inbound To numbers or channel IDs routed to the tenant
+	Prompt       string   `json:"prompt,omitempty"`        // System prompt; empty uses the bot's prompt generator
+	Tools        []string `json:"tools"`                   // Names of the bot's tools offered to the model; nil offers all of them
+	Model        string   `json:"model,omitempty"`         // Empty uses the bot's model
+	VoiceID      string   `json:"voice_id,omitempty"`      // ElevenLabs voice of audio replies; empty uses the bot's voice
+	VonageJWT    string   `json:"vonage_jwt,omitempty"`    // Empty uses the bot's Vonage credentials
+	SenderNumber string   `json:"sender_number,omitempty"` // Number replies are sent from (default the first of Numbers)
+	RedisPrefix  string   `json:"redis_prefix,omitempty"`  // Prefix of the tenant's Redis keys (default "tenant:{id}:")
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ErrInvalidDefinition is returned for definitions that cannot be served.
+var ErrInvalidDefinition = errors.New("invalid tenant definition")
+
+// Normalize fills in the defaults and cleans up the numbers, which Vonage sends without "+".
+func (d *Definition) Normalize() {
+	for i, number := range d.Numbers {
+		d.Numbers[i] = NormalizeNumber(number)
+	}
+	d.SenderNumber = NormalizeNumber(d.SenderNumber)
+	if d.SenderNumber == "" && len(d.Numbers) > 0 {
+		d.SenderNumber = d.Numbers[0]
+	}
+	if d.RedisPrefix == "" {
+		d.RedisPrefix = "tenant:" + d.ID + ":"
+	}
+}
+
+// Validate checks a normalized definition.
+func (d *Definition) Validate() error {
+	if !idPattern.MatchString(d.ID) {
+		return fmt.Errorf("%w: id %q must be 1 to 64 lowercase letters, digits, '-' or '_'", ErrInvalidDefinition, d.ID)
+	}
+	if len(d.Numbers) == 0 {
+		return fmt.Errorf("%w: tenant %s has no numbers", ErrInvalidDefinition, d.ID)
+	}
+	seen := make(map[string]bool, len(d.Numbers))
+	for _, number := range d.Numbers {
+		if number == "" {
+			return fmt.Errorf("%w: tenant %s has an empty number", ErrInvalidDefinition, d.ID)
+		}
+		if seen[number] {
+			return fmt.Errorf("%w: tenant %s lists %s twice", ErrInvalidDefinition, d.ID, number)
+		}
+		seen[number] = true
+	}
+	if strings.ContainsAny(d.RedisPrefix, "*?[]") {
+		return fmt.Errorf("%w: tenant %s redis_prefix must not contain glob characters", ErrInvalidDefinition, d.ID)
+	}
+	return nil
+}
+
+// numberFormatting is what people write in phone numbers and Vonage leaves out.
+var numberFormatting = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "")
+
+// NormalizeNumber removes the formatting and the leading "+" of a number.
+func NormalizeNumber(number string) string {
+	return strings.TrimPrefix(numberFormatting.Replace(number), "+")
+}
+
+// LoadFile reads the definitions from a JSON file holding an array of them.
+// Unknown fields are rejected so typos don't silently fall back to the defaults.
+func LoadFile(path string) ([]Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var definitions []Definition
+	if err := decoder.Decode(&definitions); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i := range definitions {
+		definitions[i].Normalize()
+		if err := definitions[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return definitions, nil
+}
@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	data := `[
+		{"id": "acme", "numbers": ["+55 11 99999-0000", "5511988880000"], "prompt": "Você é o assistente da Acme.", "tools": []},
+		{"id": "globex", "numbers": ["5521977770000"], "sender_number": "+5521966660000", "redis_prefix": "globex:"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	acme, globex := definitions[0], definitions[1]
+	if acme.SenderNumber != "5511999990000" || acme.RedisPrefix != "tenant:acme:" {
+		t.Fatalf("acme defaults = %+v", acme)
+	}
+	if acme.Tools == nil || len(acme.Tools) != 0 {
+		t.Fatalf("acme tools = %#v, want an empty list that offers no tools", acme.Tools)
+	}
+	if globex.SenderNumber != "5521966660000" || globex.RedisPrefix != "globex:" || globex.Tools != nil {
+		t.Fatalf("globex = %+v", globex)
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field":   `[{"id": "acme", "numbers": ["5511999990000"], "promt": "typo"}]`,
+		"invalid id":      `[{"id": "Acme Inc", "numbers": ["5511999990000"]}]`,
+		"no numbers":      `[{"id": "acme"}]`,
+		"repeated number": `[{"id": "acme", "numbers": ["5511999990000", "+5511999990000"]}]`,
+		"glob in prefix":  `[{"id": "acme", "numbers": ["5511999990000"], "redis_prefix": "acme*"}]`,
+	} {
+		path := filepath.Join(t.TempDir(), "tenants.json")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadFile(path)
+		if err == nil {
+			t.Errorf("%s: no error", name)
+		} else if name != "unknown field" && !errors.Is(err, ErrInvalidDefinition) {
+			t.Errorf("%s: error %v is not ErrInvalidDefinition", name, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package tracing provides OpenTelemetry tracing for a conversation turn.
+//
+// Every inbound message starts a trace in the message processor, with child spans for
+// the sleep analysis, tool calls, the response stream, each message sent and the HTTP
+// calls to Vonage, ElevenLabs and S3. Spans are dropped by the no-op tracer provider
+// OpenTelemetry starts with, until Setup installs an OTLP exporter:
+//
+//	shutdown, err := tracing.Setup(ctx, tracing.Config{
+//		Endpoint:    "http://localhost:4318",
+//		ServiceName: "chatbot",
+//	})
+//	defer shutdown(context.Background())
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/NextMind-AI/chatbot-go"
+
+// Config selects where traces are exported.
+type Config struct {
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://localhost:4318, to which /v1/traces
+	// is added when it has no path; empty disables export
+	Endpoint string
+	// ServiceName identifies the bot in the tracing backend (default "chatbot")
+	ServiceName string
+}
+
+// Setup installs a tracer provider that exports spans to cfg.Endpoint over OTLP/HTTP.
+// Headers and other exporter options are read from the standard OTEL_EXPORTER_OTLP_*
+// environment variables. With an empty endpoint it installs nothing and tracing stays a
+// no-op. The returned function flushes pending spans and stops the exporter.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint := cfg.Endpoint
+	if u, err := url.Parse(endpoint); err == nil && strings.Trim(u.Path, "/") == "" {
+		endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "chatbot"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if not nil, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Transport wraps base so every request made through it is recorded as a client span
+// named after service, e.g. "vonage POST". A nil base uses http.DefaultTransport.
+// Trace context is not propagated in request headers, since the calls go to third parties.
+func Transport(service string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base,
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return service + " " + r.Method
+		}),
+	)
+}
+
+// HTTPClient returns a copy of client whose requests are traced as calls to service.
+func HTTPClient(service string, client http.Client) http.Client {
+	client.Transport = Transport(service, client.Transport)
+	return client
+}
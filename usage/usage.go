@@ -0,0 +1,167 @@
+// Package usage accounts the tokens and speech characters consumed by each user and
+// tenant, prices them with a per-model table and enforces optional daily budgets.
+//
+// Totals are kept per day in Redis, where a day runs in the meter's time zone, so
+// budgets reset at local midnight. Costs are computed when usage is recorded, which
+// keeps past days at the prices in effect at the time.
+package usage
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/NextMind-AI/chatbot-go/llm"
+	"github.com/NextMind-AI/chatbot-go/redis"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DayLayout is the format of the days usage is grouped by.
+const DayLayout = "2006-01-02"
+
+// Price is what a model costs, in US dollars.
+type Price struct {
+	InputPerMillion       float64 // Per million prompt tokens
+	OutputPerMillion      float64 // Per million completion tokens
+	PerThousandCharacters float64 // Per thousand characters of synthesized speech
+}
+
+// Prices maps model names, as configured for the bot, to their price.
+type Prices map[string]Price
+
+// Cost returns the cost of the usage in nanodollars, and false when the model has no price.
+func (p Prices) Cost(model string, promptTokens, completionTokens, characters int64) (int64, bool) {
+	price, ok := p[model]
+	if !ok {
+		return 0, false
+	}
+	dollars := float64(promptTokens)*price.InputPerMillion/1e6 +
+		float64(completionTokens)*price.OutputPerMillion/1e6 +
+		float64(characters)*price.PerThousandCharacters/1e3
+	return int64(math.Round(dollars * 1e9)), true
+}
+
+// NanosToUSD converts nanodollars to US dollars.
+func NanosToUSD(nanos int64) float64 {
+	return float64(nanos) / 1e9
+}
+
+// Meter records usage in Redis and checks the users' daily budget.
+type Meter struct {
+	redisClient *redis.Client
+	tenant      string
+	prices      Prices
+	// dailyBudget is the cost, in nanodollars, a user may reach in a day; zero means unlimited
+	dailyBudget int64
+	location    *time.Location
+	// unpriced remembers the models already warned about
+	unpriced sync.Map
+}
+
+// NewMeter creates a meter that accounts usage to tenant. A zero dailyBudgetUSD disables
+// the budget, and a nil location counts days in UTC.
+func NewMeter(redisClient *redis.Client, tenant string, prices Prices, dailyBudgetUSD float64, location *time.Location) *Meter {
+	if location == nil {
+		location = time.UTC
+	}
+	return &Meter{
+		redisClient: redisClient,
+		tenant:      tenant,
+		prices:      prices,
+		dailyBudget: int64(math.Round(dailyBudgetUSD * 1e9)),
+		location:    location,
+	}
+}
+
+// Tenant returns the tenant usage is accounted to.
+func (m *Meter) Tenant() string {
+	return m.tenant
+}
+
+// Location returns the time zone days are counted in.
+func (m *Meter) Location() *time.Location {
+	return m.location
+}
+
+// DailyBudgetUSD returns the daily budget of each user, zero when unlimited.
+func (m *Meter) DailyBudgetUSD() float64 {
+	return NanosToUSD(m.dailyBudget)
+}
+
+// Day returns the day at falls on in the meter's time zone.
+func (m *Meter) Day(at time.Time) string {
+	return at.In(m.location).Format(DayLayout)
+}
+
+// RecordTokens records the tokens of a completion requested for the user. An empty
+// userID, for requests not made on behalf of a user, only counts towards the tenant.
+func (m *Meter) RecordTokens(userID, model string, usage llm.Usage) {
+	m.record(userID, redis.UsageRecord{
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+	})
+}
+
+// RecordCharacters records the characters of text converted to speech for the user.
+func (m *Meter) RecordCharacters(userID, model string, characters int) {
+	m.record(userID, redis.UsageRecord{
+		Model:      model,
+		Characters: int64(characters),
+	})
+}
+
+func (m *Meter) record(userID string, record redis.UsageRecord) {
+	cost, priced := m.prices.Cost(record.Model, record.PromptTokens, record.CompletionTokens, record.Characters)
+	if !priced {
+		if _, warned := m.unpriced.LoadOrStore(record.Model, true); !warned {
+			log.Warn().Str("model", record.Model).Msg("No price configured for model, its usage is recorded at no cost")
+		}
+	}
+	record.CostNanos = cost
+
+	if err := m.redisClient.RecordUsage(m.tenant, userID, m.Day(time.Now()), record); err != nil {
+		log.Error().
+			Err(err).
+			Str("user_id", userID).
+			Str("model", record.Model).
+			Msg("Error recording usage")
+	}
+}
+
+// OverBudget reports whether the user reached their daily budget today.
+func (m *Meter) OverBudget(userID string) (bool, error) {
+	if m.dailyBudget <= 0 {
+		return false, nil
+	}
+	cost, err := m.redisClient.GetUserDailyCost(userID, m.Day(time.Now()))
+	if err != nil {
+		return false, err
+	}
+	return cost >= m.dailyBudget, nil
+}
+
+// UserUsage returns the user's usage on each day.
+func (m *Meter) UserUsage(userID string, days []string) ([]redis.DailyUsage, error) {
+	return m.redisClient.GetUserUsage(userID, days)
+}
+
+// TenantUsage returns the tenant's usage on each day.
+func (m *Meter) TenantUsage(days []string) ([]redis.DailyUsage, error) {
+	return m.redisClient.GetTenantUsage(m.tenant, days)
+}
+
+// TenantUsers returns the users with usage on the day, most expensive first.
+func (m *Meter) TenantUsers(day string) ([]redis.UserUsage, error) {
+	return m.redisClient.GetTenantUsers(m.tenant, day)
+}
+
+// Days returns every day from from to to, inclusive, both formatted as DayLayout.
+func Days(from, to time.Time) []string {
+	var days []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(DayLayout))
+	}
+	return days
+}
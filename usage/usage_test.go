@@ -0,0 +1,45 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPricesCost(t *testing.T) {
+	prices := Prices{
+		"gpt-4.1-mini":           {InputPerMillion: 0.4, OutputPerMillion: 1.6},
+		"eleven_multilingual_v2": {PerThousandCharacters: 0.3},
+	}
+
+	cost, ok := prices.Cost("gpt-4.1-mini", 1000, 500, 0)
+	if !ok || cost != 1_200_000 {
+		t.Errorf("Cost = %d, %v, want 1200000 nanodollars", cost, ok)
+	}
+	cost, ok = prices.Cost("eleven_multilingual_v2", 0, 0, 250)
+	if !ok || cost != 75_000_000 {
+		t.Errorf("speech Cost = %d, %v, want 75000000 nanodollars", cost, ok)
+	}
+	if cost, ok := prices.Cost("unknown", 1000, 1000, 0); ok || cost != 0 {
+		t.Errorf("unpriced Cost = %d, %v, want 0, false", cost, ok)
+	}
+}
+
+func TestDays(t *testing.T) {
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	from := time.Date(2025, 2, 27, 0, 0, 0, 0, location)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, location)
+
+	days := Days(from, to)
+	want := []string{"2025-02-27", "2025-02-28", "2025-03-01"}
+	if len(days) != len(want) {
+		t.Fatalf("Days = %v, want %v", days, want)
+	}
+	for i := range want {
+		if days[i] != want[i] {
+			t.Errorf("Days[%d] = %q, want %q", i, days[i], want[i])
+		}
+	}
+}
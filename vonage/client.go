@@ -2,6 +2,8 @@ package vonage
 
 import (
 	"net/http"
+
+	"github.com/NextMind-AI/chatbot-go/tracing"
 )
 
 type Client struct {
@@ -10,6 +12,9 @@ type Client struct {
 }
 
 func NewClient(vonageJWT, geospecificMessagesAPIURL, messagesAPIURL, phoneNumberID string, httpClient http.Client) Client {
+	// Requests are recorded as spans of the trace in the context they are sent with
+	httpClient = tracing.HTTPClient("vonage", httpClient)
+
 	client := Client{
 		config: Config{
 			VonageJWT:                 vonageJWT,
@@ -2,15 +2,25 @@ package vonage
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/NextMind-AI/chatbot-go/metrics"
 )
 
-func (c *Client) sendMessageRequest(method, url string, body any) (*MessageResponse, error) {
-	respBody, err := c.sendRequest(method, url, body)
+func (c *Client) sendMessageRequest(ctx context.Context, method, url string, message WhatsAppMessage) (*MessageResponse, error) {
+	respBody, err := c.sendRequest(ctx, method, url, message)
 	if err != nil {
+		statusCode := 0
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			statusCode = statusErr.StatusCode
+		}
+		metrics.VonageSendErrors.WithLabelValues(message.MessageType, metrics.StatusLabel(statusCode)).Inc()
 		return nil, err
 	}
 
@@ -22,13 +32,13 @@ func (c *Client) sendMessageRequest(method, url string, body any) (*MessageRespo
 	return &messageResponse, nil
 }
 
-func (c *Client) sendRequest(method, url string, body any) ([]byte, error) {
+func (c *Client) sendRequest(ctx context.Context, method, url string, body any) ([]byte, error) {
 	payload, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -42,7 +52,7 @@ func (c *Client) sendRequest(method, url string, body any) ([]byte, error) {
 	defer resp.Body.Close()
 
 	if !c.isSuccessStatusCode(resp.StatusCode) {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	responseBody, err := io.ReadAll(resp.Body)
@@ -1,13 +1,14 @@
 package vonage
 
 import (
+	"context"
 	"fmt"
 )
 
-func (c *Client) MarkMessageAsRead(messageID string) error {
+func (c *Client) MarkMessageAsRead(ctx context.Context, messageID string) error {
 	payload := MarkAsReadPayload{Status: "read"}
 	url := fmt.Sprintf("%s/%s", c.config.GeospecificMessagesAPIURL, messageID)
 
-	_, err := c.sendRequest("PATCH", url, payload)
+	_, err := c.sendRequest(ctx, "PATCH", url, payload)
 	return err
 }
@@ -1,14 +1,16 @@
 package vonage
 
-func (c *Client) SendWhatsAppAudioMessage(toNumber, audioURL string) (*MessageResponse, error) {
+import "context"
+
+func (c *Client) SendWhatsAppAudioMessage(ctx context.Context, toNumber, audioURL string) (*MessageResponse, error) {
 	message := c.createWhatsAppAudioMessage(toNumber, c.config.PhoneNumberID, audioURL, nil)
-	return c.sendMessageRequest("POST", c.config.MessagesAPIURL, message)
+	return c.sendMessageRequest(ctx, "POST", c.config.MessagesAPIURL, message)
 }
 
-func (c *Client) SendWhatsAppReplyAudioMessage(toNumber, audioURL, messageUUID string) (*MessageResponse, error) {
-	context := &Context{MessageUUID: messageUUID}
-	message := c.createWhatsAppAudioMessage(toNumber, c.config.PhoneNumberID, audioURL, context)
-	return c.sendMessageRequest("POST", c.config.MessagesAPIURL, message)
+func (c *Client) SendWhatsAppReplyAudioMessage(ctx context.Context, toNumber, audioURL, messageUUID string) (*MessageResponse, error) {
+	replyTo := &Context{MessageUUID: messageUUID}
+	message := c.createWhatsAppAudioMessage(toNumber, c.config.PhoneNumberID, audioURL, replyTo)
+	return c.sendMessageRequest(ctx, "POST", c.config.MessagesAPIURL, message)
 }
 
 func (c *Client) createWhatsAppAudioMessage(toNumber, senderID, audioURL string, context *Context) WhatsAppMessage {
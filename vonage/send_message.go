@@ -1,14 +1,16 @@
 package vonage
 
-func (c *Client) SendWhatsAppTextMessage(toNumber, text string) (*MessageResponse, error) {
+import "context"
+
+func (c *Client) SendWhatsAppTextMessage(ctx context.Context, toNumber, text string) (*MessageResponse, error) {
 	message := c.createWhatsAppMessage(toNumber, c.config.PhoneNumberID, text, nil)
-	return c.sendMessageRequest("POST", c.config.MessagesAPIURL, message)
+	return c.sendMessageRequest(ctx, "POST", c.config.MessagesAPIURL, message)
 }
 
-func (c *Client) SendWhatsAppReplyMessage(toNumber, text, messageUUID string) (*MessageResponse, error) {
-	context := &Context{MessageUUID: messageUUID}
-	message := c.createWhatsAppMessage(toNumber, c.config.PhoneNumberID, text, context)
-	return c.sendMessageRequest("POST", c.config.MessagesAPIURL, message)
+func (c *Client) SendWhatsAppReplyMessage(ctx context.Context, toNumber, text, messageUUID string) (*MessageResponse, error) {
+	replyTo := &Context{MessageUUID: messageUUID}
+	message := c.createWhatsAppMessage(toNumber, c.config.PhoneNumberID, text, replyTo)
+	return c.sendMessageRequest(ctx, "POST", c.config.MessagesAPIURL, message)
 }
 
 func (c *Client) createWhatsAppMessage(toNumber, senderID, text string, context *Context) WhatsAppMessage {
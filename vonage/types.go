@@ -1,5 +1,7 @@
 package vonage
 
+import "fmt"
+
 type Config struct {
 	VonageJWT                 string
 	GeospecificMessagesAPIURL string
@@ -32,3 +34,12 @@ type MessageResponse struct {
 type MarkAsReadPayload struct {
 	Status string `json:"status"`
 }
+
+// StatusError is returned when Vonage answers a request with an unexpected HTTP status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}